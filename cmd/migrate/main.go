@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/devfullcycle/golangtechweek/config"
+	"github.com/devfullcycle/golangtechweek/internal/infra/database"
+)
+
+const usage = `uso: migrate <comando>
+
+comandos:
+  up        aplica todas as migrações pendentes
+  down      reverte a última migração aplicada
+  to <N>    aplica ou reverte migrações até a versão N (0 reverte todas)
+  status    lista as migrações e se já foram aplicadas`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	cfg := config.NewConfig()
+	// A própria CLI decide o que aplicar
+	cfg.Database.AutoMigrate = false
+
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer database.Close(db)
+
+	migrator, err := database.NewMigrator(db, cfg.Database.Driver)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "up":
+		versions, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%d migração(ões) aplicada(s): %v\n", len(versions), versions)
+	case "down":
+		versions, err := migrator.Down(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%d migração(ões) revertida(s): %v\n", len(versions), versions)
+	case "to":
+		if len(os.Args) < 3 {
+			fmt.Println(usage)
+			os.Exit(2)
+		}
+		version, err := strconv.ParseInt(os.Args[2], 10, 64)
+		if err != nil {
+			log.Fatalf("versão inválida: %s", os.Args[2])
+		}
+		versions, err := migrator.To(ctx, version)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%d migração(ões) executada(s): %v\n", len(versions), versions)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, status := range statuses {
+			state := "pendente"
+			if status.Applied {
+				state = "aplicada em " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%06d_%s\t%s\n", status.Version, status.Name, state)
+		}
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+}
@@ -1,11 +1,25 @@
 package config
 
+import (
+	"os"
+
+	"github.com/devfullcycle/golangtechweek/internal/infra/database"
+)
+
 type Config struct {
-	Port string
+	Port     string
+	Database database.Config
+
+	// EncodingProfilesFile é o arquivo JSON com os perfis de codificação nomeados (ENCODING_PROFILES_FILE),
+	// lido por service.LoadEncodingProfiles
+	EncodingProfilesFile string
 }
 
 func NewConfig() *Config {
 	return &Config{
-		Port: "8081",
+		Port:                 "8081",
+		Database:             database.ConfigFromEnv(),
+		EncodingProfilesFile: os.Getenv("ENCODING_PROFILES_FILE"),
 	}
 }
+
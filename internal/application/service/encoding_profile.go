@@ -0,0 +1,254 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+)
+
+// DefaultEncodingProfileName é o nome do perfil padrão, usado quando nenhum perfil é informado
+const DefaultEncodingProfileName = "default"
+
+// ErrInvalidEncodingProfile é retornado quando um perfil de codificação usa valores fora das listas permitidas
+var ErrInvalidEncodingProfile = errors.New("perfil de codificação inválido")
+
+// ErrEncodingProfileNotFound é retornado quando o perfil pedido não está configurado
+var ErrEncodingProfileNotFound = errors.New("perfil de codificação não encontrado")
+
+// Valores permitidos nos perfis de codificação
+// Apenas H.264 em 4:2:0 de 8 bits: o atributo CODECS da playlist principal e a compatibilidade
+// dos players dependem disso
+var (
+	allowedVideoCodecs   = []string{"h264", "libx264"}
+	allowedPresets       = []string{"ultrafast", "superfast", "veryfast", "faster", "fast", "medium", "slow", "slower", "veryslow"}
+	allowedPixelFormats  = []string{"yuv420p"}
+	allowedAudioBitrates = []int{64, 96, 128, 160, 192, 256, 320}
+	allowedAudioChannels = []int{1, 2, 6}
+	allowedExtraFlags    = map[string]func(value string) bool{
+		"tune":              oneOf("film", "animation", "grain", "stillimage", "fastdecode", "zerolatency"),
+		"bf":                intBetween(0, 16),
+		"refs":              intBetween(1, 16),
+		"threads":           intBetween(0, 64),
+		"hls_playlist_type": oneOf("vod", "event"),
+	}
+)
+
+// Limites numéricos dos perfis de codificação
+const (
+	maxSegmentDuration = 30 // segundos
+	maxGOPSize         = 10 // segundos
+	maxCRF             = 51
+)
+
+// EncodingProfile define como o vídeo é codificado na conversão para HLS
+// Campos vazios usam os valores do perfil padrão, exceto CRF, AudioChannels, ExtraFlags e Ladder,
+// cujo valor vazio tem significado próprio
+type EncodingProfile struct {
+	Name            string            `json:"-"`
+	SegmentDuration int               `json:"segment_duration"` // Duração de cada segmento, em segundos; múltiplo de GOPSize
+	VideoCodec      string            `json:"video_codec"`      // Encoder de vídeo (h264 ou libx264)
+	Preset          string            `json:"preset"`           // Preset do x264 (ultrafast a veryslow)
+	CRF             int               `json:"crf"`              // Qualidade constante (1 a 51), limitada pela taxa máxima de cada degrau; 0 usa as taxas de bits da escada
+	GOPSize         int               `json:"gop_size"`         // Intervalo entre keyframes, em segundos, igual em todas as variantes
+	AudioBitrate    int               `json:"audio_bitrate"`    // Taxa de bits do áudio AAC, em kbps
+	AudioChannels   int               `json:"audio_channels"`   // Canais de áudio (1, 2 ou 6); 0 mantém os do vídeo original
+	PixelFormat     string            `json:"pixel_format"`     // Formato de pixel da saída
+	ExtraFlags      map[string]string `json:"extra_flags"`      // Opções adicionais do FFmpeg, sem o "-" (ex.: "tune": "film")
+	Ladder          []HLSRendition    `json:"ladder"`           // Escada de qualidades; vazia usa a do FFmpegService
+}
+
+// DefaultEncodingProfile retorna o perfil padrão: segmentos de 10 segundos, H.264 e áudio AAC de 128 kbps
+func DefaultEncodingProfile() EncodingProfile {
+	return EncodingProfile{
+		Name:            DefaultEncodingProfileName,
+		SegmentDuration: 10,
+		VideoCodec:      "h264",
+		Preset:          "medium",
+		GOPSize:         2,
+		AudioBitrate:    128,
+		PixelFormat:     "yuv420p",
+	}
+}
+
+// WithDefaults retorna o perfil com os campos vazios preenchidos pelo perfil padrão
+func (p EncodingProfile) WithDefaults() EncodingProfile {
+	defaults := DefaultEncodingProfile()
+
+	if p.Name == "" {
+		p.Name = defaults.Name
+	}
+	if p.SegmentDuration == 0 {
+		p.SegmentDuration = defaults.SegmentDuration
+	}
+	if p.VideoCodec == "" {
+		p.VideoCodec = defaults.VideoCodec
+	}
+	if p.Preset == "" {
+		p.Preset = defaults.Preset
+	}
+	if p.GOPSize == 0 {
+		p.GOPSize = defaults.GOPSize
+	}
+	if p.AudioBitrate == 0 {
+		p.AudioBitrate = defaults.AudioBitrate
+	}
+	if p.PixelFormat == "" {
+		p.PixelFormat = defaults.PixelFormat
+	}
+
+	return p
+}
+
+// Validate verifica se os valores do perfil estão nas listas permitidas
+// Retorna um erro que envolve ErrInvalidEncodingProfile, descrevendo o primeiro valor recusado
+func (p EncodingProfile) Validate() error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidEncodingProfile, p.Name, fmt.Sprintf(format, args...))
+	}
+
+	if !slices.Contains(allowedVideoCodecs, p.VideoCodec) {
+		return invalid("codec de vídeo %q não permitido", p.VideoCodec)
+	}
+	if !slices.Contains(allowedPresets, p.Preset) {
+		return invalid("preset %q não permitido", p.Preset)
+	}
+	if p.CRF < 0 || p.CRF > maxCRF {
+		return invalid("CRF %d fora do intervalo de 0 a %d", p.CRF, maxCRF)
+	}
+	if p.GOPSize < 1 || p.GOPSize > maxGOPSize {
+		return invalid("GOP de %d segundos fora do intervalo de 1 a %d", p.GOPSize, maxGOPSize)
+	}
+	if p.SegmentDuration < 1 || p.SegmentDuration > maxSegmentDuration {
+		return invalid("segmentos de %d segundos fora do intervalo de 1 a %d", p.SegmentDuration, maxSegmentDuration)
+	}
+	// Com segmentos múltiplos do GOP, todo segmento começa em um keyframe, nos mesmos instantes em todas as variantes
+	if p.SegmentDuration%p.GOPSize != 0 {
+		return invalid("duração dos segmentos (%d s) não é múltipla do GOP (%d s)", p.SegmentDuration, p.GOPSize)
+	}
+	if !slices.Contains(allowedAudioBitrates, p.AudioBitrate) {
+		return invalid("taxa de bits do áudio de %d kbps não permitida", p.AudioBitrate)
+	}
+	if p.AudioChannels != 0 && !slices.Contains(allowedAudioChannels, p.AudioChannels) {
+		return invalid("%d canais de áudio não permitidos", p.AudioChannels)
+	}
+	if !slices.Contains(allowedPixelFormats, p.PixelFormat) {
+		return invalid("formato de pixel %q não permitido", p.PixelFormat)
+	}
+
+	for _, flag := range slices.Sorted(maps.Keys(p.ExtraFlags)) {
+		valid, ok := allowedExtraFlags[flag]
+		if !ok {
+			return invalid("opção %q não permitida", flag)
+		}
+		if !valid(p.ExtraFlags[flag]) {
+			return invalid("valor %q não permitido para a opção %q", p.ExtraFlags[flag], flag)
+		}
+	}
+
+	if len(p.Ladder) > 0 && len(normalizeLadder(p.Ladder)) == 0 {
+		return invalid("escada de qualidades sem degraus válidos")
+	}
+
+	return nil
+}
+
+// Params retorna os parâmetros do FFmpeg comuns a todas as variantes
+// Os valores também são registrados em cada tentativa de processamento para facilitar o diagnóstico
+func (p EncodingProfile) Params() map[string]string {
+	params := map[string]string{
+		"f":                "hls",                                             // Formato de saída: HLS
+		"hls_time":         strconv.Itoa(p.SegmentDuration),                   // Duração de cada segmento em segundos
+		"hls_list_size":    "0",                                               // 0 = incluir todos os segmentos no manifesto
+		"hls_flags":        "independent_segments",                            // Todo segmento começa em um keyframe
+		"c:v":              p.VideoCodec,                                      // Codec de vídeo H.264 (amplamente suportado)
+		"preset":           p.Preset,                                          // Equilíbrio entre velocidade e compressão
+		"pix_fmt":          p.PixelFormat,                                     // Formato de pixel aceito pelos players
+		"c:a":              "aac",                                             // Codec de áudio AAC (amplamente suportado)
+		"b:a":              fmt.Sprintf("%dk", p.AudioBitrate),                // Taxa de bits do áudio
+		"force_key_frames": fmt.Sprintf("expr:gte(t,n_forced*%d)", p.GOPSize), // Keyframes nos mesmos instantes em todas as variantes
+		"sc_threshold":     "0",                                               // Sem keyframes extras em trocas de cena
+	}
+
+	if p.CRF > 0 {
+		params["crf"] = strconv.Itoa(p.CRF)
+	}
+	if p.AudioChannels > 0 {
+		params["ac"] = strconv.Itoa(p.AudioChannels)
+	}
+	for flag, value := range p.ExtraFlags {
+		params[flag] = value
+	}
+
+	return params
+}
+
+// EncodingProfiles são os perfis de codificação configurados, indexados pelo nome
+type EncodingProfiles map[string]EncodingProfile
+
+// Get retorna o perfil com o nome informado; nome vazio retorna o perfil padrão, que pode ser
+// substituído por um perfil configurado com o nome DefaultEncodingProfileName
+func (p EncodingProfiles) Get(name string) (EncodingProfile, error) {
+	if name == "" {
+		name = DefaultEncodingProfileName
+	}
+
+	if profile, ok := p[name]; ok {
+		return profile, nil
+	}
+
+	if name == DefaultEncodingProfileName {
+		return DefaultEncodingProfile(), nil
+	}
+
+	return EncodingProfile{}, fmt.Errorf("%w: %q", ErrEncodingProfileNotFound, name)
+}
+
+// LoadEncodingProfiles lê os perfis de codificação de um arquivo JSON, no formato
+// {"nome": {"preset": "fast", "crf": 23, ...}}
+// Cada perfil é completado com o perfil padrão e validado; um perfil inválido invalida o arquivo
+// Sem arquivo (path vazio), apenas o perfil padrão fica disponível
+func LoadEncodingProfiles(path string) (EncodingProfiles, error) {
+	if path == "" {
+		return EncodingProfiles{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler perfis de codificação: %w", err)
+	}
+
+	var profiles EncodingProfiles
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("erro ao ler perfis de codificação: %w", err)
+	}
+
+	for name, profile := range profiles {
+		profile.Name = name
+		profile = profile.WithDefaults()
+		if err := profile.Validate(); err != nil {
+			return nil, err
+		}
+		profiles[name] = profile
+	}
+
+	return profiles, nil
+}
+
+// oneOf retorna um validador que aceita apenas os valores informados
+func oneOf(values ...string) func(string) bool {
+	return func(value string) bool {
+		return slices.Contains(values, value)
+	}
+}
+
+// intBetween retorna um validador que aceita apenas inteiros entre lower e upper (inclusive)
+func intBetween(lower, upper int) func(string) bool {
+	return func(value string) bool {
+		n, err := strconv.Atoi(value)
+		return err == nil && n >= lower && n <= upper
+	}
+}
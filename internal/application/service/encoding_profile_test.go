@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodingProfileDefaultsAreValid(t *testing.T) {
+	assert.NoError(t, DefaultEncodingProfile().Validate())
+	assert.NoError(t, EncodingProfile{}.WithDefaults().Validate())
+	assert.Equal(t, DefaultEncodingProfile().Params(), DefaultHLSEncodingParams())
+}
+
+func TestEncodingProfileValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile EncodingProfile
+	}{
+		{"codec fora da lista", EncodingProfile{VideoCodec: "libx265"}},
+		{"preset desconhecido", EncodingProfile{Preset: "placebo"}},
+		{"CRF acima do limite", EncodingProfile{CRF: 52}},
+		{"GOP acima do limite", EncodingProfile{GOPSize: 11, SegmentDuration: 22}},
+		{"segmentos longos demais", EncodingProfile{SegmentDuration: 60}},
+		{"segmento não múltiplo do GOP", EncodingProfile{SegmentDuration: 5, GOPSize: 2}},
+		{"taxa de áudio fora da lista", EncodingProfile{AudioBitrate: 100}},
+		{"canais de áudio fora da lista", EncodingProfile{AudioChannels: 3}},
+		{"formato de pixel fora da lista", EncodingProfile{PixelFormat: "yuv444p"}},
+		{"opção extra fora da lista", EncodingProfile{ExtraFlags: map[string]string{"filter_complex": "null"}}},
+		{"valor de opção extra fora da lista", EncodingProfile{ExtraFlags: map[string]string{"tune": "psnr"}}},
+		{"escada sem degraus válidos", EncodingProfile{Ladder: []HLSRendition{{Height: 720}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.profile.WithDefaults().Validate(), ErrInvalidEncodingProfile)
+		})
+	}
+
+	valid := EncodingProfile{
+		SegmentDuration: 6,
+		GOPSize:         3,
+		Preset:          "veryfast",
+		CRF:             23,
+		AudioBitrate:    96,
+		AudioChannels:   2,
+		ExtraFlags:      map[string]string{"tune": "film", "bf": "3"},
+		Ladder:          []HLSRendition{{Height: 720, VideoBitrate: 2500}},
+	}
+	assert.NoError(t, valid.WithDefaults().Validate())
+}
+
+func TestEncodingProfileParams(t *testing.T) {
+	params := EncodingProfile{
+		SegmentDuration: 6,
+		GOPSize:         3,
+		Preset:          "fast",
+		CRF:             20,
+		AudioBitrate:    96,
+		AudioChannels:   2,
+		ExtraFlags:      map[string]string{"tune": "animation"},
+	}.WithDefaults().Params()
+
+	assert.Equal(t, "6", params["hls_time"])
+	assert.Equal(t, "expr:gte(t,n_forced*3)", params["force_key_frames"])
+	assert.Equal(t, "fast", params["preset"])
+	assert.Equal(t, "20", params["crf"])
+	assert.Equal(t, "96k", params["b:a"])
+	assert.Equal(t, "2", params["ac"])
+	assert.Equal(t, "yuv420p", params["pix_fmt"])
+	assert.Equal(t, "animation", params["tune"])
+
+	// Sem CRF nem canais, as opções não são passadas ao FFmpeg
+	params = DefaultEncodingProfile().Params()
+	assert.NotContains(t, params, "crf")
+	assert.NotContains(t, params, "ac")
+}
+
+func TestLoadEncodingProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"fast": {"preset": "veryfast", "crf": 26, "segment_duration": 4},
+		"mobile": {"audio_bitrate": 64, "audio_channels": 1, "ladder": [{"height": 360, "video_bitrate": 600}]}
+	}`), 0o644))
+
+	profiles, err := LoadEncodingProfiles(path)
+	require.NoError(t, err)
+
+	fast, err := profiles.Get("fast")
+	require.NoError(t, err)
+	assert.Equal(t, "fast", fast.Name)
+	assert.Equal(t, "veryfast", fast.Preset)
+	assert.Equal(t, 26, fast.CRF)
+	assert.Equal(t, 4, fast.SegmentDuration)
+	assert.Equal(t, 2, fast.GOPSize)
+
+	mobile, err := profiles.Get("mobile")
+	require.NoError(t, err)
+	assert.Equal(t, 64, mobile.AudioBitrate)
+	assert.Equal(t, []HLSRendition{{Height: 360, VideoBitrate: 600}}, mobile.Ladder)
+
+	// Sem um perfil "default" no arquivo, o nome vazio retorna o perfil padrão
+	profile, err := profiles.Get("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultEncodingProfile(), profile)
+
+	_, err = profiles.Get("4k")
+	assert.ErrorIs(t, err, ErrEncodingProfileNotFound)
+}
+
+func TestLoadEncodingProfilesWithoutFile(t *testing.T) {
+	profiles, err := LoadEncodingProfiles("")
+	require.NoError(t, err)
+	assert.Empty(t, profiles)
+
+	profile, err := profiles.Get("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultEncodingProfile(), profile)
+}
+
+func TestLoadEncodingProfilesRejectsInvalidProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"bad": {"preset": "placebo"}}`), 0o644))
+
+	_, err := LoadEncodingProfiles(path)
+
+	assert.ErrorIs(t, err, ErrInvalidEncodingProfile)
+	assert.ErrorContains(t, err, `"bad"`)
+}
+
+func TestFFmpegService_ConvertToHLSRejectsInvalidProfile(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "video-1")
+
+	_, err := NewFFmpegService().ConvertToHLS(context.Background(), "input.mp4", outputDir,
+		EncodingProfile{ExtraFlags: map[string]string{"i": "/etc/passwd"}}, nil)
+
+	assert.ErrorIs(t, err, ErrInvalidEncodingProfile)
+	assert.NoDirExists(t, outputDir)
+}
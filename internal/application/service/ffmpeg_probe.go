@@ -0,0 +1,94 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errNoVideoStream indica que o arquivo de entrada não tem stream de vídeo
+var errNoVideoStream = errors.New("arquivo sem stream de vídeo")
+
+// mediaInfo são as informações do vídeo de entrada usadas na conversão
+type mediaInfo struct {
+	Duration time.Duration // Duração total; 0 quando desconhecida
+	Width    int           // Largura de exibição, já considerando a rotação
+	Height   int           // Altura de exibição, já considerando a rotação
+	HasAudio bool
+}
+
+// probeInput obtém as informações do vídeo de entrada usando o ffprobe
+func probeInput(ctx context.Context, ffprobePath, input string) (mediaInfo, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ffprobePath, "-v", "error", "-show_format", "-show_streams", "-of", "json", input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return mediaInfo{}, fmt.Errorf("erro ao obter informações do vídeo: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+
+	return parseProbeOutput(stdout.Bytes())
+}
+
+// parseProbeOutput lê a saída JSON do ffprobe
+func parseProbeOutput(output []byte) (mediaInfo, error) {
+	var probe struct {
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+		Streams []struct {
+			CodecType string            `json:"codec_type"`
+			Width     int               `json:"width"`
+			Height    int               `json:"height"`
+			Tags      map[string]string `json:"tags"`
+			SideData  []struct {
+				Rotation float64 `json:"rotation"`
+			} `json:"side_data_list"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return mediaInfo{}, fmt.Errorf("erro ao ler informações do vídeo: %w", err)
+	}
+
+	var info mediaInfo
+	if seconds, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		info.Duration = time.Duration(seconds * float64(time.Second))
+	}
+
+	for _, stream := range probe.Streams {
+		switch stream.CodecType {
+		case "audio":
+			info.HasAudio = true
+		case "video":
+			if info.Width > 0 {
+				continue
+			}
+			info.Width, info.Height = stream.Width, stream.Height
+
+			// Vídeos gravados na vertical costumam ter a rotação nos metadados; o FFmpeg gira
+			// os quadros antes dos filtros, então as dimensões de exibição são as invertidas
+			rotation, _ := strconv.Atoi(stream.Tags["rotate"])
+			for _, side := range stream.SideData {
+				if side.Rotation != 0 {
+					rotation = int(side.Rotation)
+				}
+			}
+			if rotation%180 != 0 {
+				info.Width, info.Height = info.Height, info.Width
+			}
+		}
+	}
+
+	if info.Width <= 0 || info.Height <= 0 {
+		return mediaInfo{}, errNoVideoStream
+	}
+
+	return info, nil
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProbeOutput(t *testing.T) {
+	info, err := parseProbeOutput([]byte(`{
+		"format": {"duration": "12.500000"},
+		"streams": [
+			{"codec_type": "video", "width": 1920, "height": 1080},
+			{"codec_type": "audio"}
+		]
+	}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, mediaInfo{Duration: 12500 * time.Millisecond, Width: 1920, Height: 1080, HasAudio: true}, info)
+}
+
+func TestParseProbeOutputRotation(t *testing.T) {
+	// Gravado na vertical: rotação na side data (FFmpeg recente) ou na tag rotate (FFmpeg antigo)
+	for _, stream := range []string{
+		`{"codec_type": "video", "width": 1920, "height": 1080, "side_data_list": [{"rotation": -90}]}`,
+		`{"codec_type": "video", "width": 1920, "height": 1080, "tags": {"rotate": "90"}}`,
+	} {
+		info, err := parseProbeOutput([]byte(`{"format": {}, "streams": [` + stream + `]}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, 1080, info.Width)
+		assert.Equal(t, 1920, info.Height)
+		assert.False(t, info.HasAudio)
+		assert.Zero(t, info.Duration)
+	}
+}
+
+func TestParseProbeOutputWithoutVideo(t *testing.T) {
+	_, err := parseProbeOutput([]byte(`{"format": {"duration": "3.0"}, "streams": [{"codec_type": "audio"}]}`))
+
+	assert.ErrorIs(t, err, errNoVideoStream)
+}
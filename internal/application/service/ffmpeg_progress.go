@@ -0,0 +1,68 @@
+package service
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+)
+
+// ConversionProgress representa o andamento de uma conversão, lido da saída -progress do FFmpeg
+type ConversionProgress struct {
+	Stage     string        // Etapa atual da conversão
+	Percent   float64       // Percentual concluído (0 a 100); 0 quando a duração do vídeo é desconhecida
+	Processed time.Duration // Tempo de vídeo já convertido
+	Total     time.Duration // Duração total do vídeo de entrada (0 quando desconhecida)
+	Speed     float64       // Velocidade da conversão em relação ao tempo real (ex: 2.0 = 2x)
+	Done      bool          // Indica que o FFmpeg reportou o fim da conversão
+}
+
+// ProgressFunc é chamada a cada atualização de progresso da conversão
+type ProgressFunc func(progress ConversionProgress)
+
+// readProgress lê os blocos chave=valor escritos pelo FFmpeg com "-progress pipe:1"
+// e chama onProgress ao final de cada bloco (linha "progress=continue" ou "progress=end").
+// A leitura continua até o fim do reader, mesmo sem onProgress, para não bloquear o FFmpeg.
+func readProgress(r io.Reader, total time.Duration, onProgress ProgressFunc) {
+	current := ConversionProgress{Stage: entity.ProcessingStageTranscoding, Total: total}
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "out_time_us", "out_time_ms":
+			// Apesar do nome, out_time_ms também é reportado em microssegundos pelo FFmpeg
+			if us, err := strconv.ParseInt(value, 10, 64); err == nil && us >= 0 {
+				current.Processed = time.Duration(us) * time.Microsecond
+			}
+		case "speed":
+			if speed, err := strconv.ParseFloat(strings.TrimSuffix(value, "x"), 64); err == nil {
+				current.Speed = speed
+			}
+		case "progress":
+			current.Done = value == "end"
+			current.Percent = progressPercent(current.Processed, total, current.Done)
+			if onProgress != nil {
+				onProgress(current)
+			}
+		}
+	}
+}
+
+// progressPercent calcula o percentual concluído, limitado a 100
+func progressPercent(processed, total time.Duration, done bool) float64 {
+	if done {
+		return 100
+	}
+	if total <= 0 {
+		return 0
+	}
+	return min(float64(processed)/float64(total)*100, 100)
+}
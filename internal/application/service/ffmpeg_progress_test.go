@@ -0,0 +1,54 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadProgress(t *testing.T) {
+	// Saída no formato de "-progress pipe:1" do FFmpeg
+	output := strings.Join([]string{
+		"frame=120",
+		"out_time_us=5000000",
+		"out_time=00:00:05.000000",
+		"speed=2.5x",
+		"progress=continue",
+		"frame=480",
+		"out_time_us=20000000",
+		"speed=2.4x",
+		"progress=end",
+	}, "\n")
+
+	var updates []ConversionProgress
+	readProgress(strings.NewReader(output), 20*time.Second, func(progress ConversionProgress) {
+		updates = append(updates, progress)
+	})
+
+	if assert.Len(t, updates, 2) {
+		assert.Equal(t, entity.ProcessingStageTranscoding, updates[0].Stage)
+		assert.InDelta(t, 25.0, updates[0].Percent, 0.001)
+		assert.Equal(t, 5*time.Second, updates[0].Processed)
+		assert.InDelta(t, 2.5, updates[0].Speed, 0.001)
+		assert.False(t, updates[0].Done)
+
+		assert.Equal(t, 100.0, updates[1].Percent)
+		assert.True(t, updates[1].Done)
+	}
+}
+
+func TestReadProgressUnknownDuration(t *testing.T) {
+	var updates []ConversionProgress
+	readProgress(strings.NewReader("out_time_us=5000000\nprogress=continue\n"), 0, func(progress ConversionProgress) {
+		updates = append(updates, progress)
+	})
+
+	// Sem a duração total não é possível calcular o percentual
+	if assert.Len(t, updates, 1) {
+		assert.Equal(t, 0.0, updates[0].Percent)
+		assert.Equal(t, 5*time.Second, updates[0].Processed)
+	}
+}
@@ -1,16 +1,30 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
 	ffmpeg "github.com/u2takey/ffmpeg-go"
 )
 
+// DefaultFFmpegGracePeriod é o tempo que o FFmpeg tem para encerrar após o cancelamento antes de ser morto
+const DefaultFFmpegGracePeriod = 10 * time.Second
+
+// ErrConversionCanceled é retornado quando a conversão é interrompida pelo cancelamento do contexto
+// O erro retornado também envolve o erro do contexto (context.Canceled ou context.DeadlineExceeded)
+var ErrConversionCanceled = errors.New("conversão cancelada")
+
 // OutputFile representa um arquivo gerado pela conversão
 type OutputFile struct {
 	Path string // Caminho completo do arquivo
@@ -23,26 +37,88 @@ type OutputFile struct {
 // Exemplo de uso com mock em testes:
 //
 //	mockFFmpeg := new(MockFFmpegService)
-//	mockFFmpeg.On("ConvertToHLS", ctx, inputPath, outputDir).Return(expectedFiles, nil)
+//	mockFFmpeg.On("ConvertToHLS", ctx, inputPath, outputDir, mock.Anything, mock.Anything).Return(expectedFiles, nil)
 //	// Use o mock no seu teste
 type FFmpegServiceInterface interface {
-	// ConvertToHLS converte um arquivo de vídeo para o formato HLS (HTTP Live Streaming).
+	// ConvertToHLS converte um arquivo de vídeo para o formato HLS (HTTP Live Streaming), com o perfil de codificação informado.
+	// onProgress (opcional) recebe as atualizações de progresso reportadas pelo FFmpeg.
 	// Retorna uma lista de arquivos gerados (manifesto e segmentos) e um possível erro.
-	ConvertToHLS(ctx context.Context, input string, outputDir string) ([]OutputFile, error)
+	ConvertToHLS(ctx context.Context, input string, outputDir string, profile EncodingProfile, onProgress ProgressFunc) ([]OutputFile, error)
 }
 
 // FFmpegService implementa a interface FFmpegServiceInterface usando o pacote ffmpeg-go.
-// Esta estrutura não possui campos, pois não precisa armazenar estado.
-type FFmpegService struct{}
+// O processo do FFmpeg fica vinculado ao contexto da conversão: ao cancelamento, recebe SIGINT para encerrar
+// e, se ainda estiver em execução após gracePeriod, é morto.
+// Cada degrau da escada de qualidades que não amplia o vídeo gera uma variante, listada em master.m3u8.
+type FFmpegService struct {
+	ffmpegPath  string // Executável do FFmpeg
+	ffprobePath string // Executável do ffprobe
+	gracePeriod time.Duration
+	ladder      []HLSRendition
+	logger      *slog.Logger
+}
+
+// FFmpegServiceConfig contém as configurações do serviço FFmpeg
+type FFmpegServiceConfig struct {
+	GracePeriod time.Duration  // Tempo para o FFmpeg encerrar após o cancelamento; não positivo usa DefaultFFmpegGracePeriod
+	Ladder      []HLSRendition // Escada de qualidades; vazia usa DefaultHLSLadder
+	Logger      *slog.Logger   // Recebe a saída de erro do FFmpeg em nível debug
+}
+
+// DefaultFFmpegServiceConfig retorna uma configuração padrão para o serviço FFmpeg
+func DefaultFFmpegServiceConfig() FFmpegServiceConfig {
+	return FFmpegServiceConfig{
+		GracePeriod: DefaultFFmpegGracePeriod,
+		Ladder:      DefaultHLSLadder(),
+		Logger: slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			Level: slog.LevelInfo,
+		})),
+	}
+}
 
 // NewFFmpegService cria uma nova instância do serviço FFmpeg.
 //
 // Exemplo de uso:
 //
 //	ffmpegService := NewFFmpegService()
-//	outputFiles, err := ffmpegService.ConvertToHLS(ctx, "video.mp4", "./output")
+//	outputFiles, err := ffmpegService.ConvertToHLS(ctx, "video.mp4", "./output", DefaultEncodingProfile(), nil)
 func NewFFmpegService() *FFmpegService {
-	return &FFmpegService{}
+	return NewFFmpegServiceWithConfig(DefaultFFmpegServiceConfig())
+}
+
+// NewFFmpegServiceWithGracePeriod cria o serviço FFmpeg com o tempo de encerramento informado
+// Valores não positivos usam DefaultFFmpegGracePeriod
+func NewFFmpegServiceWithGracePeriod(gracePeriod time.Duration) *FFmpegService {
+	config := DefaultFFmpegServiceConfig()
+	config.GracePeriod = gracePeriod
+	return NewFFmpegServiceWithConfig(config)
+}
+
+// NewFFmpegServiceWithConfig cria o serviço FFmpeg com as configurações informadas
+// Degraus da escada sem resolução ou taxa de bits são ignorados
+func NewFFmpegServiceWithConfig(config FFmpegServiceConfig) *FFmpegService {
+	if config.GracePeriod <= 0 {
+		config.GracePeriod = DefaultFFmpegGracePeriod
+	}
+
+	ladder := normalizeLadder(config.Ladder)
+	if len(ladder) == 0 {
+		ladder = DefaultHLSLadder()
+	}
+
+	if config.Logger == nil {
+		config.Logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			Level: slog.LevelInfo,
+		}))
+	}
+
+	return &FFmpegService{
+		ffmpegPath:  "ffmpeg",
+		ffprobePath: "ffprobe",
+		gracePeriod: config.GracePeriod,
+		ladder:      ladder,
+		logger:      config.Logger,
+	}
 }
 
 // Sobre permissões de arquivos em notação octal (como 0o755):
@@ -77,32 +153,48 @@ func NewFFmpegService() *FFmpegService {
 //   - ctx: Contexto que permite cancelamento da operação
 //   - input: Caminho do arquivo de vídeo de entrada
 //   - outputDir: Diretório onde os arquivos HLS serão salvos
+//   - profile: Perfil de codificação; campos vazios usam o perfil padrão (veja EncodingProfile.WithDefaults)
+//   - onProgress: Função chamada a cada atualização de progresso (pode ser nil)
 //
 // Retorna:
-//   - Uma lista de arquivos gerados (manifesto e segmentos)
-//   - Um erro, se ocorrer
+//   - Uma lista de arquivos gerados: a playlist principal (master.m3u8), as playlists e os segmentos de cada variante
+//   - Um erro, se ocorrer; ErrConversionCanceled se o contexto for cancelado, caso em que
+//     os arquivos parciais já gerados são removidos; ErrInvalidEncodingProfile se o perfil for recusado
 //
 // Exemplo de uso:
 //
 //	ctx := context.Background()
-//	outputFiles, err := ffmpegService.ConvertToHLS(ctx, "video.mp4", "./output")
+//	outputFiles, err := ffmpegService.ConvertToHLS(ctx, "video.mp4", "./output", DefaultEncodingProfile(), nil)
 //	if err != nil {
 //	    log.Fatalf("Erro ao converter vídeo: %v", err)
 //	}
-//	fmt.Printf("Arquivos gerados: %d\n", len(outputFiles))
-func (s *FFmpegService) ConvertToHLS(ctx context.Context, input string, outputDir string) ([]OutputFile, error) {
+func (s *FFmpegService) ConvertToHLS(ctx context.Context, input string, outputDir string, profile EncodingProfile, onProgress ProgressFunc) ([]OutputFile, error) {
 	// Verifica se a operação já foi cancelada
 	if ctx.Err() != nil {
-		return nil, fmt.Errorf("operação cancelada: %w", ctx.Err())
+		return nil, fmt.Errorf("%w: %w", ErrConversionCanceled, ctx.Err())
 	}
 
+	// Recusa o perfil antes de qualquer efeito no disco
+	profile = profile.WithDefaults()
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Guarda se o diretório de saída já existia, para removê-lo apenas se foi criado aqui
+	_, statErr := os.Stat(outputDir)
+	createdDir := errors.Is(statErr, os.ErrNotExist)
+
 	// Cria o diretório de saída (e diretórios pai, se necessário)
 	if err := os.MkdirAll(outputDir, 0o755); err != nil {
 		return nil, fmt.Errorf("erro ao criar diretório de saída: %w", err)
 	}
 
 	// Executa a conversão do vídeo para o formato HLS
-	if err := s.executeFFmpegConversion(ctx, input, outputDir); err != nil {
+	if err := s.executeFFmpegConversion(ctx, input, outputDir, profile, onProgress); err != nil {
+		if errors.Is(err, ErrConversionCanceled) {
+			removePartialOutput(outputDir, createdDir)
+			return nil, err
+		}
 		return nil, fmt.Errorf("erro na conversão FFmpeg: %w", err)
 	}
 
@@ -110,43 +202,193 @@ func (s *FFmpegService) ConvertToHLS(ctx context.Context, input string, outputDi
 	return s.collectOutputFiles(outputDir)
 }
 
+// FFmpegError representa uma falha na execução do FFmpeg
+// Além do erro original, guarda o trecho final do stderr, onde o FFmpeg descreve a causa da falha
+type FFmpegError struct {
+	Err    error  // Erro retornado pela execução do FFmpeg
+	Stderr string // Trecho final da saída de erro do FFmpeg
+}
+
+// Error retorna a mensagem do erro original
+func (e *FFmpegError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap permite usar errors.Is e errors.As com o erro original
+func (e *FFmpegError) Unwrap() error {
+	return e.Err
+}
+
+// StderrFromError retorna o trecho do stderr do FFmpeg contido no erro, se houver
+func StderrFromError(err error) string {
+	var ffmpegErr *FFmpegError
+	if errors.As(err, &ffmpegErr) {
+		return ffmpegErr.Stderr
+	}
+	return ""
+}
+
+// DefaultHLSEncodingParams retorna os parâmetros de codificação do perfil padrão (veja EncodingProfile.Params)
+func DefaultHLSEncodingParams() map[string]string {
+	return DefaultEncodingProfile().Params()
+}
+
 // executeFFmpegConversion executa o comando FFmpeg para converter o vídeo para HLS.
 // Esta função configura e executa o FFmpeg com os parâmetros necessários para
-// criar um stream HLS a partir do vídeo de entrada.
-func (s *FFmpegService) executeFFmpegConversion(ctx context.Context, input string, outputDir string) error {
-	// Define o caminho do arquivo de manifesto (playlist principal)
-	manifestPath := filepath.Join(outputDir, "playlist.m3u8")
+// criar as variantes HLS a partir do vídeo de entrada e grava a playlist principal.
+func (s *FFmpegService) executeFFmpegConversion(ctx context.Context, input string, outputDir string, profile EncodingProfile, onProgress ProgressFunc) error {
+	// As dimensões do vídeo definem as variantes geradas, e a duração permite calcular o percentual
+	info, err := probeInput(ctx, s.ffprobePath, input)
+	if ctx.Err() != nil {
+		return fmt.Errorf("%w: %w", ErrConversionCanceled, ctx.Err())
+	}
+	if err != nil {
+		return err
+	}
+
+	// A escada do perfil, quando houver, substitui a do serviço
+	ladder := s.ladder
+	if len(profile.Ladder) > 0 {
+		ladder = normalizeLadder(profile.Ladder)
+	}
+	variants := selectVariants(ladder, info.Width, info.Height)
+
+	// Configura os parâmetros para a conversão HLS: os comuns e os de cada variante
+	hlsParams := hlsVariantArgs(variants, profile, info.HasAudio, outputDir)
+	for key, value := range profile.Params() {
+		hlsParams[key] = value
+	}
+
+	// Guarda o trecho final do stderr para diagnóstico e envia cada linha ao logger em nível debug
+	stderr := &tailBuffer{limit: entity.MaxStderrExcerptSize}
+	stderrLog := &debugLogWriter{ctx: ctx, logger: s.logger, message: "Saída do FFmpeg"}
+
+	// O FFmpeg escreve o progresso no stdout ("-progress pipe:1"), que é lido em paralelo
+	progressReader, progressWriter := io.Pipe()
+	progressDone := make(chan struct{})
+	go func() {
+		defer close(progressDone)
+		readProgress(progressReader, info.Duration, onProgress)
+	}()
 
-	// Configura os parâmetros para a conversão HLS
-	hlsParams := ffmpeg.KwArgs{
-		// Parâmetros essenciais
-		"f":             "hls", // Formato de saída: HLS
-		"hls_time":      10,    // Duração de cada segmento em segundos
-		"hls_list_size": 0,     // 0 = incluir todos os segmentos no manifesto
+	// Monta o comando FFmpeg; o ffmpeg-go não propaga o contexto até o processo, por isso
+	// o comando é criado aqui com exec.CommandContext
+	stream := ffmpeg.Input(input).
+		Output(variantPlaylistPattern(outputDir), hlsParams).
+		GlobalArgs("-progress", "pipe:1", "-nostats")
 
-		// Codecs de áudio e vídeo
-		"c:v": "h264", // Codec de vídeo H.264 (amplamente suportado)
-		"c:a": "aac",  // Codec de áudio AAC (amplamente suportado)
-		"b:a": "128k", // Taxa de bits do áudio: 128 kbps
+	cmd := exec.CommandContext(ctx, s.ffmpegPath, stream.GetArgs()...)
+	cmd.Stdout = progressWriter
+	cmd.Stderr = stderr
+	if s.logger.Enabled(ctx, slog.LevelDebug) {
+		cmd.Stderr = io.MultiWriter(stderrLog, stderr)
 	}
 
+	// No cancelamento, o SIGINT permite ao FFmpeg encerrar por conta própria; após o gracePeriod,
+	// o exec mata o processo e deixa de esperar pela saída
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(os.Interrupt); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = s.gracePeriod
+
 	// Executa o comando FFmpeg
-	err := ffmpeg.Input(input).
-		Output(manifestPath, hlsParams).
-		ErrorToStdOut().
-		Run()
+	err = cmd.Run()
+
+	// Fecha o pipe e aguarda a leitura das últimas linhas de progresso
+	progressWriter.Close()
+	<-progressDone
+	stderrLog.Flush()
 
 	// Verifica se a operação foi cancelada durante a execução
 	if ctx.Err() != nil {
-		return ctx.Err()
+		return fmt.Errorf("%w: %w", ErrConversionCanceled, ctx.Err())
 	}
 
 	// Retorna o erro do FFmpeg, se houver
 	if err != nil {
-		return err
+		return &FFmpegError{Err: err, Stderr: stderr.String()}
 	}
 
-	return nil
+	return writeMasterPlaylist(outputDir, variants, profile, info.HasAudio)
+}
+
+// removePartialOutput remove os arquivos gerados por uma conversão interrompida (as playlists, os segmentos
+// e os temporários, todos com o prefixo "playlist") e o diretório de saída, se foi criado
+// pela conversão e ficou vazio
+func removePartialOutput(outputDir string, removeDir bool) {
+	partial, _ := filepath.Glob(filepath.Join(outputDir, "playlist*"))
+	partial = append(partial, filepath.Join(outputDir, masterPlaylistName))
+	for _, path := range partial {
+		os.Remove(path)
+	}
+
+	if removeDir {
+		os.Remove(outputDir)
+	}
+}
+
+// tailBuffer é um io.Writer que mantém apenas os últimos limit bytes escritos
+type tailBuffer struct {
+	limit int
+	data  []byte
+}
+
+// Write adiciona os bytes ao buffer, descartando os mais antigos quando o limite é excedido
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.data = append(b.data, p...)
+	if len(b.data) > b.limit {
+		b.data = b.data[len(b.data)-b.limit:]
+	}
+	return len(p), nil
+}
+
+// String retorna o conteúdo atual do buffer, começando sempre no início de um caractere UTF-8
+func (b *tailBuffer) String() string {
+	start := 0
+	for start < len(b.data) && !utf8.RuneStart(b.data[start]) {
+		start++
+	}
+	return string(b.data[start:])
+}
+
+// debugLogWriter é um io.Writer que envia cada linha completa escrita ao logger em nível debug
+type debugLogWriter struct {
+	ctx     context.Context
+	logger  *slog.Logger
+	message string
+	pending []byte
+}
+
+// Write registra as linhas completas e guarda a última linha incompleta até a próxima escrita
+func (w *debugLogWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(w.pending[:i])
+		w.pending = w.pending[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush registra a linha incompleta que restou no buffer
+func (w *debugLogWriter) Flush() {
+	w.log(w.pending)
+	w.pending = nil
+}
+
+// log registra a linha, ignorando linhas vazias
+func (w *debugLogWriter) log(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) == 0 {
+		return
+	}
+	w.logger.DebugContext(w.ctx, w.message, "line", string(line))
 }
 
 // collectOutputFiles lista e categoriza os arquivos gerados pela conversão.
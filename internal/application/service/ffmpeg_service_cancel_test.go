@@ -0,0 +1,140 @@
+//go:build !windows
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeFFmpeg cria um executável que simula o FFmpeg: grava um segmento parcial ao lado do manifesto
+// (o argumento terminado em .m3u8) e fica em execução; onInterrupt é executado ao receber SIGINT
+func newFakeFFmpeg(t *testing.T, onInterrupt string) string {
+	t.Helper()
+
+	script := `#!/bin/sh
+trap '` + onInterrupt + `' INT
+for arg in "$@"; do
+	case "$arg" in *.m3u8) manifest="$arg" ;; esac
+done
+touch "$(dirname "$manifest")/playlist0.ts"
+while true; do sleep 0.05; done
+`
+	path := filepath.Join(t.TempDir(), "ffmpeg")
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+	return path
+}
+
+// newFakeFFprobe cria um executável que simula o ffprobe de um vídeo 1280x720 com áudio
+func newFakeFFprobe(t *testing.T) string {
+	t.Helper()
+
+	script := `#!/bin/sh
+echo '{"format":{"duration":"60.0"},"streams":[{"codec_type":"video","width":1280,"height":720},{"codec_type":"audio"}]}'
+`
+	path := filepath.Join(t.TempDir(), "ffprobe")
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+	return path
+}
+
+// convertAndCancel inicia a conversão, cancela o contexto assim que o segmento parcial aparece
+// e retorna o erro e o tempo entre o cancelamento e o retorno
+func convertAndCancel(t *testing.T, s *FFmpegService, outputDir string) (error, time.Duration) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.ConvertToHLS(ctx, "input.mp4", outputDir, DefaultEncodingProfile(), nil)
+		done <- err
+	}()
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(outputDir, "playlist0.ts"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	canceledAt := time.Now()
+
+	select {
+	case err := <-done:
+		return err, time.Since(canceledAt)
+	case <-time.After(5 * time.Second):
+		t.Fatal("a conversão não terminou após o cancelamento")
+		return nil, 0
+	}
+}
+
+func TestFFmpegService_CancelStopsProcessAndRemovesPartialOutput(t *testing.T) {
+	s := NewFFmpegServiceWithGracePeriod(5 * time.Second)
+	s.ffmpegPath = newFakeFFmpeg(t, "exit 255")
+	s.ffprobePath = newFakeFFprobe(t)
+	outputDir := filepath.Join(t.TempDir(), "video-1")
+
+	err, elapsed := convertAndCancel(t, s, outputDir)
+
+	assert.ErrorIs(t, err, ErrConversionCanceled)
+	assert.ErrorIs(t, err, context.Canceled)
+	// O FFmpeg atendeu ao SIGINT, sem esperar o fim do prazo
+	assert.Less(t, elapsed, 2*time.Second)
+	assert.NoDirExists(t, outputDir)
+}
+
+func TestFFmpegService_CancelKillsProcessAfterGracePeriod(t *testing.T) {
+	s := NewFFmpegServiceWithGracePeriod(200 * time.Millisecond)
+	s.ffmpegPath = newFakeFFmpeg(t, "")
+	s.ffprobePath = newFakeFFprobe(t)
+	outputDir := t.TempDir()
+	other := filepath.Join(outputDir, "outro.txt")
+	require.NoError(t, os.WriteFile(other, nil, 0o644))
+
+	err, elapsed := convertAndCancel(t, s, outputDir)
+
+	assert.ErrorIs(t, err, ErrConversionCanceled)
+	assert.GreaterOrEqual(t, elapsed, 200*time.Millisecond)
+	assert.NoFileExists(t, filepath.Join(outputDir, "playlist0.ts"))
+	// O diretório já existia: apenas os arquivos da conversão são removidos
+	assert.FileExists(t, other)
+}
+
+func TestFFmpegService_AlreadyCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewFFmpegService().ConvertToHLS(ctx, "input.mp4", t.TempDir(), DefaultEncodingProfile(), nil)
+
+	assert.ErrorIs(t, err, ErrConversionCanceled)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFFmpegService_FailureLogsStderrAtDebug(t *testing.T) {
+	var logs bytes.Buffer
+	config := DefaultFFmpegServiceConfig()
+	config.Logger = slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	s := NewFFmpegServiceWithConfig(config)
+	s.ffprobePath = newFakeFFprobe(t)
+
+	s.ffmpegPath = filepath.Join(t.TempDir(), "ffmpeg")
+	script := "#!/bin/sh\necho 'primeira linha' >&2\nprintf 'input.mp4: Invalid data' >&2\nexit 1\n"
+	require.NoError(t, os.WriteFile(s.ffmpegPath, []byte(script), 0o755))
+
+	_, err := s.ConvertToHLS(context.Background(), "input.mp4", t.TempDir(), DefaultEncodingProfile(), nil)
+
+	// O trecho final do stderr fica no erro, e cada linha, inclusive a última sem quebra, vai para o logger
+	require.Error(t, err)
+	assert.Contains(t, StderrFromError(err), "input.mp4: Invalid data")
+	assert.Contains(t, logs.String(), "level=DEBUG")
+	assert.Contains(t, logs.String(), `line="primeira linha"`)
+	assert.Contains(t, logs.String(), `line="input.mp4: Invalid data"`)
+}
@@ -45,7 +45,7 @@ func TestFFmpegService_ConvertToHLS_Integration(t *testing.T) {
 
 	// Executar a conversão
 	ctx := context.Background()
-	outputFiles, err := ffmpegService.ConvertToHLS(ctx, testVideoPath, outputDir)
+	outputFiles, err := ffmpegService.ConvertToHLS(ctx, testVideoPath, outputDir, service.DefaultEncodingProfile(), nil)
 
 	// Verificar se não houve erro
 	require.NoError(t, err)
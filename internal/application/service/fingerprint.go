@@ -0,0 +1,26 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+// FingerprintFile calcula a impressão digital SHA-256 (hex) do arquivo informado
+// O arquivo é lido em streaming, sem carregar o vídeo inteiro em memória
+func FingerprintFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("erro ao abrir arquivo para calcular impressão digital: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("erro ao calcular impressão digital do arquivo: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	ffmpeg "github.com/u2takey/ffmpeg-go"
+)
+
+const (
+	// masterPlaylistName é o manifesto que lista as variantes, usado pelos players para trocar de qualidade
+	masterPlaylistName = "master.m3u8"
+
+	hlsAudioCodec = "mp4a.40.2" // AAC-LC, no formato do atributo CODECS
+)
+
+// avcProfileIDs são os prefixos do atributo CODECS (profile_idc e flags de restrição) de cada perfil H.264
+var avcProfileIDs = map[string]string{
+	"baseline": "42e0",
+	"main":     "4d40",
+	"high":     "6400",
+}
+
+// HLSRendition é um degrau da escada de qualidades (ABR) gerada na conversão para HLS
+type HLSRendition struct {
+	Height       int    `json:"height"`        // Resolução do degrau no lado menor do vídeo (ex.: 720 para 720p)
+	VideoBitrate int    `json:"video_bitrate"` // Taxa de bits média do vídeo, em kbps
+	MaxBitrate   int    `json:"max_bitrate"`   // Taxa de bits máxima do vídeo, em kbps
+	Profile      string `json:"profile"`       // Perfil H.264: baseline, main ou high
+	Level        string `json:"level"`         // Nível H.264 (ex.: "4.1")
+}
+
+// Name retorna o nome da rendition (ex.: "720p"), usado nos arquivos da variante
+func (r HLSRendition) Name() string {
+	return fmt.Sprintf("%dp", r.Height)
+}
+
+// DefaultHLSLadder retorna a escada de qualidades padrão: 1080p, 720p, 480p e 360p
+func DefaultHLSLadder() []HLSRendition {
+	return []HLSRendition{
+		{Height: 1080, VideoBitrate: 5000, MaxBitrate: 5350, Profile: "high", Level: "4.1"},
+		{Height: 720, VideoBitrate: 2800, MaxBitrate: 2996, Profile: "high", Level: "3.1"},
+		{Height: 480, VideoBitrate: 1400, MaxBitrate: 1498, Profile: "main", Level: "3.1"},
+		{Height: 360, VideoBitrate: 800, MaxBitrate: 856, Profile: "main", Level: "3.0"},
+	}
+}
+
+// normalizeLadder ordena a escada da maior para a menor resolução e completa os campos vazios
+// Degraus sem resolução ou taxa de bits são ignorados, assim como resoluções repetidas
+func normalizeLadder(ladder []HLSRendition) []HLSRendition {
+	normalized := make([]HLSRendition, 0, len(ladder))
+	for _, rendition := range ladder {
+		if rendition.Height <= 0 || rendition.VideoBitrate <= 0 {
+			continue
+		}
+		if slices.ContainsFunc(normalized, func(r HLSRendition) bool { return r.Height == rendition.Height }) {
+			continue
+		}
+
+		rendition.MaxBitrate = max(rendition.MaxBitrate, rendition.VideoBitrate)
+		rendition.Profile = strings.ToLower(rendition.Profile)
+		if _, ok := avcProfileIDs[rendition.Profile]; !ok {
+			rendition.Profile = "high"
+		}
+		if _, err := strconv.ParseFloat(rendition.Level, 64); err != nil {
+			rendition.Level = "4.1"
+		}
+
+		normalized = append(normalized, rendition)
+	}
+
+	slices.SortFunc(normalized, func(a, b HLSRendition) int {
+		return cmp.Compare(b.Height, a.Height)
+	})
+
+	return normalized
+}
+
+// hlsVariant é uma rendition ajustada às dimensões do vídeo de entrada
+type hlsVariant struct {
+	rendition HLSRendition
+	width     int
+	height    int
+}
+
+// codecs retorna o atributo CODECS da variante (ex.: "avc1.640029,mp4a.40.2")
+func (v hlsVariant) codecs(hasAudio bool) string {
+	level, _ := strconv.ParseFloat(v.rendition.Level, 64)
+	codecs := fmt.Sprintf("avc1.%s%02x", avcProfileIDs[v.rendition.Profile], int(math.Round(level*10)))
+	if hasAudio {
+		codecs += "," + hlsAudioCodec
+	}
+	return codecs
+}
+
+// selectVariants escolhe os degraus da escada que não ampliam o vídeo de entrada, mantendo a proporção
+// Se o vídeo for menor que todos os degraus, gera uma única variante na resolução original, com as
+// taxas de bits do menor degrau
+func selectVariants(ladder []HLSRendition, width, height int) []hlsVariant {
+	shortSide := min(width, height)
+
+	var variants []hlsVariant
+	for _, rendition := range ladder {
+		if rendition.Height <= shortSide {
+			w, h := scaleToShortSide(width, height, rendition.Height)
+			variants = append(variants, hlsVariant{rendition: rendition, width: w, height: h})
+		}
+	}
+
+	if len(variants) == 0 && len(ladder) > 0 {
+		rendition := ladder[len(ladder)-1]
+		w, h := scaleToShortSide(width, height, shortSide)
+		rendition.Height = min(w, h)
+		variants = append(variants, hlsVariant{rendition: rendition, width: w, height: h})
+	}
+
+	return variants
+}
+
+// scaleToShortSide calcula as dimensões com o lado menor igual a shortSide, arredondadas para números
+// pares, como exige o H.264 com subamostragem 4:2:0
+func scaleToShortSide(width, height, shortSide int) (int, int) {
+	even := func(value float64) int {
+		return max(2, int(math.Round(value/2))*2)
+	}
+
+	if width >= height {
+		return even(float64(width) * float64(shortSide) / float64(height)), even(float64(shortSide))
+	}
+	return even(float64(shortSide)), even(float64(height) * float64(shortSide) / float64(width))
+}
+
+// hlsVariantArgs retorna os argumentos do FFmpeg que geram todas as variantes em uma única execução:
+// o vídeo é dividido e redimensionado uma vez por variante, e cada uma recebe suas taxas de bits,
+// perfil e nível
+// Com CRF, a taxa média não é fixada e a taxa máxima do degrau limita a qualidade constante
+func hlsVariantArgs(variants []hlsVariant, profile EncodingProfile, hasAudio bool, outputDir string) ffmpeg.KwArgs {
+	args := ffmpeg.KwArgs{}
+
+	filters := []string{fmt.Sprintf("[0:v]split=%d", len(variants))}
+	var maps, streamMap []string
+
+	for i, variant := range variants {
+		filters[0] += fmt.Sprintf("[s%d]", i)
+		filters = append(filters, fmt.Sprintf("[s%d]scale=%d:%d[v%d]", i, variant.width, variant.height, i))
+
+		maps = append(maps, fmt.Sprintf("[v%d]", i))
+		entry := fmt.Sprintf("v:%d", i)
+		if hasAudio {
+			maps = append(maps, "0:a:0")
+			entry += fmt.Sprintf(",a:%d", i)
+		}
+		streamMap = append(streamMap, entry+",name:"+variant.rendition.Name())
+
+		rendition := variant.rendition
+		if profile.CRF == 0 {
+			args[fmt.Sprintf("b:v:%d", i)] = fmt.Sprintf("%dk", rendition.VideoBitrate)
+		}
+		args[fmt.Sprintf("maxrate:v:%d", i)] = fmt.Sprintf("%dk", rendition.MaxBitrate)
+		args[fmt.Sprintf("bufsize:v:%d", i)] = fmt.Sprintf("%dk", 2*rendition.VideoBitrate)
+		args[fmt.Sprintf("profile:v:%d", i)] = rendition.Profile
+		args[fmt.Sprintf("level:v:%d", i)] = rendition.Level
+	}
+
+	args["filter_complex"] = strings.Join(filters, ";")
+	args["map"] = maps
+	args["var_stream_map"] = strings.Join(streamMap, " ")
+	args["hls_segment_filename"] = filepath.Join(outputDir, "playlist_%v_%d.ts")
+
+	return args
+}
+
+// variantPlaylistPattern é o caminho das playlists das variantes; o FFmpeg troca %v pelo nome da variante
+func variantPlaylistPattern(outputDir string) string {
+	return filepath.Join(outputDir, "playlist_%v.m3u8")
+}
+
+// writeMasterPlaylist grava a playlist principal, com uma entrada por variante
+// BANDWIDTH usa a taxa máxima e AVERAGE-BANDWIDTH a média, somando o áudio quando houver;
+// com CRF a taxa média não é conhecida de antemão e AVERAGE-BANDWIDTH é omitido
+func writeMasterPlaylist(outputDir string, variants []hlsVariant, profile EncodingProfile, hasAudio bool) error {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-INDEPENDENT-SEGMENTS\n")
+
+	audio := 0
+	if hasAudio {
+		audio = profile.AudioBitrate
+	}
+
+	for _, variant := range variants {
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:BANDWIDTH=%d", (variant.rendition.MaxBitrate+audio)*1000)
+		if profile.CRF == 0 {
+			fmt.Fprintf(&b, ",AVERAGE-BANDWIDTH=%d", (variant.rendition.VideoBitrate+audio)*1000)
+		}
+		fmt.Fprintf(&b, ",RESOLUTION=%dx%d,CODECS=\"%s\"\n", variant.width, variant.height, variant.codecs(hasAudio))
+		fmt.Fprintf(&b, "playlist_%s.m3u8\n", variant.rendition.Name())
+	}
+
+	if err := os.WriteFile(filepath.Join(outputDir, masterPlaylistName), []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("erro ao gravar playlist principal: %w", err)
+	}
+
+	return nil
+}
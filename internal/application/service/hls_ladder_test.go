@@ -0,0 +1,117 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeLadder(t *testing.T) {
+	ladder := normalizeLadder([]HLSRendition{
+		{Height: 480, VideoBitrate: 1400, Profile: "Main", Level: "3.1"},
+		{Height: 0, VideoBitrate: 800},
+		{Height: 1080, VideoBitrate: 5000, MaxBitrate: 5350},
+		{Height: 480, VideoBitrate: 1000},
+	})
+
+	require.Len(t, ladder, 2)
+	assert.Equal(t, 1080, ladder[0].Height)
+	assert.Equal(t, "high", ladder[0].Profile)
+	assert.Equal(t, "4.1", ladder[0].Level)
+	assert.Equal(t, 480, ladder[1].Height)
+	assert.Equal(t, "main", ladder[1].Profile)
+	// Sem taxa máxima, a taxa média é usada
+	assert.Equal(t, 1400, ladder[1].MaxBitrate)
+}
+
+func TestSelectVariants(t *testing.T) {
+	ladder := DefaultHLSLadder()
+
+	dimensions := func(variants []hlsVariant) [][2]int {
+		var result [][2]int
+		for _, variant := range variants {
+			result = append(result, [2]int{variant.width, variant.height})
+		}
+		return result
+	}
+
+	tests := []struct {
+		name          string
+		width, height int
+		want          [][2]int
+	}{
+		{"1080p usa toda a escada", 1920, 1080, [][2]int{{1920, 1080}, {1280, 720}, {854, 480}, {640, 360}}},
+		{"720p não é ampliado", 1280, 720, [][2]int{{1280, 720}, {854, 480}, {640, 360}}},
+		{"vertical usa o lado menor", 1080, 1920, [][2]int{{1080, 1920}, {720, 1280}, {480, 854}, {360, 640}}},
+		{"menor que a escada mantém a resolução", 320, 240, [][2]int{{320, 240}}},
+		{"dimensões ímpares são arredondadas", 1279, 719, [][2]int{{854, 480}, {640, 360}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, dimensions(selectVariants(ladder, tt.width, tt.height)))
+		})
+	}
+
+	// A variante única de um vídeo pequeno usa as taxas do menor degrau
+	small := selectVariants(ladder, 320, 240)
+	assert.Equal(t, "240p", small[0].rendition.Name())
+	assert.Equal(t, 800, small[0].rendition.VideoBitrate)
+}
+
+func TestHLSVariantArgs(t *testing.T) {
+	variants := selectVariants(DefaultHLSLadder(), 1280, 720)
+
+	args := hlsVariantArgs(variants, DefaultEncodingProfile(), true, "/hls/video-1")
+
+	assert.Equal(t, "[0:v]split=3[s0][s1][s2];[s0]scale=1280:720[v0];[s1]scale=854:480[v1];[s2]scale=640:360[v2]", args["filter_complex"])
+	assert.Equal(t, []string{"[v0]", "0:a:0", "[v1]", "0:a:0", "[v2]", "0:a:0"}, args["map"])
+	assert.Equal(t, "v:0,a:0,name:720p v:1,a:1,name:480p v:2,a:2,name:360p", args["var_stream_map"])
+	assert.Equal(t, "/hls/video-1/playlist_%v_%d.ts", args["hls_segment_filename"])
+	assert.Equal(t, "2800k", args["b:v:0"])
+	assert.Equal(t, "2996k", args["maxrate:v:0"])
+	assert.Equal(t, "main", args["profile:v:1"])
+	assert.Equal(t, "3.0", args["level:v:2"])
+
+	// Sem áudio, as variantes têm apenas vídeo
+	args = hlsVariantArgs(variants[:1], DefaultEncodingProfile(), false, "/hls/video-1")
+	assert.Equal(t, []string{"[v0]"}, args["map"])
+	assert.Equal(t, "v:0,name:720p", args["var_stream_map"])
+
+	// Com CRF, apenas a taxa máxima é fixada
+	args = hlsVariantArgs(variants[:1], EncodingProfile{CRF: 23}, true, "/hls/video-1")
+	assert.NotContains(t, args, "b:v:0")
+	assert.Equal(t, "2996k", args["maxrate:v:0"])
+}
+
+func TestWriteMasterPlaylist(t *testing.T) {
+	dir := t.TempDir()
+	variants := selectVariants(DefaultHLSLadder(), 1920, 1080)[:2]
+
+	require.NoError(t, writeMasterPlaylist(dir, variants, DefaultEncodingProfile(), true))
+
+	content, err := os.ReadFile(filepath.Join(dir, masterPlaylistName))
+	require.NoError(t, err)
+	assert.Equal(t, "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-INDEPENDENT-SEGMENTS\n"+
+		"#EXT-X-STREAM-INF:BANDWIDTH=5478000,AVERAGE-BANDWIDTH=5128000,RESOLUTION=1920x1080,CODECS=\"avc1.640029,mp4a.40.2\"\n"+
+		"playlist_1080p.m3u8\n"+
+		"#EXT-X-STREAM-INF:BANDWIDTH=3124000,AVERAGE-BANDWIDTH=2928000,RESOLUTION=1280x720,CODECS=\"avc1.64001f,mp4a.40.2\"\n"+
+		"playlist_720p.m3u8\n", string(content))
+
+	// Com CRF a taxa média é desconhecida; sem áudio, apenas o vídeo conta
+	require.NoError(t, writeMasterPlaylist(dir, variants[1:], EncodingProfile{CRF: 23, AudioBitrate: 128}, false))
+
+	content, err = os.ReadFile(filepath.Join(dir, masterPlaylistName))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "#EXT-X-STREAM-INF:BANDWIDTH=2996000,RESOLUTION=1280x720,CODECS=\"avc1.64001f\"\n")
+}
+
+func TestHLSVariantCodecs(t *testing.T) {
+	variant := hlsVariant{rendition: HLSRendition{Profile: "main", Level: "3.0"}}
+
+	assert.Equal(t, "avc1.4d401e,mp4a.40.2", variant.codecs(true))
+	assert.Equal(t, "avc1.4d401e", variant.codecs(false))
+}
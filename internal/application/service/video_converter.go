@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -9,15 +10,18 @@ import (
 	"time"
 
 	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	"github.com/devfullcycle/golangtechweek/internal/domain/event"
 	"github.com/devfullcycle/golangtechweek/internal/domain/repository"
 	"github.com/devfullcycle/golangtechweek/pkg/workerpool"
+	"github.com/google/uuid"
 )
 
 // ConversionJob representa um trabalho de conversão de vídeo
 type ConversionJob struct {
-	VideoID   string // ID do vídeo no banco de dados
-	InputPath string // Caminho do arquivo de entrada
-	OutputDir string // Diretório de saída para os arquivos convertidos
+	VideoID   string           // ID do vídeo no banco de dados
+	InputPath string           // Caminho do arquivo de entrada
+	OutputDir string           // Diretório de saída para os arquivos convertidos
+	Profile   *EncodingProfile // Perfil de codificação (opcional; padrão: o perfil do conversor)
 }
 
 // ConversionResult representa o resultado de uma conversão
@@ -31,16 +35,40 @@ type ConversionResult struct {
 
 // VideoConverterService implementa o serviço de conversão de vídeos
 type VideoConverterService struct {
-	ffmpeg     FFmpegServiceInterface
-	videoRepo  repository.VideoRepository
-	workerPool workerpool.WorkerPool
-	logger     *slog.Logger
+	ffmpeg           FFmpegServiceInterface
+	videoRepo        repository.VideoRepository
+	workerPool       workerpool.WorkerPool
+	dispatcher       event.Dispatcher
+	attemptRepo      repository.ProcessingAttemptRepository
+	fileRepo         repository.VideoFileRepository
+	unitOfWork       repository.UnitOfWork
+	workerID         string
+	profile          EncodingProfile
+	progressInterval time.Duration
+	leaseDuration    time.Duration
+	logger           *slog.Logger
 }
 
+const (
+	// defaultProgressInterval é o intervalo padrão entre gravações de progresso no banco de dados
+	defaultProgressInterval = 5 * time.Second
+
+	// defaultLeaseDuration é a duração padrão da concessão sobre o vídeo em conversão
+	defaultLeaseDuration = 2 * time.Minute
+)
+
 // VideoConverterConfig representa a configuração do serviço de conversão de vídeo
 type VideoConverterConfig struct {
-	WorkerCount int          // Número de workers para processamento paralelo
-	Logger      *slog.Logger // Logger para registro de eventos
+	WorkerCount       int                                    // Número de workers para processamento paralelo
+	Logger            *slog.Logger                           // Logger para registro de eventos
+	EventDispatcher   event.Dispatcher                       // Dispatcher dos eventos de domínio (opcional)
+	AttemptRepository repository.ProcessingAttemptRepository // Repositório do histórico de tentativas (opcional)
+	UnitOfWork        repository.UnitOfWork                  // Torna atômica a conclusão do vídeo (opcional)
+	FileRepository    repository.VideoFileRepository         // Registra cada arquivo HLS gerado (opcional)
+	WorkerID          string                                 // Identificador deste worker/host nas tentativas (padrão: hostname-pid)
+	ProgressInterval  time.Duration                          // Intervalo mínimo entre gravações de progresso no banco
+	LeaseDuration     time.Duration                          // Duração da concessão sobre o vídeo, renovada enquanto o FFmpeg reporta progresso
+	EncodingProfile   *EncodingProfile                       // Perfil dos jobs que não informam um (padrão: DefaultEncodingProfile)
 }
 
 // DefaultVideoConverterConfig retorna uma configuração padrão para o serviço
@@ -50,6 +78,8 @@ func DefaultVideoConverterConfig() VideoConverterConfig {
 		Logger: slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 			Level: slog.LevelInfo,
 		})),
+		ProgressInterval: defaultProgressInterval,
+		LeaseDuration:    defaultLeaseDuration,
 	}
 }
 
@@ -65,10 +95,35 @@ func NewVideoConverter(ffmpeg FFmpegServiceInterface, videoRepo repository.Video
 		}))
 	}
 
+	if config.WorkerID == "" {
+		config.WorkerID = defaultWorkerID()
+	}
+
+	if config.ProgressInterval <= 0 {
+		config.ProgressInterval = defaultProgressInterval
+	}
+
+	if config.LeaseDuration <= 0 {
+		config.LeaseDuration = defaultLeaseDuration
+	}
+
+	profile := DefaultEncodingProfile()
+	if config.EncodingProfile != nil {
+		profile = *config.EncodingProfile
+	}
+
 	service := &VideoConverterService{
-		ffmpeg:    ffmpeg,
-		videoRepo: videoRepo,
-		logger:    config.Logger,
+		ffmpeg:           ffmpeg,
+		videoRepo:        videoRepo,
+		dispatcher:       config.EventDispatcher,
+		attemptRepo:      config.AttemptRepository,
+		fileRepo:         config.FileRepository,
+		unitOfWork:       config.UnitOfWork,
+		workerID:         config.WorkerID,
+		profile:          profile,
+		progressInterval: config.ProgressInterval,
+		leaseDuration:    config.LeaseDuration,
+		logger:           config.Logger,
 	}
 
 	// Cria a função de processamento para o worker pool
@@ -171,6 +226,12 @@ func (c *VideoConverterService) IsRunning() bool {
 // processJob processa um trabalho de conversão de vídeo
 func (c *VideoConverterService) processJob(ctx context.Context, job ConversionJob) ConversionResult {
 	startTime := time.Now()
+
+	// As alterações feitas por este processamento são atribuídas ao worker e agrupadas no histórico de auditoria
+	ctx = repository.WithActor(ctx, repository.WorkerActor(c.workerID))
+	if repository.CorrelationIDFromContext(ctx) == "" {
+		ctx = repository.WithCorrelationID(ctx, uuid.NewString())
+	}
 	c.logger.Info("Iniciando processamento de vídeo", "video_id", job.VideoID)
 
 	// Inicializa o resultado com falha por padrão
@@ -180,8 +241,15 @@ func (c *VideoConverterService) processJob(ctx context.Context, job ConversionJo
 		Duration: time.Since(startTime),
 	}
 
-	// Etapa 1: Atualiza o status do vídeo para "processing"
-	if err := c.updateVideoStatusToProcessing(ctx, job.VideoID); err != nil {
+	// Etapa 1: Carrega o vídeo e o reivindica para este worker
+	video, err := c.videoRepo.FindByID(ctx, job.VideoID)
+	if err != nil {
+		c.logger.Error("Erro ao buscar vídeo", "video_id", job.VideoID, "error", err)
+		result.Error = fmt.Errorf("erro ao buscar vídeo: %w", err)
+		return result
+	}
+
+	if err := c.claimVideo(ctx, video); err != nil {
 		result.Error = err
 		return result
 	}
@@ -189,9 +257,18 @@ func (c *VideoConverterService) processJob(ctx context.Context, job ConversionJo
 	// Etapa 2: Prepara o diretório de saída
 	outputDir := c.prepareOutputDirectory(job)
 
-	// Etapa 3: Converte o vídeo para HLS
-	outputFiles, err := c.convertVideoToHLS(ctx, job.VideoID, job.InputPath, outputDir)
+	// Etapa 3: Registra a tentativa e converte o vídeo para HLS
+	profile := c.profile
+	if job.Profile != nil {
+		profile = *job.Profile
+	}
+	profile = profile.WithDefaults()
+
+	attempt := c.startAttempt(ctx, video.ID, profile)
+
+	outputFiles, err := c.convertVideoToHLS(ctx, video, job.InputPath, outputDir, profile)
 	if err != nil {
+		c.finishAttempt(ctx, attempt, err)
 		result.Error = err
 		return result
 	}
@@ -202,7 +279,8 @@ func (c *VideoConverterService) processJob(ctx context.Context, job ConversionJo
 	result.Duration = time.Since(startTime)
 
 	// Etapa 5: Processa os arquivos de saída e atualiza o banco de dados
-	c.processOutputFiles(ctx, job.VideoID, outputFiles)
+	c.processOutputFiles(ctx, video, outputFiles)
+	c.finishAttempt(ctx, attempt, nil)
 
 	c.logger.Info("Processamento de vídeo concluído com sucesso",
 		"video_id", job.VideoID,
@@ -212,18 +290,143 @@ func (c *VideoConverterService) processJob(ctx context.Context, job ConversionJo
 	return result
 }
 
-// updateVideoStatusToProcessing atualiza o status do vídeo para "processing"
-func (c *VideoConverterService) updateVideoStatusToProcessing(ctx context.Context, videoID string) error {
-	err := c.videoRepo.UpdateStatus(ctx, videoID, entity.StatusProcessing, "")
+// claimVideo atualiza o status do vídeo para "processing" com uma concessão para este worker
+// A concessão é renovada pelo progresso do FFmpeg e é o sinal de vida que o reaper consulta
+// Um vídeo com concessão ativa de outro worker, ou alterado desde a leitura, não é processado nem marcado como falho
+func (c *VideoConverterService) claimVideo(ctx context.Context, video *entity.Video) error {
+	now := time.Now()
+	if video.HasActiveLease(now) && video.LeaseOwner != c.workerID {
+		c.logger.Warn("Vídeo já está sendo processado por outro worker", "video_id", video.ID, "lease_owner", video.LeaseOwner)
+		return fmt.Errorf("vídeo em processamento por %s: %w", video.LeaseOwner, repository.ErrLeaseNotHeld)
+	}
+
+	video.MarkAsProcessing()
+	video.AcquireLease(c.workerID, now.Add(c.leaseDuration))
+
+	err := c.videoRepo.Update(ctx, video)
 	if err != nil {
 		errWithContext := fmt.Errorf("erro ao atualizar status do vídeo para processing: %w", err)
-		c.logger.Error("Erro ao atualizar status do vídeo", "video_id", videoID, "error", err)
-		c.videoRepo.UpdateStatus(ctx, videoID, entity.StatusError, errWithContext.Error())
+		c.logger.Error("Erro ao atualizar status do vídeo", "video_id", video.ID, "error", err)
+		video.PullEvents() // O início do processamento não foi persistido
+		if !errors.Is(err, repository.ErrConcurrentModification) {
+			c.markVideoAsFailed(ctx, video, errWithContext)
+		}
 		return errWithContext
 	}
+
+	dispatchVideoEvents(ctx, c.dispatcher, c.logger, video)
+	return nil
+}
+
+// markVideoAsFailed atualiza o status do vídeo para "failed" com a mensagem de erro
+func (c *VideoConverterService) markVideoAsFailed(ctx context.Context, video *entity.Video, cause error) {
+	video.MarkAsFailed(cause.Error())
+
+	if err := c.videoRepo.UpdateStatus(ctx, video.ID, video.Version, entity.StatusError, video.ErrorMessage); err != nil {
+		c.logger.Error("Erro ao atualizar status do vídeo para failed", "video_id", video.ID, "error", err)
+		video.PullEvents() // A falha não foi persistida, então o evento não deve ser despachado
+		return
+	}
+	video.Version++
+
+	dispatchVideoEvents(ctx, c.dispatcher, c.logger, video)
+}
+
+// UpdateUploadStatus atualiza o status de upload do vídeo para S3
+// Os eventos, como VideoUploadCompleted, só são despachados após o repositório salvar o novo status
+func (c *VideoConverterService) UpdateUploadStatus(ctx context.Context, videoID, uploadStatus string) error {
+	video, err := c.videoRepo.FindByID(ctx, videoID)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar vídeo: %w", err)
+	}
+
+	video.UpdateUploadStatus(uploadStatus)
+
+	if err := c.videoRepo.UpdateS3Status(ctx, video.ID, video.Version, uploadStatus); err != nil {
+		video.PullEvents() // O novo status não foi persistido, então o evento não deve ser despachado
+		return fmt.Errorf("erro ao atualizar status de upload do vídeo: %w", err)
+	}
+	video.Version++
+
+	dispatchVideoEvents(ctx, c.dispatcher, c.logger, video)
 	return nil
 }
 
+// startAttempt registra o início de uma nova tentativa de processamento, com os parâmetros e o nome do perfil
+// Retorna nil se o histórico de tentativas não estiver configurado ou não puder ser gravado
+func (c *VideoConverterService) startAttempt(ctx context.Context, videoID string, profile EncodingProfile) *entity.ProcessingAttempt {
+	if c.attemptRepo == nil {
+		return nil
+	}
+
+	params := profile.Params()
+	params["encoding_profile"] = profile.Name
+
+	attempt := entity.NewProcessingAttempt(videoID, c.workerID, params)
+	if err := c.attemptRepo.Create(ctx, attempt); err != nil {
+		c.logger.Error("Erro ao registrar tentativa de processamento", "video_id", videoID, "error", err)
+		// Não falha a conversão por erro no histórico de tentativas
+		return nil
+	}
+
+	return attempt
+}
+
+// finishAttempt registra o resultado da tentativa de processamento
+// convErr nil indica sucesso; ErrConversionCanceled e erros de cancelamento do contexto marcam a tentativa como cancelada
+func (c *VideoConverterService) finishAttempt(ctx context.Context, attempt *entity.ProcessingAttempt, convErr error) {
+	if attempt == nil {
+		return
+	}
+
+	switch {
+	case convErr == nil:
+		attempt.MarkAsSucceeded()
+	case isConversionCanceled(convErr):
+		attempt.MarkAsCanceled(convErr.Error(), StderrFromError(convErr))
+	default:
+		attempt.MarkAsFailed(convErr.Error(), StderrFromError(convErr))
+	}
+
+	// Usa um contexto próprio para que o resultado seja gravado mesmo após o cancelamento do job
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	if err := c.attemptRepo.Finish(finishCtx, attempt); err != nil {
+		c.logger.Error("Erro ao finalizar tentativa de processamento", "video_id", attempt.VideoID, "attempt_id", attempt.ID, "error", err)
+	}
+}
+
+// isConversionCanceled indica se a conversão foi interrompida pelo cancelamento do job, e não por falha do vídeo
+func isConversionCanceled(err error) bool {
+	return errors.Is(err, ErrConversionCanceled) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// requeueCanceledVideo devolve para a fila um vídeo cuja conversão foi cancelada, para que seja processado
+// novamente, em vez de marcá-lo como falho
+// Usa um contexto próprio, como finishAttempt, já que o contexto do job está cancelado
+func (c *VideoConverterService) requeueCanceledVideo(ctx context.Context, video *entity.Video) {
+	requeueCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	video.Requeue()
+	if err := c.videoRepo.Update(requeueCtx, video); err != nil {
+		c.logger.Error("Erro ao devolver vídeo cancelado para a fila", "video_id", video.ID, "error", err)
+		return
+	}
+
+	c.logger.Info("Conversão cancelada, vídeo devolvido para a fila", "video_id", video.ID)
+}
+
+// defaultWorkerID retorna um identificador do processo no formato hostname-pid
+func defaultWorkerID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
 // prepareOutputDirectory prepara o diretório de saída para os arquivos convertidos
 func (c *VideoConverterService) prepareOutputDirectory(job ConversionJob) string {
 	outputDir := job.OutputDir
@@ -234,41 +437,205 @@ func (c *VideoConverterService) prepareOutputDirectory(job ConversionJob) string
 }
 
 // convertVideoToHLS converte o vídeo para o formato HLS
-func (c *VideoConverterService) convertVideoToHLS(ctx context.Context, videoID, inputPath, outputDir string) ([]OutputFile, error) {
-	outputFiles, err := c.ffmpeg.ConvertToHLS(ctx, inputPath, outputDir)
+// Uma conversão cancelada devolve o vídeo para a fila; as demais falhas o marcam como falho
+func (c *VideoConverterService) convertVideoToHLS(ctx context.Context, video *entity.Video, inputPath, outputDir string, profile EncodingProfile) ([]OutputFile, error) {
+	outputFiles, err := c.ffmpeg.ConvertToHLS(ctx, inputPath, outputDir, profile, c.newProgressReporter(ctx, video))
 	if err != nil {
 		errWithContext := fmt.Errorf("erro ao converter vídeo para HLS: %w", err)
-		c.logger.Error("Erro ao converter vídeo para HLS", "video_id", videoID, "error", err)
-		c.videoRepo.UpdateStatus(ctx, videoID, entity.StatusError, errWithContext.Error())
+		if isConversionCanceled(err) {
+			c.requeueCanceledVideo(ctx, video)
+			return nil, errWithContext
+		}
+		c.logger.Error("Erro ao converter vídeo para HLS", "video_id", video.ID, "error", err)
+		c.markVideoAsFailed(ctx, video, errWithContext)
 		return nil, errWithContext
 	}
 	return outputFiles, nil
 }
 
+// newProgressReporter cria a função que recebe o progresso do FFmpeg e o persiste no banco de dados
+// As gravações são limitadas a uma por ProgressInterval (exceto a do fim da conversão)
+// e ignoradas quando o percentual não mudou, para não sobrecarregar o banco em conversões longas
+// A concessão sobre o vídeo é renovada a cada terço de LeaseDuration, mesmo sem mudança no percentual
+func (c *VideoConverterService) newProgressReporter(ctx context.Context, video *entity.Video) ProgressFunc {
+	startedAt := time.Now()
+	lastRenewal := startedAt
+	var lastWrite time.Time
+	lastPercent := -1
+
+	return func(progress ConversionProgress) {
+		now := time.Now()
+		percent := int(progress.Percent)
+
+		if now.Sub(lastRenewal) >= c.leaseDuration/3 {
+			c.renewLease(ctx, video, now)
+			lastRenewal = now
+		}
+
+		if !progress.Done && (percent == lastPercent || now.Sub(lastWrite) < c.progressInterval) {
+			return
+		}
+
+		stage := progress.Stage
+		if progress.Done {
+			// O FFmpeg terminou, mas o vídeo só é concluído após o processamento dos arquivos
+			stage = entity.ProcessingStageFinalizing
+		}
+
+		video.UpdateProgress(percent, stage, estimateCompletion(startedAt, now, progress.Percent))
+
+		err := c.videoRepo.UpdateProgress(ctx, video.ID, video.Progress, video.ProcessingStage, video.EstimatedCompletionAt)
+		if err != nil {
+			c.logger.Error("Erro ao atualizar progresso do vídeo", "video_id", video.ID, "error", err)
+			// Não falha a conversão por erro na atualização do progresso
+			return
+		}
+
+		lastWrite = now
+		lastPercent = percent
+	}
+}
+
+// renewLease estende a concessão deste worker sobre o vídeo
+// ErrLeaseNotHeld indica que o reaper devolveu o vídeo para a fila enquanto o FFmpeg não reportava progresso
+func (c *VideoConverterService) renewLease(ctx context.Context, video *entity.Video, now time.Time) {
+	err := c.videoRepo.RenewLease(ctx, video.ID, c.workerID, c.leaseDuration)
+	if err != nil {
+		c.logger.Error("Erro ao renovar concessão do vídeo", "video_id", video.ID, "error", err)
+		// Não falha a conversão por erro na renovação; a renovação é tentada novamente no próximo intervalo
+		return
+	}
+
+	video.AcquireLease(c.workerID, now.Add(c.leaseDuration))
+}
+
+// estimateCompletion estima o horário de término a partir do tempo decorrido e do percentual concluído
+// Retorna nil enquanto não houver progresso suficiente para estimar
+func estimateCompletion(startedAt, now time.Time, percent float64) *time.Time {
+	if percent <= 0 || percent >= 100 {
+		return nil
+	}
+
+	elapsed := now.Sub(startedAt)
+	remaining := time.Duration(float64(elapsed) * (100 - percent) / percent)
+	eta := now.Add(remaining)
+
+	return &eta
+}
+
 // processOutputFiles processa os arquivos de saída e atualiza o banco de dados
-func (c *VideoConverterService) processOutputFiles(ctx context.Context, videoID string, outputFiles []OutputFile) {
+func (c *VideoConverterService) processOutputFiles(ctx context.Context, video *entity.Video, outputFiles []OutputFile) {
 	// Encontra o manifesto e os segmentos
 	manifestPath, hlsPath := c.findManifestAndHLSPaths(outputFiles)
+	files := c.describeVideoFiles(video.ID, outputFiles)
+
+	video.MarkAsCompleted(hlsPath, manifestPath)
+
+	// Sem unidade de trabalho, os caminhos, os arquivos e o status são gravados separadamente
+	// O evento de conclusão só é despachado se todas as gravações tiverem sucesso
+	if c.unitOfWork == nil {
+		persisted := true
+
+		// Atualiza os caminhos HLS e Manifest no banco de dados
+		if manifestPath != "" && hlsPath != "" {
+			persisted = c.updateHLSPaths(ctx, video, hlsPath, manifestPath)
+		}
+
+		if files != nil {
+			if err := c.fileRepo.ReplaceForVideo(ctx, video.ID, files); err != nil {
+				c.logger.Error("Erro ao registrar arquivos do vídeo", "video_id", video.ID, "error", err)
+				// Não falha a conversão por erro no registro dos arquivos
+				persisted = false
+			}
+		}
+
+		// Atualiza o status do vídeo para "completed"
+		if c.updateVideoStatusToCompleted(ctx, video) && persisted {
+			dispatchVideoEvents(ctx, c.dispatcher, c.logger, video)
+			return
+		}
+		video.PullEvents() // A conclusão não foi totalmente persistida, então o evento não deve ser despachado
+		return
+	}
+
+	if !c.completeVideoInTx(ctx, video, hlsPath, manifestPath, files) {
+		video.PullEvents() // A transação foi desfeita, então o evento não deve ser despachado
+		return
+	}
+	dispatchVideoEvents(ctx, c.dispatcher, c.logger, video)
+}
+
+// describeVideoFiles monta os registros dos arquivos gerados quando há um FileRepository configurado
+// Retorna nil se não houver repositório ou se algum arquivo não puder ser lido
+func (c *VideoConverterService) describeVideoFiles(videoID string, outputFiles []OutputFile) []*entity.VideoFile {
+	if c.fileRepo == nil {
+		return nil
+	}
+
+	files, err := describeOutputFiles(videoID, outputFiles)
+	if err != nil {
+		c.logger.Error("Erro ao ler arquivos gerados", "video_id", videoID, "error", err)
+		return nil
+	}
+	return files
+}
+
+// completeVideoInTx grava os caminhos HLS, os arquivos gerados e o status "completed" na mesma transação,
+// para que o vídeo nunca fique com apenas uma das alterações
+// Retorna true se a transação foi confirmada
+func (c *VideoConverterService) completeVideoInTx(ctx context.Context, video *entity.Video, hlsPath, manifestPath string, files []*entity.VideoFile) bool {
+	videoID := video.ID
+	var version int64
+
+	err := c.unitOfWork.RunInTx(ctx, func(ctx context.Context, repos repository.Repositories) error {
+		version = video.Version
+
+		if manifestPath != "" && hlsPath != "" {
+			if err := repos.Videos.UpdateHLSPath(ctx, videoID, version, hlsPath, manifestPath); err != nil {
+				return fmt.Errorf("erro ao atualizar caminhos HLS: %w", err)
+			}
+			version++
+		}
 
-	// Atualiza os caminhos HLS e Manifest no banco de dados
-	if manifestPath != "" && hlsPath != "" {
-		c.updateHLSPaths(ctx, videoID, hlsPath, manifestPath)
+		if files != nil {
+			fileRepo := repos.VideoFiles
+			if fileRepo == nil {
+				fileRepo = c.fileRepo
+			}
+			if err := fileRepo.ReplaceForVideo(ctx, videoID, files); err != nil {
+				return fmt.Errorf("erro ao registrar arquivos do vídeo: %w", err)
+			}
+		}
+
+		if err := repos.Videos.UpdateStatus(ctx, videoID, version, entity.StatusCompleted, ""); err != nil {
+			return fmt.Errorf("erro ao atualizar status: %w", err)
+		}
+		version++
+
+		return nil
+	})
+	if err != nil {
+		c.logger.Error("Erro ao concluir vídeo", "video_id", videoID, "error", err)
+		// Não falha a conversão por erro na conclusão do vídeo
+		return false
 	}
 
-	// Atualiza o status do vídeo para "completed"
-	c.updateVideoStatusToCompleted(ctx, videoID)
+	video.Version = version
+	return true
 }
 
 // findManifestAndHLSPaths encontra os caminhos do manifesto e do diretório HLS
+// O manifesto é a playlist principal (master.m3u8) ou, na sua ausência, o primeiro manifesto
 // Retorna o caminho do manifesto e o caminho do diretório HLS
 func (c *VideoConverterService) findManifestAndHLSPaths(outputFiles []OutputFile) (string, string) {
 	var manifestPath string
 	var hlsPath string
 
-	// Itera sobre os arquivos até encontrar tanto o manifesto quanto o primeiro segmento
+	// Itera sobre os arquivos até encontrar tanto a playlist principal quanto o primeiro segmento
 	for _, file := range outputFiles {
-		// Se ainda não encontramos o manifesto e este arquivo é um manifesto
-		if manifestPath == "" && file.Type == entity.FileTypeManifest {
+		// A playlist principal tem preferência sobre as playlists das variantes
+		if file.Type == entity.FileTypeManifest &&
+			(manifestPath == "" || filepath.Base(file.Path) == masterPlaylistName) {
 			manifestPath = file.Path
 		}
 
@@ -279,7 +646,7 @@ func (c *VideoConverterService) findManifestAndHLSPaths(outputFiles []OutputFile
 		}
 
 		// Se já encontramos ambos, podemos sair do loop
-		if manifestPath != "" && hlsPath != "" {
+		if filepath.Base(manifestPath) == masterPlaylistName && hlsPath != "" {
 			break
 		}
 	}
@@ -288,19 +655,27 @@ func (c *VideoConverterService) findManifestAndHLSPaths(outputFiles []OutputFile
 }
 
 // updateHLSPaths atualiza os caminhos HLS e Manifest no banco de dados
-func (c *VideoConverterService) updateHLSPaths(ctx context.Context, videoID, hlsPath, manifestPath string) {
-	err := c.videoRepo.UpdateHLSPath(ctx, videoID, hlsPath, manifestPath)
+// Retorna true se os caminhos foram persistidos
+func (c *VideoConverterService) updateHLSPaths(ctx context.Context, video *entity.Video, hlsPath, manifestPath string) bool {
+	err := c.videoRepo.UpdateHLSPath(ctx, video.ID, video.Version, hlsPath, manifestPath)
 	if err != nil {
-		c.logger.Error("Erro ao atualizar caminhos HLS", "video_id", videoID, "error", err)
+		c.logger.Error("Erro ao atualizar caminhos HLS", "video_id", video.ID, "error", err)
 		// Não falha a conversão por erro na atualização dos caminhos
+		return false
 	}
+	video.Version++
+	return true
 }
 
 // updateVideoStatusToCompleted atualiza o status do vídeo para "completed"
-func (c *VideoConverterService) updateVideoStatusToCompleted(ctx context.Context, videoID string) {
-	err := c.videoRepo.UpdateStatus(ctx, videoID, entity.StatusCompleted, "")
+// Retorna true se o status foi persistido
+func (c *VideoConverterService) updateVideoStatusToCompleted(ctx context.Context, video *entity.Video) bool {
+	err := c.videoRepo.UpdateStatus(ctx, video.ID, video.Version, entity.StatusCompleted, "")
 	if err != nil {
-		c.logger.Error("Erro ao atualizar status do vídeo para completed", "video_id", videoID, "error", err)
+		c.logger.Error("Erro ao atualizar status do vídeo para completed", "video_id", video.ID, "error", err)
 		// Não falha a conversão por erro na atualização do status
+		return false
 	}
+	video.Version++
+	return true
 }
@@ -3,12 +3,15 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	"github.com/devfullcycle/golangtechweek/internal/domain/event"
+	"github.com/devfullcycle/golangtechweek/internal/domain/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -18,8 +21,8 @@ type MockFFmpegService struct {
 	mock.Mock
 }
 
-func (m *MockFFmpegService) ConvertToHLS(ctx context.Context, input string, outputDir string) ([]OutputFile, error) {
-	args := m.Called(ctx, input, outputDir)
+func (m *MockFFmpegService) ConvertToHLS(ctx context.Context, input string, outputDir string, profile EncodingProfile, onProgress ProgressFunc) ([]OutputFile, error) {
+	args := m.Called(ctx, input, outputDir, profile, onProgress)
 	return args.Get(0).([]OutputFile), args.Error(1)
 }
 
@@ -41,41 +44,233 @@ func (m *MockVideoRepository) FindByID(ctx context.Context, id string) (*entity.
 	return args.Get(0).(*entity.Video), args.Error(1)
 }
 
-func (m *MockVideoRepository) List(ctx context.Context, page, pageSize int) ([]*entity.Video, error) {
-	args := m.Called(ctx, page, pageSize)
+func (m *MockVideoRepository) ClaimNextPending(ctx context.Context, workerID string, leaseDuration time.Duration) (*entity.Video, error) {
+	args := m.Called(ctx, workerID, leaseDuration)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Video), args.Error(1)
+}
+
+func (m *MockVideoRepository) RenewLease(ctx context.Context, id, workerID string, leaseDuration time.Duration) error {
+	args := m.Called(ctx, id, workerID, leaseDuration)
+	return args.Error(0)
+}
+
+func (m *MockVideoRepository) ListStaleProcessing(ctx context.Context, staleBefore time.Time, limit int) ([]*entity.Video, error) {
+	args := m.Called(ctx, staleBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).([]*entity.Video), args.Error(1)
 }
 
-func (m *MockVideoRepository) UpdateStatus(ctx context.Context, id string, status string, errorMessage string) error {
-	args := m.Called(ctx, id, status, errorMessage)
+func (m *MockVideoRepository) FindManyByID(ctx context.Context, ids []string) ([]*entity.Video, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Video), args.Error(1)
+}
+
+func (m *MockVideoRepository) CreateMany(ctx context.Context, videos []*entity.Video) ([]repository.BulkResult, error) {
+	args := m.Called(ctx, videos)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.BulkResult), args.Error(1)
+}
+
+func (m *MockVideoRepository) UpdateStatusMany(ctx context.Context, ids []string, status string) ([]repository.BulkResult, error) {
+	args := m.Called(ctx, ids, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.BulkResult), args.Error(1)
+}
+
+func (m *MockVideoRepository) RequeueFailed(ctx context.Context, filter repository.RequeueFailedFilter) ([]repository.BulkResult, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.BulkResult), args.Error(1)
+}
+
+func (m *MockVideoRepository) ReleaseLease(ctx context.Context, id, workerID string) error {
+	args := m.Called(ctx, id, workerID)
 	return args.Error(0)
 }
 
-func (m *MockVideoRepository) UpdateHLSPath(ctx context.Context, id string, hlsPath, manifestPath string) error {
-	args := m.Called(ctx, id, hlsPath, manifestPath)
+func (m *MockVideoRepository) Update(ctx context.Context, video *entity.Video) error {
+	args := m.Called(ctx, video)
 	return args.Error(0)
 }
 
-func (m *MockVideoRepository) UpdateS3Status(ctx context.Context, id string, uploadStatus string) error {
-	args := m.Called(ctx, id, uploadStatus)
+func (m *MockVideoRepository) ListVideos(ctx context.Context, query repository.ListVideosQuery) (*repository.VideoPage, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.VideoPage), args.Error(1)
+}
+
+func (m *MockVideoRepository) Search(ctx context.Context, query string, filters repository.VideoSearchFilters, cursor string) (*repository.VideoSearchPage, error) {
+	args := m.Called(ctx, query, filters, cursor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.VideoSearchPage), args.Error(1)
+}
+
+func (m *MockVideoRepository) FindCompletedByContentHash(ctx context.Context, ownerID, contentHash string) (*entity.Video, error) {
+	args := m.Called(ctx, ownerID, contentHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Video), args.Error(1)
+}
+
+func (m *MockVideoRepository) List(ctx context.Context, filter repository.VideoFilter, page, pageSize int) ([]*entity.Video, error) {
+	args := m.Called(ctx, filter, page, pageSize)
+	return args.Get(0).([]*entity.Video), args.Error(1)
+}
+
+func (m *MockVideoRepository) UpdateStatus(ctx context.Context, id string, expectedVersion int64, status string, errorMessage string) error {
+	args := m.Called(ctx, id, expectedVersion, status, errorMessage)
+	return args.Error(0)
+}
+
+func (m *MockVideoRepository) UpdateProgress(ctx context.Context, id string, progress int, stage string, estimatedCompletionAt *time.Time) error {
+	args := m.Called(ctx, id, progress, stage, estimatedCompletionAt)
+	return args.Error(0)
+}
+
+func (m *MockVideoRepository) UpdateHLSPath(ctx context.Context, id string, expectedVersion int64, hlsPath, manifestPath string) error {
+	args := m.Called(ctx, id, expectedVersion, hlsPath, manifestPath)
+	return args.Error(0)
+}
+
+func (m *MockVideoRepository) UpdateS3Status(ctx context.Context, id string, expectedVersion int64, uploadStatus string) error {
+	args := m.Called(ctx, id, expectedVersion, uploadStatus)
 	return args.Error(0)
 }
 
-func (m *MockVideoRepository) UpdateS3URLs(ctx context.Context, id string, s3URL, s3ManifestURL string) error {
-	args := m.Called(ctx, id, s3URL, s3ManifestURL)
+func (m *MockVideoRepository) UpdateS3URLs(ctx context.Context, id string, expectedVersion int64, s3URL, s3ManifestURL string) error {
+	args := m.Called(ctx, id, expectedVersion, s3URL, s3ManifestURL)
 	return args.Error(0)
 }
 
-func (m *MockVideoRepository) UpdateS3Keys(ctx context.Context, id string, segmentKey string, manifestKey string) error {
-	args := m.Called(ctx, id, segmentKey, manifestKey)
+func (m *MockVideoRepository) UpdateS3Keys(ctx context.Context, id string, expectedVersion int64, segmentKey string, manifestKey string) error {
+	args := m.Called(ctx, id, expectedVersion, segmentKey, manifestKey)
 	return args.Error(0)
 }
 
-func (m *MockVideoRepository) Delete(ctx context.Context, id string) error {
+func (m *MockVideoRepository) Restore(ctx context.Context, id string) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockVideoRepository) ListPurgeCandidates(ctx context.Context, criteria repository.PurgeCriteria) ([]repository.PurgeCandidate, error) {
+	args := m.Called(ctx, criteria)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.PurgeCandidate), args.Error(1)
+}
+
+func (m *MockVideoRepository) BeginPurge(ctx context.Context, id string, criteria repository.PurgeCriteria) error {
+	args := m.Called(ctx, id, criteria)
+	return args.Error(0)
+}
+
+func (m *MockVideoRepository) Purge(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockVideoRepository) Stats(ctx context.Context, filter repository.VideoStatsFilter) (*repository.VideoStats, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.VideoStats), args.Error(1)
+}
+
+func (m *MockVideoRepository) Delete(ctx context.Context, id string, expectedVersion int64) error {
+	args := m.Called(ctx, id, expectedVersion)
+	return args.Error(0)
+}
+
+// MockProcessingAttemptRepository é um mock para o repositório de tentativas de processamento
+type MockProcessingAttemptRepository struct {
+	mock.Mock
+}
+
+func (m *MockProcessingAttemptRepository) Create(ctx context.Context, attempt *entity.ProcessingAttempt) error {
+	args := m.Called(ctx, attempt)
+	return args.Error(0)
+}
+
+func (m *MockProcessingAttemptRepository) Finish(ctx context.Context, attempt *entity.ProcessingAttempt) error {
+	args := m.Called(ctx, attempt)
+	return args.Error(0)
+}
+
+func (m *MockProcessingAttemptRepository) ListByVideoID(ctx context.Context, videoID string) ([]*entity.ProcessingAttempt, error) {
+	args := m.Called(ctx, videoID)
+	return args.Get(0).([]*entity.ProcessingAttempt), args.Error(1)
+}
+
+// MockVideoFileRepository é um mock do repositório de arquivos gerados
+type MockVideoFileRepository struct {
+	mock.Mock
+}
+
+func (m *MockVideoFileRepository) ReplaceForVideo(ctx context.Context, videoID string, files []*entity.VideoFile) error {
+	args := m.Called(ctx, videoID, files)
+	return args.Error(0)
+}
+
+func (m *MockVideoFileRepository) ListByVideoID(ctx context.Context, videoID string) ([]*entity.VideoFile, error) {
+	args := m.Called(ctx, videoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.VideoFile), args.Error(1)
+}
+
+func (m *MockVideoFileRepository) UpdateUploadStatus(ctx context.Context, file *entity.VideoFile) error {
+	args := m.Called(ctx, file)
+	return args.Error(0)
+}
+
+func (m *MockVideoFileRepository) DeleteByVideoID(ctx context.Context, videoID string) error {
+	args := m.Called(ctx, videoID)
+	return args.Error(0)
+}
+
+// fakeUnitOfWork executa fn diretamente com os repositórios informados e registra o resultado da "transação"
+type fakeUnitOfWork struct {
+	repos   repository.Repositories
+	calls   int
+	lastErr error
+}
+
+func (u *fakeUnitOfWork) RunInTx(ctx context.Context, fn func(ctx context.Context, repos repository.Repositories) error) error {
+	u.calls++
+	u.lastErr = fn(ctx, u.repos)
+	return u.lastErr
+}
+
+// newTestVideo cria um vídeo de teste com o ID informado
+func newTestVideo(id string) *entity.Video {
+	video := entity.NewVideo("owner-123", "Vídeo de Teste", "", "input/path")
+	video.ID = id
+	video.PullEvents()
+	return video
+}
+
 // Substituir o FFmpegService no VideoConverterService para testes
 func replaceFFmpegService(converter *VideoConverterService, ffmpeg FFmpegServiceInterface) {
 	converter.ffmpeg = ffmpeg
@@ -109,20 +304,28 @@ func TestVideoConverterService_StartConversion_Success(t *testing.T) {
 	mockFFmpeg := new(MockFFmpegService)
 	config := DefaultVideoConverterConfig()
 	config.WorkerCount = 1 // Usar apenas 1 worker para simplificar o teste
+	config.WorkerID = "worker-1"
 
 	converter := NewVideoConverter(mockFFmpeg, mockRepo, config)
 
-	// Configurar o mock do repositório para retornar sucesso ao atualizar o status
-	mockRepo.On("UpdateStatus", mock.Anything, "test-video-id", entity.StatusProcessing, "").Return(nil)
-	mockRepo.On("UpdateStatus", mock.Anything, "test-video-id", entity.StatusCompleted, "").Return(nil)
-	mockRepo.On("UpdateHLSPath", mock.Anything, "test-video-id", "output/dir", "output/dir/manifest.m3u8").Return(nil)
+	// Configurar o mock do repositório para retornar o vídeo e sucesso ao atualizar o status
+	mockRepo.On("FindByID", mock.Anything, "test-video-id").Return(newTestVideo("test-video-id"), nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(video *entity.Video) bool {
+		// O vídeo é reivindicado com uma concessão para este worker
+		return video.Status == entity.StatusProcessing && video.LeaseOwner == "worker-1" && video.HasActiveLease(time.Now())
+	})).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.Video).Version++
+	})
+	// Cada gravação confere a versão deixada pela anterior
+	mockRepo.On("UpdateHLSPath", mock.Anything, "test-video-id", int64(2), "output/dir", "output/dir/manifest.m3u8").Return(nil)
+	mockRepo.On("UpdateStatus", mock.Anything, "test-video-id", int64(3), entity.StatusCompleted, "").Return(nil)
 
 	// Configurar o mock do FFmpeg para retornar arquivos de saída simulados
 	outputFiles := []OutputFile{
 		{Path: "output/dir/manifest.m3u8", Type: entity.FileTypeManifest},
 		{Path: "output/dir/segment_0.ts", Type: entity.FileTypeSegment},
 	}
-	mockFFmpeg.On("ConvertToHLS", mock.Anything, "input/path", mock.Anything).Return(outputFiles, nil)
+	mockFFmpeg.On("ConvertToHLS", mock.Anything, "input/path", mock.Anything, mock.Anything, mock.Anything).Return(outputFiles, nil)
 
 	// Criar um canal de entrada com capacidade para evitar bloqueio
 	inputCh := make(chan ConversionJob, 1)
@@ -179,12 +382,13 @@ func TestVideoConverterService_StartConversion_FFmpegError(t *testing.T) {
 	converter := NewVideoConverter(mockFFmpeg, mockRepo, config)
 
 	// Configurar o mock do repositório
-	mockRepo.On("UpdateStatus", mock.Anything, "test-video-id", entity.StatusProcessing, "").Return(nil)
-	mockRepo.On("UpdateStatus", mock.Anything, "test-video-id", entity.StatusError, mock.Anything).Return(nil)
+	mockRepo.On("FindByID", mock.Anything, "test-video-id").Return(newTestVideo("test-video-id"), nil)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("UpdateStatus", mock.Anything, "test-video-id", mock.Anything, entity.StatusError, mock.Anything).Return(nil)
 
 	// Configurar o mock do FFmpeg para retornar erro
 	ffmpegError := errors.New("erro na conversão")
-	mockFFmpeg.On("ConvertToHLS", mock.Anything, "input/path", mock.Anything).Return([]OutputFile{}, ffmpegError)
+	mockFFmpeg.On("ConvertToHLS", mock.Anything, "input/path", mock.Anything, mock.Anything, mock.Anything).Return([]OutputFile{}, ffmpegError)
 
 	// Criar um canal de entrada com capacidade para evitar bloqueio
 	inputCh := make(chan ConversionJob, 1)
@@ -230,46 +434,26 @@ func TestVideoConverterService_StartConversion_FFmpegError(t *testing.T) {
 	}
 }
 
-func TestVideoConverterService_StartConversion_UpdateStatusError(t *testing.T) {
+func TestVideoConverterService_ProcessJob_ClaimError(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockVideoRepository)
 	mockFFmpeg := new(MockFFmpegService)
 	config := DefaultVideoConverterConfig()
-	config.WorkerCount = 1 // Usar apenas 1 worker para simplificar o teste
 
 	converter := NewVideoConverter(mockFFmpeg, mockRepo, config)
 
 	// Configurar o mock do repositório para retornar erro ao atualizar o status
 	updateError := errors.New("erro ao atualizar status")
-	mockRepo.On("UpdateStatus", mock.Anything, "test-video-id", entity.StatusProcessing, "").Return(updateError)
-	mockRepo.On("UpdateStatus", mock.Anything, "test-video-id", entity.StatusError, mock.Anything).Return(nil)
-
-	// Criar um canal de entrada com capacidade para evitar bloqueio
-	inputCh := make(chan ConversionJob, 1)
-
-	// Criar um contexto com timeout para evitar que o teste fique preso
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	mockRepo.On("FindByID", mock.Anything, "test-video-id").Return(newTestVideo("test-video-id"), nil)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(updateError)
+	mockRepo.On("UpdateStatus", mock.Anything, "test-video-id", mock.Anything, entity.StatusError, mock.Anything).Return(nil)
 
 	// Act
-	resultCh, err := converter.StartConversion(ctx, inputCh)
-
-	// Assert - verificar se o serviço iniciou corretamente
-	assert.NoError(t, err)
-	assert.NotNil(t, resultCh)
-
-	// Enviar um job para o canal de entrada
-	inputCh <- ConversionJob{
+	result := converter.processJob(context.Background(), ConversionJob{
 		VideoID:   "test-video-id",
 		InputPath: "input/path",
 		OutputDir: "output/dir",
-	}
-
-	// Fechar o canal de entrada para sinalizar que não há mais jobs
-	close(inputCh)
-
-	// Ler o resultado do canal de saída
-	result := <-resultCh
+	})
 
 	// Verificar o resultado
 	assert.False(t, result.Success)
@@ -279,12 +463,6 @@ func TestVideoConverterService_StartConversion_UpdateStatusError(t *testing.T) {
 
 	// Verificar se os mocks foram chamados conforme esperado
 	mockRepo.AssertExpectations(t)
-
-	// Parar o serviço apenas se ainda estiver em execução
-	if converter.IsRunning() {
-		err = converter.StopConversion()
-		assert.NoError(t, err)
-	}
 }
 
 func TestVideoConverterService_StartConversion_AlreadyRunning(t *testing.T) {
@@ -383,3 +561,518 @@ func TestVideoConverterService_IsRunning(t *testing.T) {
 	// Fechar o canal de entrada
 	close(inputCh)
 }
+
+func TestVideoConverterService_ProcessJob_DispatchesEvents(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	mockFFmpeg := new(MockFFmpegService)
+	dispatcher := event.NewEventDispatcher()
+	config := DefaultVideoConverterConfig()
+	config.EventDispatcher = dispatcher
+
+	converter := NewVideoConverter(mockFFmpeg, mockRepo, config)
+
+	// Registrar um assinante que coleta o nome de todos os eventos recebidos
+	var received []string
+	dispatcher.Subscribe(event.AllEvents, func(ctx context.Context, evt event.Event) error {
+		received = append(received, evt.Name())
+		return nil
+	})
+
+	mockRepo.On("FindByID", mock.Anything, "test-video-id").Return(newTestVideo("test-video-id"), nil)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("UpdateStatus", mock.Anything, "test-video-id", mock.Anything, entity.StatusCompleted, "").Return(nil)
+	mockRepo.On("UpdateHLSPath", mock.Anything, "test-video-id", mock.Anything, "output/dir", "output/dir/manifest.m3u8").Return(nil)
+
+	outputFiles := []OutputFile{
+		{Path: "output/dir/manifest.m3u8", Type: entity.FileTypeManifest},
+		{Path: "output/dir/segment_0.ts", Type: entity.FileTypeSegment},
+	}
+	mockFFmpeg.On("ConvertToHLS", mock.Anything, "input/path", mock.Anything, mock.Anything, mock.Anything).Return(outputFiles, nil)
+
+	// Act
+	result := converter.processJob(context.Background(), ConversionJob{
+		VideoID:   "test-video-id",
+		InputPath: "input/path",
+		OutputDir: "output/dir",
+	})
+
+	// Assert - os eventos devem ser despachados na ordem das mudanças de estado
+	assert.True(t, result.Success)
+	assert.Equal(t, []string{entity.EventVideoProcessingStarted, entity.EventVideoProcessingCompleted}, received)
+}
+
+func TestVideoConverterService_ProcessJob_HLSPathErrorSkipsCompletedEvent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	mockFFmpeg := new(MockFFmpegService)
+	dispatcher := event.NewEventDispatcher()
+	config := DefaultVideoConverterConfig()
+	config.EventDispatcher = dispatcher
+
+	converter := NewVideoConverter(mockFFmpeg, mockRepo, config)
+
+	var received []string
+	dispatcher.Subscribe(event.AllEvents, func(ctx context.Context, evt event.Event) error {
+		received = append(received, evt.Name())
+		return nil
+	})
+
+	mockRepo.On("FindByID", mock.Anything, "test-video-id").Return(newTestVideo("test-video-id"), nil)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("UpdateHLSPath", mock.Anything, "test-video-id", mock.Anything, "output/dir", "output/dir/manifest.m3u8").Return(errors.New("erro no banco"))
+	mockRepo.On("UpdateStatus", mock.Anything, "test-video-id", mock.Anything, entity.StatusCompleted, "").Return(nil)
+
+	outputFiles := []OutputFile{
+		{Path: "output/dir/manifest.m3u8", Type: entity.FileTypeManifest},
+		{Path: "output/dir/segment_0.ts", Type: entity.FileTypeSegment},
+	}
+	mockFFmpeg.On("ConvertToHLS", mock.Anything, "input/path", mock.Anything, mock.Anything, mock.Anything).Return(outputFiles, nil)
+
+	// Act
+	converter.processJob(context.Background(), ConversionJob{
+		VideoID:   "test-video-id",
+		InputPath: "input/path",
+		OutputDir: "output/dir",
+	})
+
+	// Assert - sem os caminhos HLS gravados, a conclusão não é anunciada
+	assert.Equal(t, []string{entity.EventVideoProcessingStarted}, received)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestVideoConverterService_ProcessJob_CompletesInTransaction(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	txRepo := new(MockVideoRepository)
+	mockFFmpeg := new(MockFFmpegService)
+	dispatcher := event.NewEventDispatcher()
+	uow := &fakeUnitOfWork{repos: repository.Repositories{Videos: txRepo}}
+	config := DefaultVideoConverterConfig()
+	config.EventDispatcher = dispatcher
+	config.UnitOfWork = uow
+
+	converter := NewVideoConverter(mockFFmpeg, mockRepo, config)
+
+	var received []string
+	dispatcher.Subscribe(event.AllEvents, func(ctx context.Context, evt event.Event) error {
+		received = append(received, evt.Name())
+		return nil
+	})
+
+	mockRepo.On("FindByID", mock.Anything, "test-video-id").Return(newTestVideo("test-video-id"), nil)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	// A conclusão é gravada pelos repositórios da transação, e a falha do status desfaz os caminhos HLS
+	txRepo.On("UpdateHLSPath", mock.Anything, "test-video-id", mock.Anything, "output/dir", "output/dir/manifest.m3u8").Return(nil)
+	txRepo.On("UpdateStatus", mock.Anything, "test-video-id", mock.Anything, entity.StatusCompleted, "").Return(errors.New("erro no banco"))
+
+	outputFiles := []OutputFile{
+		{Path: "output/dir/manifest.m3u8", Type: entity.FileTypeManifest},
+		{Path: "output/dir/segment_0.ts", Type: entity.FileTypeSegment},
+	}
+	mockFFmpeg.On("ConvertToHLS", mock.Anything, "input/path", mock.Anything, mock.Anything, mock.Anything).Return(outputFiles, nil)
+
+	// Act
+	result := converter.processJob(context.Background(), ConversionJob{
+		VideoID:   "test-video-id",
+		InputPath: "input/path",
+		OutputDir: "output/dir",
+	})
+
+	// Assert - a transação foi desfeita e o evento de conclusão não é despachado
+	assert.True(t, result.Success)
+	assert.Equal(t, 1, uow.calls)
+	assert.Error(t, uow.lastErr)
+	assert.Equal(t, []string{entity.EventVideoProcessingStarted}, received)
+	mockRepo.AssertNotCalled(t, "UpdateHLSPath", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	txRepo.AssertExpectations(t)
+}
+
+func TestVideoConverterService_ProcessJob_RecordsOutputFiles(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	fileRepo := new(MockVideoFileRepository)
+	mockFFmpeg := new(MockFFmpegService)
+	config := DefaultVideoConverterConfig()
+	config.FileRepository = fileRepo
+
+	converter := NewVideoConverter(mockFFmpeg, mockRepo, config)
+
+	outputFiles := writeHLSOutput(t)
+
+	mockRepo.On("FindByID", mock.Anything, "test-video-id").Return(newTestVideo("test-video-id"), nil)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("UpdateStatus", mock.Anything, "test-video-id", mock.Anything, mock.Anything, "").Return(nil)
+	mockRepo.On("UpdateHLSPath", mock.Anything, "test-video-id", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	fileRepo.On("ReplaceForVideo", mock.Anything, "test-video-id", mock.Anything).Return(nil)
+	mockFFmpeg.On("ConvertToHLS", mock.Anything, "input/path", mock.Anything, mock.Anything, mock.Anything).Return(outputFiles, nil)
+
+	// Act
+	result := converter.processJob(context.Background(), ConversionJob{VideoID: "test-video-id", InputPath: "input/path", OutputDir: "output/dir"})
+
+	// Assert - todos os arquivos gerados são registrados
+	assert.True(t, result.Success)
+	fileRepo.AssertNumberOfCalls(t, "ReplaceForVideo", 1)
+
+	files := fileRepo.Calls[0].Arguments.Get(2).([]*entity.VideoFile)
+	assert.Len(t, files, len(outputFiles))
+	for _, file := range files {
+		assert.Equal(t, "test-video-id", file.VideoID)
+		assert.Equal(t, entity.FileUploadPending, file.UploadStatus)
+		assert.Len(t, file.Checksum, 64)
+	}
+}
+
+func TestVideoConverterService_ProcessJob_FailureDispatchesFailedEvent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	mockFFmpeg := new(MockFFmpegService)
+	dispatcher := event.NewEventDispatcher()
+	config := DefaultVideoConverterConfig()
+	config.EventDispatcher = dispatcher
+
+	converter := NewVideoConverter(mockFFmpeg, mockRepo, config)
+
+	var failed []event.Event
+	dispatcher.Subscribe(entity.EventVideoProcessingFailed, func(ctx context.Context, evt event.Event) error {
+		failed = append(failed, evt)
+		return nil
+	})
+
+	mockRepo.On("FindByID", mock.Anything, "test-video-id").Return(newTestVideo("test-video-id"), nil)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("UpdateStatus", mock.Anything, "test-video-id", mock.Anything, entity.StatusError, mock.Anything).Return(nil)
+	mockFFmpeg.On("ConvertToHLS", mock.Anything, "input/path", mock.Anything, mock.Anything, mock.Anything).Return([]OutputFile{}, errors.New("erro na conversão"))
+
+	// Act
+	result := converter.processJob(context.Background(), ConversionJob{
+		VideoID:   "test-video-id",
+		InputPath: "input/path",
+		OutputDir: "output/dir",
+	})
+
+	// Assert
+	assert.False(t, result.Success)
+	if assert.Len(t, failed, 1) {
+		failedEvent, ok := failed[0].(entity.VideoProcessingFailed)
+		assert.True(t, ok)
+		assert.Equal(t, "test-video-id", failedEvent.AggregateID())
+		assert.Contains(t, failedEvent.ErrorMessage, "erro na conversão")
+	}
+}
+
+func TestVideoConverterService_ProcessJob_RecordsFailedAttempt(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	mockFFmpeg := new(MockFFmpegService)
+	mockAttempts := new(MockProcessingAttemptRepository)
+	config := DefaultVideoConverterConfig()
+	config.AttemptRepository = mockAttempts
+	config.WorkerID = "worker-test"
+
+	converter := NewVideoConverter(mockFFmpeg, mockRepo, config)
+
+	mockRepo.On("FindByID", mock.Anything, "test-video-id").Return(newTestVideo("test-video-id"), nil)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("UpdateStatus", mock.Anything, "test-video-id", mock.Anything, entity.StatusError, mock.Anything).Return(nil)
+
+	// O FFmpeg falha e devolve o trecho do stderr junto com o erro
+	ffmpegError := &FFmpegError{Err: errors.New("exit status 1"), Stderr: "moov atom not found"}
+	mockFFmpeg.On("ConvertToHLS", mock.Anything, "input/path", mock.Anything, mock.Anything, mock.Anything).Return([]OutputFile{}, ffmpegError)
+
+	var finished *entity.ProcessingAttempt
+	mockAttempts.On("Create", mock.Anything, mock.AnythingOfType("*entity.ProcessingAttempt")).Return(nil)
+	mockAttempts.On("Finish", mock.Anything, mock.AnythingOfType("*entity.ProcessingAttempt")).
+		Run(func(args mock.Arguments) {
+			finished = args.Get(1).(*entity.ProcessingAttempt)
+		}).
+		Return(nil)
+
+	// Act
+	result := converter.processJob(context.Background(), ConversionJob{
+		VideoID:   "test-video-id",
+		InputPath: "input/path",
+		OutputDir: "output/dir",
+	})
+
+	// Assert
+	assert.False(t, result.Success)
+	mockAttempts.AssertExpectations(t)
+	if assert.NotNil(t, finished) {
+		assert.Equal(t, "test-video-id", finished.VideoID)
+		assert.Equal(t, "worker-test", finished.WorkerID)
+		assert.Equal(t, entity.AttemptOutcomeFailed, finished.Outcome)
+		assert.Equal(t, "moov atom not found", finished.StderrExcerpt)
+		assert.Equal(t, "h264", finished.EncodingParams["c:v"])
+		assert.True(t, finished.IsFinished())
+	}
+}
+
+func TestVideoConverterService_ProcessJob_CanceledRequeuesVideo(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	mockFFmpeg := new(MockFFmpegService)
+	converter := NewVideoConverter(mockFFmpeg, mockRepo, DefaultVideoConverterConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Registra o status gravado em cada Update e se o contexto usado já estava cancelado
+	var statuses []string
+	var ctxErrs []error
+	mockRepo.On("FindByID", mock.Anything, "test-video-id").Return(newTestVideo("test-video-id"), nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*entity.Video")).
+		Run(func(args mock.Arguments) {
+			video := args.Get(1).(*entity.Video)
+			statuses = append(statuses, video.Status)
+			ctxErrs = append(ctxErrs, args.Get(0).(context.Context).Err())
+			video.Version++
+		}).
+		Return(nil)
+
+	// O job é cancelado durante a conversão
+	mockFFmpeg.On("ConvertToHLS", mock.Anything, "input/path", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { cancel() }).
+		Return([]OutputFile{}, fmt.Errorf("%w: %w", ErrConversionCanceled, context.Canceled))
+
+	// Act
+	result := converter.processJob(ctx, ConversionJob{
+		VideoID:   "test-video-id",
+		InputPath: "input/path",
+		OutputDir: "output/dir",
+	})
+
+	// Assert
+	assert.False(t, result.Success)
+	assert.ErrorIs(t, result.Error, ErrConversionCanceled)
+	// O vídeo volta para a fila, gravado com um contexto que não foi cancelado, e não é marcado como falho
+	assert.Equal(t, []string{entity.StatusProcessing, entity.StatusPending}, statuses)
+	assert.Equal(t, []error{nil, nil}, ctxErrs)
+	mockRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, entity.StatusError, mock.Anything)
+	mockFFmpeg.AssertExpectations(t)
+}
+
+func TestVideoConverterService_ProcessJob_UsesJobEncodingProfile(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	mockFFmpeg := new(MockFFmpegService)
+	mockAttempts := new(MockProcessingAttemptRepository)
+	config := DefaultVideoConverterConfig()
+	config.AttemptRepository = mockAttempts
+
+	converter := NewVideoConverter(mockFFmpeg, mockRepo, config)
+
+	mockRepo.On("FindByID", mock.Anything, "test-video-id").Return(newTestVideo("test-video-id"), nil)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("UpdateStatus", mock.Anything, "test-video-id", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	// O perfil do job chega ao FFmpeg completado pelo perfil padrão
+	var used EncodingProfile
+	mockFFmpeg.On("ConvertToHLS", mock.Anything, "input/path", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			used = args.Get(3).(EncodingProfile)
+		}).
+		Return([]OutputFile{}, errors.New("erro na conversão"))
+
+	var created *entity.ProcessingAttempt
+	mockAttempts.On("Create", mock.Anything, mock.AnythingOfType("*entity.ProcessingAttempt")).
+		Run(func(args mock.Arguments) {
+			created = args.Get(1).(*entity.ProcessingAttempt)
+		}).
+		Return(nil)
+	mockAttempts.On("Finish", mock.Anything, mock.Anything).Return(nil)
+
+	// Act
+	converter.processJob(context.Background(), ConversionJob{
+		VideoID:   "test-video-id",
+		InputPath: "input/path",
+		OutputDir: "output/dir",
+		Profile:   &EncodingProfile{Name: "fast", Preset: "veryfast", CRF: 23},
+	})
+
+	// Assert
+	assert.Equal(t, "fast", used.Name)
+	assert.Equal(t, "veryfast", used.Preset)
+	assert.Equal(t, 23, used.CRF)
+	assert.Equal(t, 10, used.SegmentDuration)
+	if assert.NotNil(t, created) {
+		assert.Equal(t, "fast", created.EncodingParams["encoding_profile"])
+		assert.Equal(t, "23", created.EncodingParams["crf"])
+	}
+}
+
+func TestVideoConverterService_UpdateUploadStatus_DispatchesUploadCompleted(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	dispatcher := event.NewEventDispatcher()
+	config := DefaultVideoConverterConfig()
+	config.EventDispatcher = dispatcher
+
+	converter := NewVideoConverter(new(MockFFmpegService), mockRepo, config)
+
+	var received []string
+	dispatcher.Subscribe(event.AllEvents, func(ctx context.Context, evt event.Event) error {
+		received = append(received, evt.Name())
+		return nil
+	})
+
+	video := newTestVideo("test-video-id")
+	mockRepo.On("FindByID", mock.Anything, "test-video-id").Return(video, nil)
+	mockRepo.On("UpdateS3Status", mock.Anything, "test-video-id", video.Version, entity.UploadStatusCompletedS3).Return(nil)
+
+	// Act
+	err := converter.UpdateUploadStatus(context.Background(), "test-video-id", entity.UploadStatusCompletedS3)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{entity.EventVideoUploadCompleted}, received)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestVideoConverterService_UpdateUploadStatus_SaveErrorSkipsEvent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	dispatcher := event.NewEventDispatcher()
+	config := DefaultVideoConverterConfig()
+	config.EventDispatcher = dispatcher
+
+	converter := NewVideoConverter(new(MockFFmpegService), mockRepo, config)
+
+	var received []string
+	dispatcher.Subscribe(event.AllEvents, func(ctx context.Context, evt event.Event) error {
+		received = append(received, evt.Name())
+		return nil
+	})
+
+	video := newTestVideo("test-video-id")
+	mockRepo.On("FindByID", mock.Anything, "test-video-id").Return(video, nil)
+	mockRepo.On("UpdateS3Status", mock.Anything, "test-video-id", mock.Anything, entity.UploadStatusCompletedS3).Return(repository.ErrConcurrentModification)
+
+	// Act
+	err := converter.UpdateUploadStatus(context.Background(), "test-video-id", entity.UploadStatusCompletedS3)
+
+	// Assert - sem o status gravado, a conclusão do upload não é anunciada
+	assert.ErrorIs(t, err, repository.ErrConcurrentModification)
+	assert.Empty(t, received)
+	assert.Empty(t, video.Events())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestVideoConverterService_ProgressReporter_Throttles(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	mockFFmpeg := new(MockFFmpegService)
+	config := DefaultVideoConverterConfig()
+	config.ProgressInterval = time.Hour // Apenas a primeira atualização e a final devem ser gravadas
+
+	converter := NewVideoConverter(mockFFmpeg, mockRepo, config)
+	video := newTestVideo("test-video-id")
+	video.MarkAsProcessing()
+
+	mockRepo.On("UpdateProgress", mock.Anything, "test-video-id", 10, entity.ProcessingStageTranscoding, mock.Anything).Return(nil).Once()
+	mockRepo.On("UpdateProgress", mock.Anything, "test-video-id", 100, entity.ProcessingStageFinalizing, mock.Anything).Return(nil).Once()
+
+	report := converter.newProgressReporter(context.Background(), video)
+
+	// Act
+	report(ConversionProgress{Stage: entity.ProcessingStageTranscoding, Percent: 10.4})
+	report(ConversionProgress{Stage: entity.ProcessingStageTranscoding, Percent: 35})
+	report(ConversionProgress{Stage: entity.ProcessingStageTranscoding, Percent: 80})
+	report(ConversionProgress{Stage: entity.ProcessingStageTranscoding, Percent: 100, Done: true})
+
+	// Assert
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNumberOfCalls(t, "UpdateProgress", 2)
+	assert.Equal(t, 100, video.Progress)
+	assert.Nil(t, video.EstimatedCompletionAt)
+}
+
+func TestVideoConverterService_ProgressReporter_RenewsLease(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	mockFFmpeg := new(MockFFmpegService)
+	config := DefaultVideoConverterConfig()
+	config.WorkerID = "worker-1"
+	config.ProgressInterval = time.Hour // O percentual não é gravado novamente, mas a concessão é renovada
+	config.LeaseDuration = 30 * time.Millisecond
+
+	converter := NewVideoConverter(mockFFmpeg, mockRepo, config)
+	video := newTestVideo("test-video-id")
+	video.MarkAsProcessing()
+
+	mockRepo.On("UpdateProgress", mock.Anything, "test-video-id", 10, entity.ProcessingStageTranscoding, mock.Anything).Return(nil).Once()
+	mockRepo.On("RenewLease", mock.Anything, "test-video-id", "worker-1", 30*time.Millisecond).Return(nil).Once()
+
+	report := converter.newProgressReporter(context.Background(), video)
+
+	// Act
+	report(ConversionProgress{Stage: entity.ProcessingStageTranscoding, Percent: 10})
+	time.Sleep(15 * time.Millisecond)
+	report(ConversionProgress{Stage: entity.ProcessingStageTranscoding, Percent: 10})
+
+	// Assert
+	mockRepo.AssertExpectations(t)
+	assert.True(t, video.HasActiveLease(time.Now()))
+}
+
+func TestVideoConverterService_ProcessJob_SkipsVideoLeasedByOtherWorker(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	mockFFmpeg := new(MockFFmpegService)
+	config := DefaultVideoConverterConfig()
+	config.WorkerID = "worker-1"
+
+	converter := NewVideoConverter(mockFFmpeg, mockRepo, config)
+
+	video := newTestVideo("test-video-id")
+	video.MarkAsProcessing()
+	video.PullEvents()
+	video.AcquireLease("worker-2", time.Now().Add(time.Minute))
+	mockRepo.On("FindByID", mock.Anything, "test-video-id").Return(video, nil)
+
+	// Act
+	result := converter.processJob(context.Background(), ConversionJob{VideoID: "test-video-id", InputPath: "input/path", OutputDir: "output/dir"})
+
+	// Assert - o vídeo não é convertido nem marcado como falho
+	assert.False(t, result.Success)
+	assert.ErrorIs(t, result.Error, repository.ErrLeaseNotHeld)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockFFmpeg.AssertNotCalled(t, "ConvertToHLS", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestFindManifestAndHLSPaths_PrefersMasterPlaylist(t *testing.T) {
+	converter := &VideoConverterService{}
+
+	manifestPath, hlsPath := converter.findManifestAndHLSPaths([]OutputFile{
+		{Path: "/hls/video-1/playlist_720p.m3u8", Type: entity.FileTypeManifest},
+		{Path: "/hls/video-1/playlist_720p_0.ts", Type: entity.FileTypeSegment},
+		{Path: "/hls/video-1/master.m3u8", Type: entity.FileTypeManifest},
+	})
+
+	assert.Equal(t, "/hls/video-1/master.m3u8", manifestPath)
+	assert.Equal(t, "/hls/video-1", hlsPath)
+
+	// Sem playlist principal, vale o primeiro manifesto
+	manifestPath, _ = converter.findManifestAndHLSPaths([]OutputFile{
+		{Path: "/hls/video-2/playlist.m3u8", Type: entity.FileTypeManifest},
+		{Path: "/hls/video-2/playlist0.ts", Type: entity.FileTypeSegment},
+	})
+
+	assert.Equal(t, "/hls/video-2/playlist.m3u8", manifestPath)
+}
+
+func TestEstimateCompletion(t *testing.T) {
+	startedAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	now := startedAt.Add(10 * time.Minute)
+
+	// 25% em 10 minutos: faltam 30 minutos
+	eta := estimateCompletion(startedAt, now, 25)
+	if assert.NotNil(t, eta) {
+		assert.Equal(t, now.Add(30*time.Minute), *eta)
+	}
+
+	// Sem progresso não há estimativa
+	assert.Nil(t, estimateCompletion(startedAt, now, 0))
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+)
+
+// manifestSegment é a posição e a duração de um segmento segundo o manifesto
+type manifestSegment struct {
+	sequence int
+	duration time.Duration
+}
+
+// parseManifestSegments lê as entradas #EXTINF do manifesto HLS, indexadas pelo nome do arquivo do segmento
+func parseManifestSegments(manifestPath string) (map[string]manifestSegment, error) {
+	file, err := os.Open(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir manifesto: %w", err)
+	}
+	defer file.Close()
+
+	segments := make(map[string]manifestSegment)
+	var pending *time.Duration
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case strings.HasPrefix(line, "#EXTINF:"):
+			// Formato: #EXTINF:<duração em segundos>,[título]
+			value, _, _ := strings.Cut(strings.TrimPrefix(line, "#EXTINF:"), ",")
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("duração inválida no manifesto %q: %w", line, err)
+			}
+			duration := time.Duration(seconds * float64(time.Second))
+			pending = &duration
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		default:
+			// A primeira linha que não é tag depois de #EXTINF é o URI do segmento
+			if pending != nil {
+				segments[filepath.Base(line)] = manifestSegment{sequence: len(segments), duration: *pending}
+				pending = nil
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler manifesto: %w", err)
+	}
+
+	return segments, nil
+}
+
+// describeOutputFiles cria o registro de cada arquivo gerado, com tamanho, checksum e,
+// para os segmentos, a posição e a duração informadas na playlist da sua variante
+// Segmentos ausentes das playlists ficam no fim, na ordem dos nomes
+func describeOutputFiles(videoID string, outputFiles []OutputFile) ([]*entity.VideoFile, error) {
+	// A playlist principal não tem segmentos; os de cada variante estão na playlist da variante
+	segments := map[string]manifestSegment{}
+	nextSequence := 0
+	for _, output := range outputFiles {
+		if output.Type != entity.FileTypeManifest {
+			continue
+		}
+
+		parsed, err := parseManifestSegments(output.Path)
+		if err != nil {
+			return nil, err
+		}
+		for name, segment := range parsed {
+			segments[name] = segment
+			nextSequence = max(nextSequence, segment.sequence+1)
+		}
+	}
+
+	sorted := append([]OutputFile(nil), outputFiles...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Path < sorted[j].Path
+	})
+
+	files := make([]*entity.VideoFile, 0, len(sorted))
+
+	for _, output := range sorted {
+		info, err := os.Stat(output.Path)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler arquivo gerado: %w", err)
+		}
+
+		checksum, err := FingerprintFile(output.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		var sequence int
+		var duration time.Duration
+		if output.Type == entity.FileTypeSegment {
+			segment, ok := segments[filepath.Base(output.Path)]
+			if !ok {
+				segment = manifestSegment{sequence: nextSequence}
+				nextSequence++
+			}
+			sequence, duration = segment.sequence, segment.duration
+		}
+
+		files = append(files, entity.NewVideoFile(videoID, output.Type, output.Path, sequence, info.Size(), duration, checksum))
+	}
+
+	return files, nil
+}
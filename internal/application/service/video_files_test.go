@@ -0,0 +1,116 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeHLSOutput grava um manifesto com dois segmentos em um diretório temporário
+// e retorna os arquivos como o FFmpegService os retornaria
+func writeHLSOutput(t *testing.T) []OutputFile {
+	t.Helper()
+
+	dir := t.TempDir()
+	manifest := "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:10\n" +
+		"#EXTINF:10.010000,\nplaylist0.ts\n" +
+		"#EXTINF:4.500000,\nplaylist1.ts\n" +
+		"#EXT-X-ENDLIST\n"
+
+	files := map[string]string{
+		"playlist.m3u8": manifest,
+		"playlist0.ts":  "segmento 0",
+		"playlist1.ts":  "segmento 1, mais longo",
+	}
+
+	var outputFiles []OutputFile
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+		fileType := entity.FileTypeSegment
+		if filepath.Ext(name) == ".m3u8" {
+			fileType = entity.FileTypeManifest
+		}
+		outputFiles = append(outputFiles, OutputFile{Path: path, Type: fileType})
+	}
+
+	return outputFiles
+}
+
+func TestDescribeOutputFiles(t *testing.T) {
+	outputFiles := writeHLSOutput(t)
+
+	files, err := describeOutputFiles("video-123", outputFiles)
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+
+	byName := map[string]*entity.VideoFile{}
+	for _, file := range files {
+		byName[filepath.Base(file.LocalPath)] = file
+	}
+
+	manifest := byName["playlist.m3u8"]
+	assert.Equal(t, entity.FileTypeManifest, manifest.Type)
+	assert.Zero(t, manifest.Duration)
+
+	first, second := byName["playlist0.ts"], byName["playlist1.ts"]
+	assert.Equal(t, 0, first.Sequence)
+	assert.Equal(t, 10010*time.Millisecond, first.Duration)
+	assert.Equal(t, 1, second.Sequence)
+	assert.Equal(t, 4500*time.Millisecond, second.Duration)
+	assert.Equal(t, int64(len("segmento 1, mais longo")), second.SizeBytes)
+	assert.NotEqual(t, first.Checksum, second.Checksum)
+}
+
+func TestDescribeOutputFilesMissingFile(t *testing.T) {
+	_, err := describeOutputFiles("video-123", []OutputFile{
+		{Path: filepath.Join(t.TempDir(), "playlist0.ts"), Type: entity.FileTypeSegment},
+	})
+	assert.Error(t, err)
+}
+
+func TestDescribeOutputFilesVariants(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		masterPlaylistName:   "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1000\nplaylist_720p.m3u8\n",
+		"playlist_720p.m3u8": "#EXTM3U\n#EXTINF:10.000000,\nplaylist_720p_0.ts\n#EXTINF:2.000000,\nplaylist_720p_1.ts\n",
+		"playlist_360p.m3u8": "#EXTM3U\n#EXTINF:10.000000,\nplaylist_360p_0.ts\n",
+		"playlist_720p_0.ts": "720p 0",
+		"playlist_720p_1.ts": "720p 1",
+		"playlist_360p_0.ts": "360p 0",
+	}
+
+	var outputFiles []OutputFile
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+		fileType := entity.FileTypeSegment
+		if filepath.Ext(name) == ".m3u8" {
+			fileType = entity.FileTypeManifest
+		}
+		outputFiles = append(outputFiles, OutputFile{Path: path, Type: fileType})
+	}
+
+	described, err := describeOutputFiles("video-123", outputFiles)
+	require.NoError(t, err)
+	require.Len(t, described, len(files))
+
+	byName := map[string]*entity.VideoFile{}
+	for _, file := range described {
+		byName[filepath.Base(file.LocalPath)] = file
+	}
+
+	// Cada segmento tem a posição e a duração da playlist da sua variante
+	assert.Equal(t, 0, byName["playlist_720p_0.ts"].Sequence)
+	assert.Equal(t, 1, byName["playlist_720p_1.ts"].Sequence)
+	assert.Equal(t, 2*time.Second, byName["playlist_720p_1.ts"].Duration)
+	assert.Equal(t, 0, byName["playlist_360p_0.ts"].Sequence)
+	assert.Equal(t, 10*time.Second, byName["playlist_360p_0.ts"].Duration)
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/repository"
+)
+
+const (
+	defaultPurgeInterval         = time.Hour
+	defaultPurgeDeletedRetention = 30 * 24 * time.Hour
+	defaultPurgeFailedRetention  = 7 * 24 * time.Hour
+	defaultPurgeBatchSize        = 100
+)
+
+// purgerActor identifica a remoção definitiva no histórico de auditoria
+var purgerActor = repository.Actor{Type: repository.ActorTypeSystem, ID: "purger"}
+
+// errRemoteStorageNotConfigured indica que o vídeo tem arquivos no armazenamento remoto, mas não há como removê-los
+var errRemoteStorageNotConfigured = errors.New("armazenamento remoto não configurado")
+
+// RemoteStorage remove objetos do armazenamento remoto (S3)
+type RemoteStorage interface {
+	// DeleteObjects remove os objetos das chaves informadas; chaves inexistentes não são erro
+	DeleteObjects(ctx context.Context, keys []string) error
+}
+
+// PurgeProgress é o andamento de uma varredura, informado após cada vídeo
+type PurgeProgress struct {
+	Total      int    // Candidatos da varredura
+	Processed  int    // Candidatos já tratados, removidos ou não
+	Purged     int    // Vídeos removidos definitivamente
+	Failed     int    // Vídeos cuja remoção falhou e será tentada de novo na próxima varredura
+	FreedBytes int64  // Tamanho dos arquivos registrados dos vídeos removidos
+	VideoID    string // Último vídeo tratado
+}
+
+// VideoPurgerConfig contém as configurações da remoção definitiva de vídeos
+// Retenções zeradas usam os padrões; retenções negativas desativam o critério correspondente
+type VideoPurgerConfig struct {
+	Interval         time.Duration       // Intervalo entre as varreduras
+	DeletedRetention time.Duration       // Tempo que um vídeo excluído pode ser restaurado antes de ser removido
+	FailedRetention  time.Duration       // Tempo que um vídeo com falha é mantido antes de ser removido
+	BatchSize        int                 // Máximo de vídeos tratados por varredura
+	Storage          RemoteStorage       // Armazenamento dos arquivos enviados (opcional se nenhum vídeo foi enviado)
+	Lock             Locker              // Garante uma única instância da remoção no cluster (opcional)
+	OnProgress       func(PurgeProgress) // Recebe o andamento após cada vídeo (opcional)
+	Logger           *slog.Logger
+}
+
+// DefaultVideoPurgerConfig retorna uma configuração padrão para a remoção definitiva
+func DefaultVideoPurgerConfig() VideoPurgerConfig {
+	return VideoPurgerConfig{
+		Interval:         defaultPurgeInterval,
+		DeletedRetention: defaultPurgeDeletedRetention,
+		FailedRetention:  defaultPurgeFailedRetention,
+		BatchSize:        defaultPurgeBatchSize,
+		Logger: slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			Level: slog.LevelInfo,
+		})),
+	}
+}
+
+// PurgeResult resume o que uma varredura fez
+type PurgeResult struct {
+	Skipped    bool     // Outra instância detinha o lock e a varredura não foi executada
+	Purged     []string // IDs dos vídeos removidos definitivamente
+	Failed     []string // IDs dos vídeos cuja remoção falhou
+	FreedBytes int64    // Tamanho dos arquivos registrados dos vídeos removidos
+}
+
+// VideoPurger remove definitivamente os vídeos excluídos ou com falha há mais tempo que a retenção
+// Cada vídeo é retirado de uso antes de qualquer arquivo ser apagado, e a linha é apagada por último:
+// objetos remotos, diretório HLS local e, por fim, o vídeo e seus registros; se uma etapa falhar,
+// o vídeo continua registrado e a remoção é retomada na próxima varredura
+// A saída HLS das duplicatas pertence ao original, que só é removido depois de todas elas
+type VideoPurger struct {
+	videoRepo        repository.VideoRepository
+	fileRepo         repository.VideoFileRepository
+	storage          RemoteStorage
+	lock             Locker
+	onProgress       func(PurgeProgress)
+	interval         time.Duration
+	deletedRetention time.Duration
+	failedRetention  time.Duration
+	batchSize        int
+	logger           *slog.Logger
+}
+
+// NewVideoPurger cria a remoção definitiva, que encontra os arquivos de cada vídeo em fileRepo (opcional)
+// e, se não houver arquivos com chaves do S3, nas chaves gravadas no próprio vídeo
+func NewVideoPurger(videoRepo repository.VideoRepository, fileRepo repository.VideoFileRepository, config VideoPurgerConfig) *VideoPurger {
+	if config.Interval <= 0 {
+		config.Interval = defaultPurgeInterval
+	}
+
+	if config.DeletedRetention == 0 {
+		config.DeletedRetention = defaultPurgeDeletedRetention
+	}
+
+	if config.FailedRetention == 0 {
+		config.FailedRetention = defaultPurgeFailedRetention
+	}
+
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultPurgeBatchSize
+	}
+
+	if config.Logger == nil {
+		config.Logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			Level: slog.LevelInfo,
+		}))
+	}
+
+	return &VideoPurger{
+		videoRepo:        videoRepo,
+		fileRepo:         fileRepo,
+		storage:          config.Storage,
+		lock:             config.Lock,
+		onProgress:       config.OnProgress,
+		interval:         config.Interval,
+		deletedRetention: config.DeletedRetention,
+		failedRetention:  config.FailedRetention,
+		batchSize:        config.BatchSize,
+		logger:           config.Logger,
+	}
+}
+
+// Run executa uma varredura imediatamente e depois a cada Interval, até o contexto ser cancelado
+func (p *VideoPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.PurgeOnce(ctx); err != nil && ctx.Err() == nil {
+			p.logger.Error("Erro na remoção definitiva de vídeos", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeOnce executa uma varredura, se esta instância conseguir o lock
+// Falhas em um vídeo são registradas no log e não interrompem a varredura dos demais
+func (p *VideoPurger) PurgeOnce(ctx context.Context) (*PurgeResult, error) {
+	ctx = repository.WithActor(ctx, purgerActor)
+	result := &PurgeResult{}
+
+	if p.lock == nil {
+		return result, p.purge(ctx, result)
+	}
+
+	acquired, err := p.lock.TryRun(ctx, func(ctx context.Context) error {
+		return p.purge(ctx, result)
+	})
+	if !acquired && err == nil {
+		p.logger.Debug("Remoção definitiva de vídeos executada por outra instância")
+		result.Skipped = true
+	}
+
+	return result, err
+}
+
+// criteria retorna os critérios de remoção a partir das retenções configuradas
+func (p *VideoPurger) criteria(now time.Time) repository.PurgeCriteria {
+	criteria := repository.PurgeCriteria{Limit: p.batchSize}
+	if p.deletedRetention > 0 {
+		criteria.DeletedBefore = now.Add(-p.deletedRetention)
+	}
+	if p.failedRetention > 0 {
+		criteria.FailedBefore = now.Add(-p.failedRetention)
+	}
+	return criteria
+}
+
+// purge trata um lote de candidatos à remoção
+func (p *VideoPurger) purge(ctx context.Context, result *PurgeResult) error {
+	criteria := p.criteria(time.Now())
+
+	candidates, err := p.videoRepo.ListPurgeCandidates(ctx, criteria)
+	if err != nil {
+		return fmt.Errorf("erro ao listar vídeos para remoção definitiva: %w", err)
+	}
+
+	progress := PurgeProgress{Total: len(candidates)}
+
+	for _, candidate := range candidates {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		video := candidate.Video
+		freed, err := p.purgeVideo(ctx, candidate, criteria)
+
+		switch {
+		case errors.Is(err, repository.ErrVideoNotPurgeable):
+			p.logger.Info("Vídeo deixou de ser candidato à remoção definitiva", "video_id", video.ID)
+		case err != nil:
+			p.logger.Error("Erro na remoção definitiva do vídeo", "video_id", video.ID, "error", err)
+			result.Failed = append(result.Failed, video.ID)
+			progress.Failed++
+		default:
+			p.logger.Info("Vídeo removido definitivamente",
+				"video_id", video.ID, "reason", candidate.Reason, "freed_bytes", freed)
+			result.Purged = append(result.Purged, video.ID)
+			result.FreedBytes += freed
+			progress.Purged++
+			progress.FreedBytes += freed
+		}
+
+		progress.Processed++
+		progress.VideoID = video.ID
+		if p.onProgress != nil {
+			p.onProgress(progress)
+		}
+	}
+
+	return nil
+}
+
+// purgeVideo remove os arquivos e o registro do vídeo, nessa ordem, retornando os bytes liberados
+func (p *VideoPurger) purgeVideo(ctx context.Context, candidate repository.PurgeCandidate, criteria repository.PurgeCriteria) (int64, error) {
+	video := candidate.Video
+
+	// A partir daqui o vídeo não pode ser restaurado nem usado, então seus arquivos podem ser apagados
+	if err := p.videoRepo.BeginPurge(ctx, video.ID, criteria); err != nil {
+		return 0, err
+	}
+
+	var freed int64
+
+	// A saída HLS de uma duplicata é a do original, que continua em uso
+	if !video.IsDuplicate() {
+		var err error
+		if freed, err = p.removeRemoteFiles(ctx, candidate); err != nil {
+			return 0, err
+		}
+
+		if err := p.removeHLSDirectory(video.ID, video.GetHLSDirectory()); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := p.videoRepo.Purge(ctx, video.ID); err != nil {
+		return 0, err
+	}
+
+	return freed, nil
+}
+
+// removeRemoteFiles remove do armazenamento remoto os arquivos enviados do vídeo, retornando o tamanho
+// de todos os arquivos registrados
+// Sem chaves nos arquivos registrados (o SQLite não tem a tabela video_files), usa as chaves gravadas no vídeo
+func (p *VideoPurger) removeRemoteFiles(ctx context.Context, candidate repository.PurgeCandidate) (int64, error) {
+	var keys []string
+	var size int64
+
+	if p.fileRepo != nil {
+		files, err := p.fileRepo.ListByVideoID(ctx, candidate.Video.ID)
+		if err != nil {
+			return 0, fmt.Errorf("erro ao listar arquivos do vídeo: %w", err)
+		}
+
+		for _, file := range files {
+			size += file.SizeBytes
+			if file.S3Key != "" {
+				keys = append(keys, file.S3Key)
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		keys = candidate.S3Keys
+	}
+
+	if len(keys) == 0 {
+		return size, nil
+	}
+
+	if p.storage == nil {
+		return 0, errRemoteStorageNotConfigured
+	}
+
+	if err := p.storage.DeleteObjects(ctx, keys); err != nil {
+		return 0, fmt.Errorf("erro ao remover arquivos do armazenamento remoto: %w", err)
+	}
+
+	return size, nil
+}
+
+// removeHLSDirectory apaga o diretório HLS local do vídeo
+// Por segurança, apenas diretórios nomeados com o ID do vídeo (veja Video.GenerateOutputPath) são apagados
+func (p *VideoPurger) removeHLSDirectory(videoID, dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	dir = filepath.Clean(dir)
+	if filepath.Base(dir) != videoID {
+		p.logger.Warn("Diretório HLS fora do padrão mantido na remoção definitiva", "video_id", videoID, "hls_path", dir)
+		return nil
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("erro ao remover diretório HLS: %w", err)
+	}
+
+	return nil
+}
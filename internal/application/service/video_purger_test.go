@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	"github.com/devfullcycle/golangtechweek/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRemoteStorage registra as chaves removidas e pode falhar
+type fakeRemoteStorage struct {
+	deleted []string
+	err     error
+}
+
+func (s *fakeRemoteStorage) DeleteObjects(ctx context.Context, keys []string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.deleted = append(s.deleted, keys...)
+	return nil
+}
+
+// newVideoWithOutput cria um vídeo concluído, com a saída HLS em dir/<id>
+func newVideoWithOutput(t *testing.T, id string) *entity.Video {
+	t.Helper()
+
+	hlsPath := filepath.Join(t.TempDir(), id)
+	require.NoError(t, os.MkdirAll(hlsPath, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(hlsPath, "playlist.m3u8"), []byte("#EXTM3U"), 0o644))
+
+	video := newTestVideo(id)
+	video.MarkAsCompleted(hlsPath, filepath.Join(hlsPath, "playlist.m3u8"))
+	return video
+}
+
+func newTestPurger(videoRepo *MockVideoRepository, fileRepo *MockVideoFileRepository, storage RemoteStorage) *VideoPurger {
+	config := DefaultVideoPurgerConfig()
+	config.DeletedRetention = 24 * time.Hour
+	config.Storage = storage
+	config.Lock = &fakeLocker{}
+	return NewVideoPurger(videoRepo, fileRepo, config)
+}
+
+func TestVideoPurger_PurgesDeletedVideo(t *testing.T) {
+	videoRepo := new(MockVideoRepository)
+	fileRepo := new(MockVideoFileRepository)
+	storage := &fakeRemoteStorage{}
+	purger := newTestPurger(videoRepo, fileRepo, storage)
+
+	video := newVideoWithOutput(t, "video-1")
+	deletedAt := time.Now().Add(-48 * time.Hour)
+	manifest := entity.NewVideoFile("video-1", entity.FileTypeManifest, "playlist.m3u8", 0, 100, 0, "")
+	manifest.MarkUploaded("videos/video-1/playlist.m3u8")
+	segment := entity.NewVideoFile("video-1", entity.FileTypeSegment, "segment_000.ts", 0, 900, time.Second, "")
+
+	videoRepo.On("ListPurgeCandidates", mock.Anything, mock.MatchedBy(func(criteria repository.PurgeCriteria) bool {
+		return !criteria.DeletedBefore.IsZero() && !criteria.FailedBefore.IsZero() && criteria.Limit == defaultPurgeBatchSize
+	})).Return([]repository.PurgeCandidate{{Video: video, Reason: repository.PurgeReasonDeleted, DeletedAt: &deletedAt}}, nil)
+	videoRepo.On("BeginPurge", mock.Anything, "video-1", mock.Anything).Return(nil)
+	videoRepo.On("Purge", mock.Anything, "video-1").Return(nil)
+	fileRepo.On("ListByVideoID", mock.Anything, "video-1").Return([]*entity.VideoFile{manifest, segment}, nil)
+
+	var progress []PurgeProgress
+	purger.onProgress = func(p PurgeProgress) { progress = append(progress, p) }
+
+	result, err := purger.PurgeOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"video-1"}, result.Purged)
+	assert.EqualValues(t, 1000, result.FreedBytes)
+	assert.Equal(t, []string{"videos/video-1/playlist.m3u8"}, storage.deleted)
+	assert.NoDirExists(t, video.HLSPath)
+	assert.Equal(t, []PurgeProgress{{Total: 1, Processed: 1, Purged: 1, FreedBytes: 1000, VideoID: "video-1"}}, progress)
+	videoRepo.AssertExpectations(t)
+	fileRepo.AssertExpectations(t)
+}
+
+func TestVideoPurger_FallsBackToVideoS3Keys(t *testing.T) {
+	videoRepo := new(MockVideoRepository)
+	storage := &fakeRemoteStorage{}
+	config := DefaultVideoPurgerConfig()
+	config.Storage = storage
+	// Sem FileRepository, como no SQLite, que não tem a tabela video_files
+	purger := NewVideoPurger(videoRepo, nil, config)
+
+	video := newVideoWithOutput(t, "video-1")
+	candidate := repository.PurgeCandidate{
+		Video:  video,
+		Reason: repository.PurgeReasonFailed,
+		S3Keys: []string{"videos/video-1/segments", "videos/video-1/playlist.m3u8"},
+	}
+
+	videoRepo.On("ListPurgeCandidates", mock.Anything, mock.Anything).Return([]repository.PurgeCandidate{candidate}, nil)
+	videoRepo.On("BeginPurge", mock.Anything, "video-1", mock.Anything).Return(nil)
+	videoRepo.On("Purge", mock.Anything, "video-1").Return(nil)
+
+	result, err := purger.PurgeOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"video-1"}, result.Purged)
+	assert.Equal(t, candidate.S3Keys, storage.deleted)
+	videoRepo.AssertExpectations(t)
+
+	// Sem armazenamento remoto configurado, o vídeo com chaves no S3 não é removido
+	videoRepo = new(MockVideoRepository)
+	config.Storage = nil
+	purger = NewVideoPurger(videoRepo, nil, config)
+
+	videoRepo.On("ListPurgeCandidates", mock.Anything, mock.Anything).Return([]repository.PurgeCandidate{candidate}, nil)
+	videoRepo.On("BeginPurge", mock.Anything, "video-1", mock.Anything).Return(nil)
+
+	result, err = purger.PurgeOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"video-1"}, result.Failed)
+	videoRepo.AssertNotCalled(t, "Purge", mock.Anything, mock.Anything)
+}
+
+func TestVideoPurger_KeepsOriginalOutputOfDuplicate(t *testing.T) {
+	videoRepo := new(MockVideoRepository)
+	fileRepo := new(MockVideoFileRepository)
+	purger := newTestPurger(videoRepo, fileRepo, nil)
+
+	// A duplicata aponta para o diretório do original, que não pode ser apagado
+	original := newVideoWithOutput(t, "original")
+	duplicate := newTestVideo("duplicate")
+	require.NoError(t, duplicate.LinkToOriginal(original))
+
+	videoRepo.On("ListPurgeCandidates", mock.Anything, mock.Anything).
+		Return([]repository.PurgeCandidate{{Video: duplicate, Reason: repository.PurgeReasonFailed}}, nil)
+	videoRepo.On("BeginPurge", mock.Anything, "duplicate", mock.Anything).Return(nil)
+	videoRepo.On("Purge", mock.Anything, "duplicate").Return(nil)
+
+	result, err := purger.PurgeOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"duplicate"}, result.Purged)
+	assert.DirExists(t, original.HLSPath)
+	fileRepo.AssertNotCalled(t, "ListByVideoID", mock.Anything, mock.Anything)
+	videoRepo.AssertExpectations(t)
+}
+
+func TestVideoPurger_KeepsVideoWhenRemoteDeletionFails(t *testing.T) {
+	videoRepo := new(MockVideoRepository)
+	fileRepo := new(MockVideoFileRepository)
+	purger := newTestPurger(videoRepo, fileRepo, &fakeRemoteStorage{err: errors.New("s3 indisponível")})
+
+	video := newVideoWithOutput(t, "video-1")
+	manifest := entity.NewVideoFile("video-1", entity.FileTypeManifest, "playlist.m3u8", 0, 100, 0, "")
+	manifest.MarkUploaded("videos/video-1/playlist.m3u8")
+
+	videoRepo.On("ListPurgeCandidates", mock.Anything, mock.Anything).
+		Return([]repository.PurgeCandidate{{Video: video, Reason: repository.PurgeReasonDeleted}}, nil)
+	videoRepo.On("BeginPurge", mock.Anything, "video-1", mock.Anything).Return(nil)
+	fileRepo.On("ListByVideoID", mock.Anything, "video-1").Return([]*entity.VideoFile{manifest}, nil)
+
+	result, err := purger.PurgeOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"video-1"}, result.Failed)
+	assert.Empty(t, result.Purged)
+	// O diretório local e a linha continuam para a próxima varredura
+	assert.DirExists(t, video.HLSPath)
+	videoRepo.AssertNotCalled(t, "Purge", mock.Anything, mock.Anything)
+}
+
+func TestVideoPurger_SkipsVideoNoLongerPurgeable(t *testing.T) {
+	videoRepo := new(MockVideoRepository)
+	fileRepo := new(MockVideoFileRepository)
+	purger := newTestPurger(videoRepo, fileRepo, nil)
+
+	video := newVideoWithOutput(t, "video-1")
+
+	videoRepo.On("ListPurgeCandidates", mock.Anything, mock.Anything).
+		Return([]repository.PurgeCandidate{{Video: video, Reason: repository.PurgeReasonDeleted}}, nil)
+	videoRepo.On("BeginPurge", mock.Anything, "video-1", mock.Anything).Return(repository.ErrVideoNotPurgeable)
+
+	result, err := purger.PurgeOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Purged)
+	assert.Empty(t, result.Failed)
+	assert.DirExists(t, video.HLSPath)
+	fileRepo.AssertNotCalled(t, "ListByVideoID", mock.Anything, mock.Anything)
+}
+
+func TestVideoPurger_NegativeRetentionDisablesCriterion(t *testing.T) {
+	config := DefaultVideoPurgerConfig()
+	config.FailedRetention = -1
+	purger := NewVideoPurger(new(MockVideoRepository), nil, config)
+
+	criteria := purger.criteria(time.Now())
+
+	assert.False(t, criteria.DeletedBefore.IsZero())
+	assert.True(t, criteria.FailedBefore.IsZero())
+}
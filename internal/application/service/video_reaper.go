@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	"github.com/devfullcycle/golangtechweek/internal/domain/event"
+	"github.com/devfullcycle/golangtechweek/internal/domain/repository"
+)
+
+const (
+	defaultReaperInterval    = time.Minute
+	defaultReaperStaleAfter  = 30 * time.Minute
+	defaultReaperMaxAttempts = 3
+	defaultReaperBatchSize   = 100
+)
+
+// reaperActor identifica o reaper no histórico de auditoria
+var reaperActor = repository.Actor{Type: repository.ActorTypeSystem, ID: "reaper"}
+
+// errVideoNotStale indica que o vídeo voltou a dar sinal de vida entre a listagem e a gravação
+var errVideoNotStale = errors.New("o vídeo não está mais abandonado")
+
+// Locker executa uma tarefa apenas se nenhuma outra instância a estiver executando
+type Locker interface {
+	// TryRun executa fn se conseguir o lock, retornando false sem executá-la caso contrário
+	TryRun(ctx context.Context, fn func(ctx context.Context) error) (bool, error)
+}
+
+// VideoReaperConfig contém as configurações do reaper de vídeos abandonados
+type VideoReaperConfig struct {
+	Interval        time.Duration    // Intervalo entre as varreduras
+	StaleAfter      time.Duration    // Tempo sem sinal de vida para um vídeo em processamento ser considerado abandonado
+	MaxAttempts     int              // Número de tentativas a partir do qual o vídeo abandonado é marcado como falho
+	BatchSize       int              // Máximo de vídeos tratados por varredura
+	Lock            Locker           // Garante uma única instância do reaper no cluster (opcional)
+	EventDispatcher event.Dispatcher // Dispatcher dos eventos de domínio (opcional)
+	Logger          *slog.Logger
+}
+
+// DefaultVideoReaperConfig retorna uma configuração padrão para o reaper
+func DefaultVideoReaperConfig() VideoReaperConfig {
+	return VideoReaperConfig{
+		Interval:    defaultReaperInterval,
+		StaleAfter:  defaultReaperStaleAfter,
+		MaxAttempts: defaultReaperMaxAttempts,
+		BatchSize:   defaultReaperBatchSize,
+		Logger: slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			Level: slog.LevelInfo,
+		})),
+	}
+}
+
+// ReapResult resume o que uma varredura fez
+type ReapResult struct {
+	Skipped  bool     // Outra instância detinha o lock e a varredura não foi executada
+	Requeued []string // IDs dos vídeos devolvidos para a fila
+	Failed   []string // IDs dos vídeos marcados como falhos por excederem as tentativas
+}
+
+// VideoReaper encontra vídeos presos em "processing" por um conversor que morreu no meio do trabalho
+// e os devolve para a fila ou, esgotadas as tentativas, os marca como falhos
+// O conversor renova a concessão do vídeo enquanto o FFmpeg reporta progresso; sem concessão, vale updated_at
+type VideoReaper struct {
+	videoRepo   repository.VideoRepository
+	attemptRepo repository.ProcessingAttemptRepository
+	lock        Locker
+	dispatcher  event.Dispatcher
+	interval    time.Duration
+	staleAfter  time.Duration
+	maxAttempts int
+	batchSize   int
+	logger      *slog.Logger
+}
+
+// NewVideoReaper cria um reaper que conta as tentativas de cada vídeo pelo histórico de attemptRepo
+func NewVideoReaper(videoRepo repository.VideoRepository, attemptRepo repository.ProcessingAttemptRepository, config VideoReaperConfig) *VideoReaper {
+	if config.Interval <= 0 {
+		config.Interval = defaultReaperInterval
+	}
+
+	if config.StaleAfter <= 0 {
+		config.StaleAfter = defaultReaperStaleAfter
+	}
+
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultReaperMaxAttempts
+	}
+
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultReaperBatchSize
+	}
+
+	if config.Logger == nil {
+		config.Logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			Level: slog.LevelInfo,
+		}))
+	}
+
+	return &VideoReaper{
+		videoRepo:   videoRepo,
+		attemptRepo: attemptRepo,
+		lock:        config.Lock,
+		dispatcher:  config.EventDispatcher,
+		interval:    config.Interval,
+		staleAfter:  config.StaleAfter,
+		maxAttempts: config.MaxAttempts,
+		batchSize:   config.BatchSize,
+		logger:      config.Logger,
+	}
+}
+
+// Run executa uma varredura imediatamente e depois a cada Interval, até o contexto ser cancelado
+func (r *VideoReaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.ReapOnce(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Error("Erro na varredura de vídeos abandonados", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReapOnce executa uma varredura, se esta instância conseguir o lock
+// Falhas em um vídeo são registradas no log e não interrompem a varredura dos demais
+func (r *VideoReaper) ReapOnce(ctx context.Context) (*ReapResult, error) {
+	ctx = repository.WithActor(ctx, reaperActor)
+	result := &ReapResult{}
+
+	if r.lock == nil {
+		return result, r.reap(ctx, result)
+	}
+
+	acquired, err := r.lock.TryRun(ctx, func(ctx context.Context) error {
+		return r.reap(ctx, result)
+	})
+	if !acquired && err == nil {
+		r.logger.Debug("Varredura de vídeos abandonados executada por outra instância")
+		result.Skipped = true
+	}
+
+	return result, err
+}
+
+// reap trata um lote de vídeos abandonados
+func (r *VideoReaper) reap(ctx context.Context, result *ReapResult) error {
+	staleBefore := time.Now().Add(-r.staleAfter)
+
+	videos, err := r.videoRepo.ListStaleProcessing(ctx, staleBefore, r.batchSize)
+	if err != nil {
+		return fmt.Errorf("erro ao listar vídeos abandonados: %w", err)
+	}
+
+	for _, video := range videos {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		reaped, err := r.reapVideo(ctx, video.ID, staleBefore)
+		if err != nil {
+			if errors.Is(err, errVideoNotStale) || errors.Is(err, repository.ErrVideoNotFound) {
+				r.logger.Info("Vídeo deixou de estar abandonado antes de ser tratado", "video_id", video.ID)
+				continue
+			}
+			r.logger.Error("Erro ao tratar vídeo abandonado", "video_id", video.ID, "error", err)
+			continue
+		}
+
+		if reaped.Status == entity.StatusPending {
+			result.Requeued = append(result.Requeued, reaped.ID)
+		} else {
+			result.Failed = append(result.Failed, reaped.ID)
+		}
+	}
+
+	return nil
+}
+
+// reapVideo devolve o vídeo para a fila ou o marca como falho, conforme o número de tentativas
+// A gravação usa a versão lida, então um worker que volte a atualizar o vídeo nesse meio-tempo prevalece
+func (r *VideoReaper) reapVideo(ctx context.Context, videoID string, staleBefore time.Time) (*entity.Video, error) {
+	attempts, err := r.attemptRepo.ListByVideoID(ctx, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar tentativas: %w", err)
+	}
+
+	var staleSince time.Time
+	var leaseOwner string
+
+	video, err := repository.RetryOnConflict(ctx, r.videoRepo, videoID, 0, func(video *entity.Video) error {
+		if !video.IsStale(staleBefore) {
+			return errVideoNotStale
+		}
+
+		staleSince, leaseOwner = video.UpdatedAt, video.LeaseOwner
+		if video.LeaseExpiresAt != nil {
+			staleSince = *video.LeaseExpiresAt
+		}
+
+		if len(attempts) >= r.maxAttempts {
+			video.MarkAsFailed(fmt.Sprintf("conversão abandonada após %d tentativas", len(attempts)))
+		} else {
+			video.Requeue()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if video.Status == entity.StatusPending {
+		r.logger.Warn("Vídeo abandonado devolvido para a fila",
+			"video_id", video.ID, "attempts", len(attempts), "lease_owner", leaseOwner, "stale_since", staleSince)
+	} else {
+		r.logger.Warn("Vídeo abandonado marcado como falho",
+			"video_id", video.ID, "attempts", len(attempts), "lease_owner", leaseOwner, "stale_since", staleSince)
+	}
+
+	r.finishAbandonedAttempts(ctx, attempts)
+	dispatchVideoEvents(ctx, r.dispatcher, r.logger, video)
+
+	return video, nil
+}
+
+// finishAbandonedAttempts encerra como falhas as tentativas que o conversor não chegou a finalizar
+func (r *VideoReaper) finishAbandonedAttempts(ctx context.Context, attempts []*entity.ProcessingAttempt) {
+	for _, attempt := range attempts {
+		if attempt.IsFinished() {
+			continue
+		}
+
+		attempt.MarkAsFailed("tentativa abandonada pelo worker", "")
+		if err := r.attemptRepo.Finish(ctx, attempt); err != nil {
+			r.logger.Error("Erro ao encerrar tentativa abandonada", "video_id", attempt.VideoID, "attempt_id", attempt.ID, "error", err)
+			// Não interrompe o reaper por erro no histórico de tentativas
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLocker simula o lock do cluster, detido ou não por outra instância
+type fakeLocker struct {
+	heldElsewhere bool
+}
+
+func (l *fakeLocker) TryRun(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	if l.heldElsewhere {
+		return false, nil
+	}
+	return true, fn(ctx)
+}
+
+// newStaleVideo cria um vídeo em processamento sem atualização há duas horas
+func newStaleVideo(id string) *entity.Video {
+	video := newTestVideo(id)
+	video.MarkAsProcessing()
+	video.PullEvents()
+	video.UpdatedAt = time.Now().Add(-2 * time.Hour)
+	return video
+}
+
+func newTestReaper(videoRepo *MockVideoRepository, attemptRepo *MockProcessingAttemptRepository, locker Locker) *VideoReaper {
+	config := DefaultVideoReaperConfig()
+	config.StaleAfter = time.Hour
+	config.MaxAttempts = 2
+	config.Lock = locker
+	return NewVideoReaper(videoRepo, attemptRepo, config)
+}
+
+func TestVideoReaper_RequeuesStaleVideo(t *testing.T) {
+	videoRepo := new(MockVideoRepository)
+	attemptRepo := new(MockProcessingAttemptRepository)
+	reaper := newTestReaper(videoRepo, attemptRepo, &fakeLocker{})
+
+	stale := newStaleVideo("video-1")
+	running := entity.NewProcessingAttempt("video-1", "worker-morto", nil)
+
+	videoRepo.On("ListStaleProcessing", mock.Anything, mock.Anything, defaultReaperBatchSize).Return([]*entity.Video{stale}, nil)
+	videoRepo.On("FindByID", mock.Anything, "video-1").Return(newStaleVideo("video-1"), nil)
+	videoRepo.On("Update", mock.Anything, mock.MatchedBy(func(video *entity.Video) bool {
+		return video.Status == entity.StatusPending
+	})).Return(nil)
+	attemptRepo.On("ListByVideoID", mock.Anything, "video-1").Return([]*entity.ProcessingAttempt{running}, nil)
+	attemptRepo.On("Finish", mock.Anything, mock.MatchedBy(func(attempt *entity.ProcessingAttempt) bool {
+		return attempt.ID == running.ID && attempt.Outcome == entity.AttemptOutcomeFailed
+	})).Return(nil)
+
+	result, err := reaper.ReapOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"video-1"}, result.Requeued)
+	assert.Empty(t, result.Failed)
+	videoRepo.AssertExpectations(t)
+	attemptRepo.AssertExpectations(t)
+}
+
+func TestVideoReaper_FailsVideoAfterMaxAttempts(t *testing.T) {
+	videoRepo := new(MockVideoRepository)
+	attemptRepo := new(MockProcessingAttemptRepository)
+	reaper := newTestReaper(videoRepo, attemptRepo, nil)
+
+	failed := entity.NewProcessingAttempt("video-1", "worker-1", nil)
+	failed.MarkAsFailed("erro", "")
+	finished := entity.NewProcessingAttempt("video-1", "worker-2", nil)
+	finished.MarkAsFailed("erro", "")
+
+	videoRepo.On("ListStaleProcessing", mock.Anything, mock.Anything, mock.Anything).Return([]*entity.Video{newStaleVideo("video-1")}, nil)
+	videoRepo.On("FindByID", mock.Anything, "video-1").Return(newStaleVideo("video-1"), nil)
+	videoRepo.On("Update", mock.Anything, mock.MatchedBy(func(video *entity.Video) bool {
+		return video.Status == entity.StatusError && video.ErrorMessage == "conversão abandonada após 2 tentativas"
+	})).Return(nil)
+	attemptRepo.On("ListByVideoID", mock.Anything, "video-1").Return([]*entity.ProcessingAttempt{failed, finished}, nil)
+
+	result, err := reaper.ReapOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"video-1"}, result.Failed)
+	videoRepo.AssertExpectations(t)
+	attemptRepo.AssertNotCalled(t, "Finish", mock.Anything, mock.Anything)
+}
+
+func TestVideoReaper_SkipsVideoThatRecovered(t *testing.T) {
+	videoRepo := new(MockVideoRepository)
+	attemptRepo := new(MockProcessingAttemptRepository)
+	reaper := newTestReaper(videoRepo, attemptRepo, nil)
+
+	// O worker renovou a concessão entre a listagem e a releitura
+	recovered := newStaleVideo("video-1")
+	expiresAt := time.Now().Add(time.Minute)
+	recovered.LeaseOwner = "worker-1"
+	recovered.LeaseExpiresAt = &expiresAt
+
+	videoRepo.On("ListStaleProcessing", mock.Anything, mock.Anything, mock.Anything).Return([]*entity.Video{newStaleVideo("video-1")}, nil)
+	videoRepo.On("FindByID", mock.Anything, "video-1").Return(recovered, nil)
+	attemptRepo.On("ListByVideoID", mock.Anything, "video-1").Return([]*entity.ProcessingAttempt{}, nil)
+
+	result, err := reaper.ReapOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Requeued)
+	assert.Empty(t, result.Failed)
+	videoRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestVideoReaper_SkipsVideoWithRecentProgress(t *testing.T) {
+	videoRepo := new(MockVideoRepository)
+	attemptRepo := new(MockProcessingAttemptRepository)
+	reaper := newTestReaper(videoRepo, attemptRepo, nil)
+
+	// Conversão longa: a última atualização é antiga, mas o FFmpeg continua reportando progresso
+	video := newStaleVideo("video-1")
+	video.AcquireLease("worker-1", time.Now().Add(-90*time.Minute))
+
+	config := DefaultVideoConverterConfig()
+	config.WorkerID = "worker-1"
+	config.LeaseDuration = 30 * time.Millisecond
+	converter := NewVideoConverter(new(MockFFmpegService), videoRepo, config)
+
+	videoRepo.On("RenewLease", mock.Anything, "video-1", "worker-1", config.LeaseDuration).Return(nil)
+	videoRepo.On("UpdateProgress", mock.Anything, "video-1", 40, entity.ProcessingStageTranscoding, mock.Anything).Return(nil)
+
+	report := converter.newProgressReporter(context.Background(), video)
+	time.Sleep(15 * time.Millisecond)
+	report(ConversionProgress{Stage: entity.ProcessingStageTranscoding, Percent: 40})
+	video.UpdatedAt = time.Now().Add(-2 * time.Hour)
+
+	// A listagem ainda trazia o vídeo, mas a releitura mostra a concessão renovada
+	videoRepo.On("ListStaleProcessing", mock.Anything, mock.Anything, mock.Anything).Return([]*entity.Video{newStaleVideo("video-1")}, nil)
+	videoRepo.On("FindByID", mock.Anything, "video-1").Return(video, nil)
+	attemptRepo.On("ListByVideoID", mock.Anything, "video-1").Return([]*entity.ProcessingAttempt{}, nil)
+
+	result, err := reaper.ReapOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Requeued)
+	assert.Empty(t, result.Failed)
+	videoRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestVideoReaper_SkipsWhenLockHeldElsewhere(t *testing.T) {
+	videoRepo := new(MockVideoRepository)
+	attemptRepo := new(MockProcessingAttemptRepository)
+	reaper := newTestReaper(videoRepo, attemptRepo, &fakeLocker{heldElsewhere: true})
+
+	result, err := reaper.ReapOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, result.Skipped)
+	videoRepo.AssertNotCalled(t, "ListStaleProcessing", mock.Anything, mock.Anything, mock.Anything)
+}
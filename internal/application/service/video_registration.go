@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	"github.com/devfullcycle/golangtechweek/internal/domain/event"
+	"github.com/devfullcycle/golangtechweek/internal/domain/repository"
+)
+
+// RegisterVideoInput representa os dados necessários para registrar um novo vídeo
+type RegisterVideoInput struct {
+	OwnerID     string   // Conta de cliente dona do vídeo
+	Title       string   // Título do vídeo
+	Description string   // Descrição do vídeo
+	FilePath    string   // Caminho do arquivo original
+	Tags        []string // Tags livres do vídeo
+}
+
+// DuplicatePolicy define o que fazer quando o arquivo enviado já foi convertido para a mesma conta de cliente
+type DuplicatePolicy string
+
+const (
+	// DuplicatePolicyAllow registra o vídeo normalmente, ignorando a duplicata
+	DuplicatePolicyAllow DuplicatePolicy = "allow"
+	// DuplicatePolicyLink registra o vídeo já concluído, reaproveitando a saída HLS do original
+	DuplicatePolicyLink DuplicatePolicy = "link"
+	// DuplicatePolicyReject recusa o registro do vídeo duplicado
+	DuplicatePolicyReject DuplicatePolicy = "reject"
+)
+
+// ErrDuplicateVideo é retornado quando o vídeo é recusado pela política DuplicatePolicyReject
+var ErrDuplicateVideo = errors.New("vídeo duplicado")
+
+// DuplicateVideoError informa qual vídeo já existente possui o mesmo conteúdo
+type DuplicateVideoError struct {
+	ExistingVideoID string
+}
+
+func (e *DuplicateVideoError) Error() string {
+	return fmt.Sprintf("%s: conteúdo idêntico ao vídeo %s", ErrDuplicateVideo, e.ExistingVideoID)
+}
+
+// Is permite comparar o erro com ErrDuplicateVideo via errors.Is
+func (e *DuplicateVideoError) Is(target error) bool {
+	return target == ErrDuplicateVideo
+}
+
+// VideoRegistrationConfig representa a configuração do serviço de registro de vídeos
+type VideoRegistrationConfig struct {
+	Logger          *slog.Logger     // Logger para registro de eventos
+	EventDispatcher event.Dispatcher // Dispatcher dos eventos de domínio (opcional)
+	DuplicatePolicy DuplicatePolicy  // Política aplicada a uploads duplicados (padrão: DuplicatePolicyLink)
+}
+
+// VideoRegistrationService implementa o registro de novos vídeos
+type VideoRegistrationService struct {
+	videoRepo       repository.VideoRepository
+	dispatcher      event.Dispatcher
+	duplicatePolicy DuplicatePolicy
+	logger          *slog.Logger
+}
+
+// NewVideoRegistrationService cria uma nova instância do serviço de registro de vídeos
+func NewVideoRegistrationService(videoRepo repository.VideoRepository, config VideoRegistrationConfig) *VideoRegistrationService {
+	if config.Logger == nil {
+		config.Logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+			Level: slog.LevelInfo,
+		}))
+	}
+
+	if config.DuplicatePolicy == "" {
+		config.DuplicatePolicy = DuplicatePolicyLink
+	}
+
+	return &VideoRegistrationService{
+		videoRepo:       videoRepo,
+		dispatcher:      config.EventDispatcher,
+		duplicatePolicy: config.DuplicatePolicy,
+		logger:          config.Logger,
+	}
+}
+
+// Register cria e persiste um novo vídeo e, após salvar com sucesso, despacha os eventos de domínio
+// A impressão digital do arquivo é calculada no registro e, se a conta de cliente já possuir um vídeo
+// concluído com o mesmo conteúdo, a política de duplicatas decide entre reaproveitar a saída ou recusar o upload.
+// Vídeos vinculados a um original já retornam concluídos e não precisam ser enviados para conversão.
+func (s *VideoRegistrationService) Register(ctx context.Context, input RegisterVideoInput) (*entity.Video, error) {
+	video := entity.NewVideo(input.OwnerID, input.Title, input.Description, input.FilePath, input.Tags...)
+
+	contentHash, err := FingerprintFile(input.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao registrar vídeo: %w", err)
+	}
+	video.SetContentHash(contentHash)
+
+	if s.duplicatePolicy != DuplicatePolicyAllow {
+		if err := s.applyDuplicatePolicy(ctx, video); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.videoRepo.Create(ctx, video); err != nil {
+		return nil, fmt.Errorf("erro ao registrar vídeo: %w", err)
+	}
+
+	dispatchVideoEvents(ctx, s.dispatcher, s.logger, video)
+
+	s.logger.Info("Vídeo registrado", "video_id", video.ID, "owner_id", video.OwnerID, "duplicate_of", video.DuplicateOfID)
+
+	return video, nil
+}
+
+// applyDuplicatePolicy procura um vídeo já convertido com o mesmo conteúdo e aplica a política configurada
+func (s *VideoRegistrationService) applyDuplicatePolicy(ctx context.Context, video *entity.Video) error {
+	original, err := s.videoRepo.FindCompletedByContentHash(ctx, video.OwnerID, video.ContentHash)
+	if err != nil {
+		if errors.Is(err, repository.ErrVideoNotFound) {
+			return nil
+		}
+		return fmt.Errorf("erro ao verificar vídeo duplicado: %w", err)
+	}
+
+	if s.duplicatePolicy == DuplicatePolicyReject {
+		return &DuplicateVideoError{ExistingVideoID: original.ID}
+	}
+
+	if err := video.LinkToOriginal(original); err != nil {
+		return fmt.Errorf("erro ao vincular vídeo duplicado: %w", err)
+	}
+
+	return nil
+}
+
+// dispatchVideoEvents despacha os eventos pendentes do vídeo
+// Deve ser chamada somente após o repositório salvar o vídeo com sucesso
+// Falhas dos assinantes são apenas registradas em log e não desfazem a operação já persistida
+func dispatchVideoEvents(ctx context.Context, dispatcher event.Dispatcher, logger *slog.Logger, video *entity.Video) {
+	events := video.PullEvents()
+	if dispatcher == nil || len(events) == 0 {
+		return
+	}
+
+	if err := dispatcher.Dispatch(ctx, events...); err != nil {
+		logger.Error("Erro ao despachar eventos do vídeo", "video_id", video.ID, "error", err)
+	}
+}
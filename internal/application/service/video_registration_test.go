@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	"github.com/devfullcycle/golangtechweek/internal/domain/event"
+	"github.com/devfullcycle/golangtechweek/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleVideoHash é o SHA-256 do conteúdo gravado por writeSampleVideo
+const sampleVideoHash = "d04dc2b6d612ed55340c40292f5213be945118a13b1e6f5a6e8df430f6e23aae"
+
+// writeSampleVideo cria um arquivo temporário para o registro calcular a impressão digital
+func writeSampleVideo(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "video.mp4")
+	require.NoError(t, os.WriteFile(path, []byte("conteúdo do vídeo"), 0644))
+	return path
+}
+
+func TestVideoRegistrationService_Register_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	dispatcher := event.NewEventDispatcher()
+	registration := NewVideoRegistrationService(mockRepo, VideoRegistrationConfig{EventDispatcher: dispatcher})
+	filePath := writeSampleVideo(t)
+
+	var registered []event.Event
+	dispatcher.Subscribe(entity.EventVideoRegistered, func(ctx context.Context, evt event.Event) error {
+		registered = append(registered, evt)
+		return nil
+	})
+
+	mockRepo.On("FindCompletedByContentHash", mock.Anything, "owner-123", mock.AnythingOfType("string")).Return(nil, repository.ErrVideoNotFound)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Video")).Return(nil)
+
+	// Act
+	video, err := registration.Register(context.Background(), RegisterVideoInput{
+		OwnerID:     "owner-123",
+		Title:       "Meu Vídeo",
+		Description: "Descrição",
+		FilePath:    filePath,
+		Tags:        []string{"golang"},
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "owner-123", video.OwnerID)
+	assert.Equal(t, []string{"golang"}, video.Tags)
+	assert.Len(t, video.ContentHash, 64)
+	assert.Equal(t, entity.StatusPending, video.Status)
+	assert.False(t, video.IsDuplicate())
+	assert.Empty(t, video.Events())
+	if assert.Len(t, registered, 1) {
+		assert.Equal(t, video.ID, registered[0].AggregateID())
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestVideoRegistrationService_Register_CreateError(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	dispatcher := event.NewEventDispatcher()
+	registration := NewVideoRegistrationService(mockRepo, VideoRegistrationConfig{EventDispatcher: dispatcher})
+
+	var registered []event.Event
+	dispatcher.Subscribe(event.AllEvents, func(ctx context.Context, evt event.Event) error {
+		registered = append(registered, evt)
+		return nil
+	})
+
+	mockRepo.On("FindCompletedByContentHash", mock.Anything, "owner-123", mock.AnythingOfType("string")).Return(nil, repository.ErrVideoNotFound)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Video")).Return(errors.New("erro no banco"))
+
+	// Act
+	video, err := registration.Register(context.Background(), RegisterVideoInput{
+		OwnerID:  "owner-123",
+		Title:    "Meu Vídeo",
+		FilePath: writeSampleVideo(t),
+	})
+
+	// Assert - nenhum evento deve ser despachado se o vídeo não foi salvo
+	assert.Error(t, err)
+	assert.Nil(t, video)
+	assert.Empty(t, registered)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestVideoRegistrationService_Register_FileNotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	registration := NewVideoRegistrationService(mockRepo, VideoRegistrationConfig{})
+
+	// Act
+	video, err := registration.Register(context.Background(), RegisterVideoInput{
+		OwnerID:  "owner-123",
+		Title:    "Meu Vídeo",
+		FilePath: filepath.Join(t.TempDir(), "inexistente.mp4"),
+	})
+
+	// Assert - o vídeo não é salvo sem impressão digital
+	assert.Error(t, err)
+	assert.Nil(t, video)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestVideoRegistrationService_Register_LinksDuplicate(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	dispatcher := event.NewEventDispatcher()
+	registration := NewVideoRegistrationService(mockRepo, VideoRegistrationConfig{EventDispatcher: dispatcher})
+
+	var names []string
+	dispatcher.Subscribe(event.AllEvents, func(ctx context.Context, evt event.Event) error {
+		names = append(names, evt.Name())
+		return nil
+	})
+
+	original := newTestVideo("original-123")
+	original.OwnerID = "owner-123"
+	original.MarkAsCompleted("/tmp/converted/original-123", "/tmp/converted/original-123/playlist.m3u8")
+	original.MarkUploadCompleted("https://bucket.s3/original-123", "https://bucket.s3/original-123/playlist.m3u8")
+	original.PullEvents()
+
+	mockRepo.On("FindCompletedByContentHash", mock.Anything, "owner-123", mock.AnythingOfType("string")).Return(original, nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Video")).Return(nil)
+
+	// Act
+	video, err := registration.Register(context.Background(), RegisterVideoInput{
+		OwnerID:  "owner-123",
+		Title:    "Cópia",
+		FilePath: writeSampleVideo(t),
+	})
+
+	// Assert - o vídeo já nasce concluído, reaproveitando a saída do original
+	require.NoError(t, err)
+	assert.Equal(t, "original-123", video.DuplicateOfID)
+	assert.True(t, video.IsCompleted())
+	assert.Equal(t, original.ManifestPath, video.ManifestPath)
+	assert.Equal(t, original.S3ManifestURL, video.S3ManifestURL)
+	assert.Equal(t, entity.UploadStatusCompletedS3, video.UploadStatus)
+	assert.Equal(t, []string{
+		entity.EventVideoRegistered,
+		entity.EventVideoProcessingCompleted,
+		entity.EventVideoUploadCompleted,
+	}, names)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestVideoRegistrationService_Register_RejectsDuplicate(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	registration := NewVideoRegistrationService(mockRepo, VideoRegistrationConfig{DuplicatePolicy: DuplicatePolicyReject})
+
+	original := newTestVideo("original-123")
+	original.MarkAsCompleted("/tmp/converted/original-123", "/tmp/converted/original-123/playlist.m3u8")
+
+	mockRepo.On("FindCompletedByContentHash", mock.Anything, "owner-123", mock.AnythingOfType("string")).Return(original, nil)
+
+	// Act
+	video, err := registration.Register(context.Background(), RegisterVideoInput{
+		OwnerID:  "owner-123",
+		Title:    "Cópia",
+		FilePath: writeSampleVideo(t),
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrDuplicateVideo)
+	var dupErr *DuplicateVideoError
+	if assert.ErrorAs(t, err, &dupErr) {
+		assert.Equal(t, "original-123", dupErr.ExistingVideoID)
+	}
+	assert.Nil(t, video)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestVideoRegistrationService_Register_AllowSkipsLookup(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockVideoRepository)
+	registration := NewVideoRegistrationService(mockRepo, VideoRegistrationConfig{DuplicatePolicy: DuplicatePolicyAllow})
+
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.Video")).Return(nil)
+
+	// Act
+	video, err := registration.Register(context.Background(), RegisterVideoInput{
+		OwnerID:  "owner-123",
+		Title:    "Cópia",
+		FilePath: writeSampleVideo(t),
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, entity.StatusPending, video.Status)
+	mockRepo.AssertNotCalled(t, "FindCompletedByContentHash", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestFingerprintFile(t *testing.T) {
+	path := writeSampleVideo(t)
+
+	hash, err := FingerprintFile(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, sampleVideoHash, hash)
+}
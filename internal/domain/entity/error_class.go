@@ -0,0 +1,47 @@
+package entity
+
+import "strings"
+
+// ErrorClass agrupa as mensagens de erro dos vídeos com falha por causa provável
+type ErrorClass string
+
+const (
+	ErrorClassCanceled  ErrorClass = "canceled"  // A conversão foi cancelada
+	ErrorClassTimeout   ErrorClass = "timeout"   // A conversão excedeu o tempo limite
+	ErrorClassAbandoned ErrorClass = "abandoned" // O worker parou de responder (veja VideoReaper)
+	ErrorClassInput     ErrorClass = "input"     // O arquivo original não existe ou não é um vídeo válido
+	ErrorClassStorage   ErrorClass = "storage"   // Falha ao gravar ou ler a saída no disco
+	ErrorClassFFmpeg    ErrorClass = "ffmpeg"    // Demais falhas do FFmpeg
+	ErrorClassOther     ErrorClass = "other"     // Mensagens não reconhecidas
+)
+
+// errorClassRules associa trechos das mensagens de erro às classes, na ordem em que são testados
+// As mensagens costumam envolver a causa (ex.: "erro na conversão FFmpeg: ... No such file or directory"),
+// por isso as classes mais específicas vêm antes de ErrorClassFFmpeg; um cancelamento por prazo esgotado
+// ("conversão cancelada: context deadline exceeded") conta como ErrorClassTimeout
+var errorClassRules = []struct {
+	class    ErrorClass
+	patterns []string
+}{
+	{ErrorClassTimeout, []string{"deadline exceeded", "timeout", "tempo limite"}},
+	{ErrorClassCanceled, []string{"conversão cancelada", "operação cancelada", "context canceled"}},
+	{ErrorClassAbandoned, []string{"abandonada"}},
+	{ErrorClassInput, []string{"no such file or directory", "invalid data found", "moov atom not found", "arquivo não encontrado"}},
+	{ErrorClassStorage, []string{"no space left", "permission denied", "read-only file system", "diretório de saída", "arquivos gerados"}},
+	{ErrorClassFFmpeg, []string{"ffmpeg", "exit status"}},
+}
+
+// ClassifyError retorna a classe da mensagem de erro de um vídeo com falha
+func ClassifyError(message string) ErrorClass {
+	message = strings.ToLower(message)
+
+	for _, rule := range errorClassRules {
+		for _, pattern := range rule.patterns {
+			if strings.Contains(message, pattern) {
+				return rule.class
+			}
+		}
+	}
+
+	return ErrorClassOther
+}
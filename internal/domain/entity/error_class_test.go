@@ -0,0 +1,27 @@
+package entity
+
+import "testing"
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		message string
+		want    ErrorClass
+	}{
+		{"erro ao converter vídeo para HLS: operação cancelada: context canceled", ErrorClassCanceled},
+		{"erro ao converter vídeo para HLS: erro na conversão FFmpeg: context deadline exceeded", ErrorClassTimeout},
+		{"erro ao converter vídeo para HLS: conversão cancelada: context canceled", ErrorClassCanceled},
+		{"erro ao converter vídeo para HLS: conversão cancelada: context deadline exceeded", ErrorClassTimeout},
+		{"conversão abandonada após 3 tentativas", ErrorClassAbandoned},
+		{"erro na conversão FFmpeg: /videos/a.mp4: No such file or directory", ErrorClassInput},
+		{"erro ao converter vídeo para HLS: erro ao criar diretório de saída: mkdir /hls: permission denied", ErrorClassStorage},
+		{"erro ao converter vídeo para HLS: erro na conversão FFmpeg: exit status 1", ErrorClassFFmpeg},
+		{"falha desconhecida", ErrorClassOther},
+		{"", ErrorClassOther},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifyError(tt.message); got != tt.want {
+			t.Errorf("ClassifyError(%q) = %s, esperado %s", tt.message, got, tt.want)
+		}
+	}
+}
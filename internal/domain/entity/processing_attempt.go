@@ -0,0 +1,104 @@
+package entity
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Resultado de uma tentativa de processamento
+const (
+	// AttemptOutcomeRunning representa uma tentativa que ainda está em andamento
+	AttemptOutcomeRunning = "running"
+
+	// AttemptOutcomeSucceeded representa uma tentativa concluída com sucesso
+	AttemptOutcomeSucceeded = "succeeded"
+
+	// AttemptOutcomeFailed representa uma tentativa que terminou com erro
+	AttemptOutcomeFailed = "failed"
+
+	// AttemptOutcomeCanceled representa uma tentativa interrompida por cancelamento
+	AttemptOutcomeCanceled = "canceled"
+)
+
+// MaxStderrExcerptSize é o tamanho máximo, em bytes, do trecho do stderr do FFmpeg guardado na tentativa
+const MaxStderrExcerptSize = 4096
+
+// ProcessingAttempt representa uma tentativa de processamento (conversão) de um vídeo
+// Cada nova tentativa gera um novo registro, preservando o histórico de falhas anteriores
+type ProcessingAttempt struct {
+	ID             string            // Identificador único da tentativa
+	VideoID        string            // ID do vídeo processado
+	WorkerID       string            // Identificador do worker/host que executou a tentativa
+	EncodingParams map[string]string // Parâmetros de codificação usados na conversão
+	StderrExcerpt  string            // Trecho final da saída de erro do FFmpeg
+	Outcome        string            // Resultado da tentativa
+	ErrorMessage   string            // Mensagem de erro, se houver
+	StartedAt      time.Time         // Início da tentativa
+	FinishedAt     *time.Time        // Fim da tentativa (nil enquanto estiver em andamento)
+}
+
+// NewProcessingAttempt cria uma nova tentativa de processamento em andamento
+func NewProcessingAttempt(videoID, workerID string, encodingParams map[string]string) *ProcessingAttempt {
+	return &ProcessingAttempt{
+		ID:             uuid.New().String(),
+		VideoID:        videoID,
+		WorkerID:       workerID,
+		EncodingParams: encodingParams,
+		Outcome:        AttemptOutcomeRunning,
+		StartedAt:      time.Now(),
+	}
+}
+
+// MarkAsSucceeded finaliza a tentativa com sucesso
+func (a *ProcessingAttempt) MarkAsSucceeded() {
+	a.finish(AttemptOutcomeSucceeded, "", "")
+}
+
+// MarkAsFailed finaliza a tentativa com erro, guardando a mensagem e o trecho do stderr do FFmpeg
+func (a *ProcessingAttempt) MarkAsFailed(errorMessage, stderr string) {
+	a.finish(AttemptOutcomeFailed, errorMessage, stderr)
+}
+
+// MarkAsCanceled finaliza a tentativa como cancelada
+func (a *ProcessingAttempt) MarkAsCanceled(errorMessage, stderr string) {
+	a.finish(AttemptOutcomeCanceled, errorMessage, stderr)
+}
+
+// IsFinished verifica se a tentativa já foi finalizada
+func (a *ProcessingAttempt) IsFinished() bool {
+	return a.FinishedAt != nil
+}
+
+// Duration retorna a duração da tentativa (até agora, se ainda estiver em andamento)
+func (a *ProcessingAttempt) Duration() time.Duration {
+	if a.FinishedAt == nil {
+		return time.Since(a.StartedAt)
+	}
+	return a.FinishedAt.Sub(a.StartedAt)
+}
+
+// finish registra o resultado e o horário de término da tentativa
+func (a *ProcessingAttempt) finish(outcome, errorMessage, stderr string) {
+	now := time.Now()
+	a.Outcome = outcome
+	a.ErrorMessage = errorMessage
+	a.StderrExcerpt = TruncateStderr(stderr)
+	a.FinishedAt = &now
+}
+
+// TruncateStderr mantém apenas os últimos MaxStderrExcerptSize bytes do stderr,
+// onde o FFmpeg costuma escrever a causa do erro
+func TruncateStderr(stderr string) string {
+	if len(stderr) <= MaxStderrExcerptSize {
+		return stderr
+	}
+
+	// Avança até o início de um caractere para não gravar UTF-8 inválido no banco
+	start := len(stderr) - MaxStderrExcerptSize
+	for start < len(stderr) && !utf8.RuneStart(stderr[start]) {
+		start++
+	}
+	return stderr[start:]
+}
@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNewProcessingAttempt(t *testing.T) {
+	params := map[string]string{"c:v": "h264"}
+	attempt := NewProcessingAttempt("video-123", "worker-1", params)
+
+	if attempt.ID == "" {
+		t.Error("ID não deveria ser vazio")
+	}
+
+	if attempt.Outcome != AttemptOutcomeRunning {
+		t.Errorf("Esperado Outcome %s, obtido %s", AttemptOutcomeRunning, attempt.Outcome)
+	}
+
+	if attempt.IsFinished() {
+		t.Error("Tentativa não deveria estar finalizada")
+	}
+
+	if attempt.EncodingParams["c:v"] != "h264" {
+		t.Errorf("Esperado parâmetro c:v h264, obtido %s", attempt.EncodingParams["c:v"])
+	}
+}
+
+func TestProcessingAttemptMarkAsFailed(t *testing.T) {
+	attempt := NewProcessingAttempt("video-123", "worker-1", nil)
+
+	attempt.MarkAsFailed("erro na conversão", "Invalid data found when processing input")
+
+	if attempt.Outcome != AttemptOutcomeFailed {
+		t.Errorf("Esperado Outcome %s, obtido %s", AttemptOutcomeFailed, attempt.Outcome)
+	}
+
+	if !attempt.IsFinished() {
+		t.Error("Tentativa deveria estar finalizada")
+	}
+
+	if attempt.StderrExcerpt != "Invalid data found when processing input" {
+		t.Errorf("StderrExcerpt inesperado: %s", attempt.StderrExcerpt)
+	}
+}
+
+func TestTruncateStderr(t *testing.T) {
+	// O trecho deve manter o final do stderr, sem cortar caracteres multibyte
+	stderr := strings.Repeat("ç", MaxStderrExcerptSize) + "fim"
+
+	excerpt := TruncateStderr(stderr)
+
+	if len(excerpt) > MaxStderrExcerptSize {
+		t.Errorf("Esperado no máximo %d bytes, obtido %d", MaxStderrExcerptSize, len(excerpt))
+	}
+
+	if !strings.HasSuffix(excerpt, "fim") {
+		t.Error("O trecho deveria manter o final do stderr")
+	}
+
+	if !utf8.ValidString(excerpt) {
+		t.Error("O trecho deveria ser UTF-8 válido")
+	}
+}
@@ -1,11 +1,20 @@
 package entity
 
 import (
+	"errors"
+	"strings"
 	"time"
 
+	"github.com/devfullcycle/golangtechweek/internal/domain/event"
 	"github.com/google/uuid"
 )
 
+// ErrOwnerIDRequired é retornado quando um vídeo não está associado a uma conta de cliente
+var ErrOwnerIDRequired = errors.New("o vídeo deve pertencer a uma conta de cliente (owner_id obrigatório)")
+
+// ErrOriginalNotCompleted é retornado ao tentar vincular um vídeo a um original que ainda não foi convertido
+var ErrOriginalNotCompleted = errors.New("o vídeo original ainda não foi convertido")
+
 // Status do vídeo durante o ciclo de processamento
 const (
 	// StatusPending representa um vídeo que foi registrado mas ainda não começou a ser processado
@@ -28,6 +37,13 @@ const (
 	UploadStatusFailedS3    = "failed_s3"
 )
 
+// Etapas do processamento exibidas junto com o progresso da conversão
+const (
+	ProcessingStageNone        = ""
+	ProcessingStageTranscoding = "transcoding"
+	ProcessingStageFinalizing  = "finalizing"
+)
+
 const (
 	FileTypeManifest = "manifest"
 	FileTypeSegment  = "segment"
@@ -35,39 +51,103 @@ const (
 
 // Video representa a entidade de domínio para um vídeo que será processado
 type Video struct {
-	ID            string // Identificador único do vídeo
-	Title         string // Título do vídeo
-	FilePath      string // Caminho do arquivo original no sistema de arquivos
-	HLSPath       string // Caminho onde os arquivos HLS serão armazenados temporariamente
-	ManifestPath  string // Caminho do arquivo de manifesto (.m3u8)
-	S3ManifestURL string // URL do manifesto no S3
-	S3URL         string // URL final do vídeo no S3 após o upload
-	Status        string // Estado atual do vídeo
-	UploadStatus  string
-	ErrorMessage  string    // Mensagem de erro, se houver
-	CreatedAt     time.Time // Data de criação do registro
-	UpdatedAt     time.Time // Data da última atualização do registro
+	ID                    string   // Identificador único do vídeo
+	OwnerID               string   // Identificador da conta de cliente (tenant) dona do vídeo
+	Title                 string   // Título do vídeo
+	Description           string   // Descrição livre do vídeo
+	Tags                  []string // Tags livres associadas ao vídeo
+	FilePath              string   // Caminho do arquivo original no sistema de arquivos
+	ContentHash           string   // Impressão digital SHA-256 (hex) do arquivo original
+	DuplicateOfID         string   // ID do vídeo original cuja saída HLS é reaproveitada (vazio se não for duplicata)
+	HLSPath               string   // Caminho onde os arquivos HLS serão armazenados temporariamente
+	ManifestPath          string   // Caminho do arquivo de manifesto (.m3u8)
+	S3ManifestURL         string   // URL do manifesto no S3
+	S3URL                 string   // URL final do vídeo no S3 após o upload
+	Status                string   // Estado atual do vídeo
+	UploadStatus          string
+	ErrorMessage          string     // Mensagem de erro, se houver
+	Progress              int        // Progresso da conversão (0 a 100)
+	ProcessingStage       string     // Etapa atual do processamento
+	EstimatedCompletionAt *time.Time // Estimativa de término da conversão (nil quando desconhecida)
+	LeaseOwner            string     // Worker que detém a concessão de processamento do vídeo
+	LeaseExpiresAt        *time.Time // Fim da concessão; depois disso o vídeo pode ser reivindicado por outro worker
+	CreatedAt             time.Time  // Data de criação do registro
+	UpdatedAt             time.Time  // Data da última atualização do registro
+	Version               int64      // Versão do registro, incrementada a cada gravação (controle de concorrência otimista)
+
+	events []event.Event // Eventos de domínio ainda não despachados
 }
 
 // NewVideo cria uma nova instância de Video com valores padrão
-func NewVideo(title, filePath string) *Video {
+// Todo vídeo pertence a uma conta de cliente, identificada por ownerID
+func NewVideo(ownerID, title, description, filePath string, tags ...string) *Video {
 	now := time.Now()
 
-	return &Video{
+	video := &Video{
 		ID:           uuid.New().String(),
+		OwnerID:      ownerID,
 		Title:        title,
+		Description:  description,
+		Tags:         NormalizeTags(tags),
 		FilePath:     filePath,
 		Status:       StatusPending,
 		UploadStatus: UploadStatusNone,
+		Version:      1,
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
+
+	video.recordEvent(VideoRegistered{
+		VideoEvent: video.newVideoEvent(EventVideoRegistered),
+		Title:      video.Title,
+		FilePath:   video.FilePath,
+	})
+
+	return video
+}
+
+// Validate verifica se o vídeo possui os dados obrigatórios para ser persistido
+func (v *Video) Validate() error {
+	if strings.TrimSpace(v.OwnerID) == "" {
+		return ErrOwnerIDRequired
+	}
+	return nil
+}
+
+// SetDescription define a descrição do vídeo
+func (v *Video) SetDescription(description string) {
+	v.Description = description
+	v.UpdatedAt = time.Now()
+}
+
+// SetTags substitui as tags do vídeo, removendo espaços, valores vazios e duplicados
+func (v *Video) SetTags(tags []string) {
+	v.Tags = NormalizeTags(tags)
+	v.UpdatedAt = time.Now()
+}
+
+// HasTag verifica se o vídeo possui a tag informada
+func (v *Video) HasTag(tag string) bool {
+	tag = strings.TrimSpace(tag)
+	for _, t := range v.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
 }
 
 // MarkAsProcessing atualiza o status do vídeo para "processing"
 func (v *Video) MarkAsProcessing() {
 	v.Status = StatusProcessing
+	v.Progress = 0
+	v.ProcessingStage = ProcessingStageTranscoding
+	v.EstimatedCompletionAt = nil
 	v.UpdatedAt = time.Now()
+
+	v.recordEvent(VideoProcessingStarted{
+		VideoEvent: v.newVideoEvent(EventVideoProcessingStarted),
+	})
 }
 
 // MarkAsCompleted atualiza o status do vídeo para "completed"
@@ -75,13 +155,39 @@ func (v *Video) MarkAsCompleted(hslPath, manifestPath string) {
 	v.Status = StatusCompleted
 	v.HLSPath = hslPath
 	v.ManifestPath = manifestPath
+	v.Progress = 100
+	v.ProcessingStage = ProcessingStageNone
+	v.EstimatedCompletionAt = nil
+	v.releaseLease()
 	v.UpdatedAt = time.Now()
+
+	v.recordEvent(VideoProcessingCompleted{
+		VideoEvent:   v.newVideoEvent(EventVideoProcessingCompleted),
+		HLSPath:      hslPath,
+		ManifestPath: manifestPath,
+	})
 }
 
 // MarkAsFailed atualiza o status do vídeo para "failed" e registra a mensagem de erro
 func (v *Video) MarkAsFailed(errorMessage string) {
 	v.Status = StatusError
 	v.ErrorMessage = errorMessage
+	v.ProcessingStage = ProcessingStageNone
+	v.EstimatedCompletionAt = nil
+	v.releaseLease()
+	v.UpdatedAt = time.Now()
+
+	v.recordEvent(VideoProcessingFailed{
+		VideoEvent:   v.newVideoEvent(EventVideoProcessingFailed),
+		ErrorMessage: errorMessage,
+	})
+}
+
+// UpdateProgress atualiza o progresso (limitado entre 0 e 100), a etapa e a estimativa de término da conversão
+func (v *Video) UpdateProgress(progress int, stage string, estimatedCompletionAt *time.Time) {
+	v.Progress = min(max(progress, 0), 100)
+	v.ProcessingStage = stage
+	v.EstimatedCompletionAt = estimatedCompletionAt
 	v.UpdatedAt = time.Now()
 }
 
@@ -97,6 +203,101 @@ func (v *Video) SetS3ManifestURL(url string) {
 	v.UpdatedAt = time.Now()
 }
 
+// UpdateUploadStatus atualiza o status de upload para S3
+// Ao passar para "completed_s3", emite VideoUploadCompleted com as URLs já definidas no vídeo
+func (v *Video) UpdateUploadStatus(uploadStatus string) {
+	previous := v.UploadStatus
+	v.UploadStatus = uploadStatus
+	v.UpdatedAt = time.Now()
+
+	if uploadStatus == UploadStatusCompletedS3 && previous != UploadStatusCompletedS3 {
+		v.recordEvent(VideoUploadCompleted{
+			VideoEvent:    v.newVideoEvent(EventVideoUploadCompleted),
+			S3URL:         v.S3URL,
+			S3ManifestURL: v.S3ManifestURL,
+		})
+	}
+}
+
+// MarkUploadCompleted registra as URLs do S3 e atualiza o status de upload para "completed_s3"
+func (v *Video) MarkUploadCompleted(s3URL, s3ManifestURL string) {
+	v.S3URL = s3URL
+	v.S3ManifestURL = s3ManifestURL
+	v.UpdateUploadStatus(UploadStatusCompletedS3)
+}
+
+// SetContentHash define a impressão digital SHA-256 do arquivo original
+func (v *Video) SetContentHash(hash string) {
+	v.ContentHash = strings.ToLower(strings.TrimSpace(hash))
+	v.UpdatedAt = time.Now()
+}
+
+// LinkToOriginal reaproveita a saída já convertida de um vídeo com o mesmo conteúdo
+// O vídeo é marcado como concluído sem passar pela conversão e, se o original já estiver no S3, herda suas URLs
+func (v *Video) LinkToOriginal(original *Video) error {
+	if original == nil || !original.IsCompleted() {
+		return ErrOriginalNotCompleted
+	}
+
+	v.DuplicateOfID = original.ID
+	if original.DuplicateOfID != "" {
+		v.DuplicateOfID = original.DuplicateOfID
+	}
+
+	v.MarkAsCompleted(original.HLSPath, original.ManifestPath)
+
+	if original.UploadStatus == UploadStatusCompletedS3 {
+		v.MarkUploadCompleted(original.S3URL, original.S3ManifestURL)
+	}
+
+	return nil
+}
+
+// IsDuplicate verifica se o vídeo reaproveita a saída de outro vídeo
+func (v *Video) IsDuplicate() bool {
+	return v.DuplicateOfID != ""
+}
+
+// HasActiveLease verifica se algum worker detém a concessão de processamento no instante informado
+func (v *Video) HasActiveLease(now time.Time) bool {
+	return v.LeaseOwner != "" && v.LeaseExpiresAt != nil && v.LeaseExpiresAt.After(now)
+}
+
+// AcquireLease concede ao worker o processamento do vídeo até expiresAt
+func (v *Video) AcquireLease(workerID string, expiresAt time.Time) {
+	v.LeaseOwner = workerID
+	v.LeaseExpiresAt = &expiresAt
+}
+
+// IsStale verifica se o vídeo está em processamento sem sinal de vida desde staleBefore
+// Com concessão, vale o fim da concessão; sem ela, a data da última atualização
+func (v *Video) IsStale(staleBefore time.Time) bool {
+	if v.Status != StatusProcessing {
+		return false
+	}
+	if v.LeaseExpiresAt != nil {
+		return v.LeaseExpiresAt.Before(staleBefore)
+	}
+	return v.UpdatedAt.Before(staleBefore)
+}
+
+// Requeue devolve um vídeo em processamento ou com falha para a fila, descartando o erro, o progresso e a concessão
+func (v *Video) Requeue() {
+	v.Status = StatusPending
+	v.ErrorMessage = ""
+	v.Progress = 0
+	v.ProcessingStage = ProcessingStageNone
+	v.EstimatedCompletionAt = nil
+	v.releaseLease()
+	v.UpdatedAt = time.Now()
+}
+
+// releaseLease encerra a concessão de processamento ao final da conversão
+func (v *Video) releaseLease() {
+	v.LeaseOwner = ""
+	v.LeaseExpiresAt = nil
+}
+
 // IsCompleted verifica se o vídeo foi processado com sucesso
 func (v *Video) IsCompleted() bool {
 	return v.Status == StatusCompleted
@@ -116,3 +317,23 @@ func (v *Video) GetManifestPath() string {
 func (v *Video) GenerateOutputPath(baseDir string) string {
 	return baseDir + "/converted/" + v.ID
 }
+
+// NormalizeTags remove espaços nas extremidades, tags vazias e duplicadas, preservando a ordem original
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
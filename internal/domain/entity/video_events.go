@@ -0,0 +1,99 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/event"
+)
+
+// Nomes dos eventos de domínio emitidos pela entidade Video
+const (
+	EventVideoRegistered          = "video.registered"
+	EventVideoProcessingStarted   = "video.processing_started"
+	EventVideoProcessingCompleted = "video.processing_completed"
+	EventVideoProcessingFailed    = "video.processing_failed"
+	EventVideoUploadCompleted     = "video.upload_completed"
+)
+
+// VideoEvent contém os dados comuns a todos os eventos de vídeo
+type VideoEvent struct {
+	EventName string    // Nome do evento
+	VideoID   string    // ID do vídeo que originou o evento
+	OwnerID   string    // Conta de cliente dona do vídeo
+	Timestamp time.Time // Momento em que o evento ocorreu
+}
+
+// Name retorna o nome do evento
+func (e VideoEvent) Name() string {
+	return e.EventName
+}
+
+// AggregateID retorna o ID do vídeo que originou o evento
+func (e VideoEvent) AggregateID() string {
+	return e.VideoID
+}
+
+// OccurredAt retorna o momento em que o evento ocorreu
+func (e VideoEvent) OccurredAt() time.Time {
+	return e.Timestamp
+}
+
+// VideoRegistered é emitido quando um novo vídeo é registrado
+type VideoRegistered struct {
+	VideoEvent
+	Title    string
+	FilePath string
+}
+
+// VideoProcessingStarted é emitido quando a conversão do vídeo começa
+type VideoProcessingStarted struct {
+	VideoEvent
+}
+
+// VideoProcessingCompleted é emitido quando a conversão do vídeo termina com sucesso
+type VideoProcessingCompleted struct {
+	VideoEvent
+	HLSPath      string
+	ManifestPath string
+}
+
+// VideoProcessingFailed é emitido quando a conversão do vídeo falha
+type VideoProcessingFailed struct {
+	VideoEvent
+	ErrorMessage string
+}
+
+// VideoUploadCompleted é emitido quando os arquivos do vídeo terminam de ser enviados ao S3
+type VideoUploadCompleted struct {
+	VideoEvent
+	S3URL         string
+	S3ManifestURL string
+}
+
+// newVideoEvent cria os dados comuns de um evento a partir do estado atual do vídeo
+func (v *Video) newVideoEvent(name string) VideoEvent {
+	return VideoEvent{
+		EventName: name,
+		VideoID:   v.ID,
+		OwnerID:   v.OwnerID,
+		Timestamp: v.UpdatedAt,
+	}
+}
+
+// recordEvent registra um evento de domínio para ser despachado após a persistência do vídeo
+func (v *Video) recordEvent(evt event.Event) {
+	v.events = append(v.events, evt)
+}
+
+// Events retorna os eventos registrados e ainda não despachados
+func (v *Video) Events() []event.Event {
+	return v.events
+}
+
+// PullEvents retorna os eventos registrados e limpa a lista
+// Deve ser chamado somente depois que o repositório salvar o vídeo com sucesso
+func (v *Video) PullEvents() []event.Event {
+	events := v.events
+	v.events = nil
+	return events
+}
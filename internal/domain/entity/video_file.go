@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Estado do upload de cada arquivo HLS para o S3
+const (
+	// FileUploadPending representa um arquivo gerado que ainda não foi enviado
+	FileUploadPending = "pending"
+
+	// FileUploadUploading representa um arquivo sendo enviado
+	FileUploadUploading = "uploading"
+
+	// FileUploadCompleted representa um arquivo já disponível no S3
+	FileUploadCompleted = "completed"
+
+	// FileUploadFailed representa um arquivo cujo envio falhou e pode ser retomado
+	FileUploadFailed = "failed"
+)
+
+// VideoFile representa um arquivo HLS (manifesto ou segmento) gerado na conversão de um vídeo
+type VideoFile struct {
+	ID           string        // Identificador único do arquivo
+	VideoID      string        // ID do vídeo convertido
+	Type         string        // FileTypeManifest ou FileTypeSegment
+	Sequence     int           // Posição do segmento no manifesto, a partir de 0 (0 para o manifesto)
+	LocalPath    string        // Caminho do arquivo no sistema de arquivos local
+	SizeBytes    int64         // Tamanho do arquivo em bytes
+	Duration     time.Duration // Duração do segmento segundo o manifesto (0 para o manifesto)
+	Checksum     string        // SHA-256 (hex) do conteúdo do arquivo
+	S3Key        string        // Chave do arquivo no S3, preenchida após o upload
+	UploadStatus string        // Estado do upload deste arquivo
+	UploadError  string        // Mensagem da última falha de upload, se houver
+	CreatedAt    time.Time     // Data de criação do registro
+	UpdatedAt    time.Time     // Data da última atualização do registro
+}
+
+// NewVideoFile cria o registro de um arquivo gerado, com o upload pendente
+func NewVideoFile(videoID, fileType, localPath string, sequence int, sizeBytes int64, duration time.Duration, checksum string) *VideoFile {
+	now := time.Now()
+
+	return &VideoFile{
+		ID:           uuid.New().String(),
+		VideoID:      videoID,
+		Type:         fileType,
+		Sequence:     sequence,
+		LocalPath:    localPath,
+		SizeBytes:    sizeBytes,
+		Duration:     duration,
+		Checksum:     checksum,
+		UploadStatus: FileUploadPending,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// MarkUploading marca o início do envio do arquivo
+func (f *VideoFile) MarkUploading() {
+	f.UploadStatus = FileUploadUploading
+	f.UploadError = ""
+	f.UpdatedAt = time.Now()
+}
+
+// MarkUploaded marca o arquivo como enviado para a chave informada
+func (f *VideoFile) MarkUploaded(s3Key string) {
+	f.UploadStatus = FileUploadCompleted
+	f.S3Key = s3Key
+	f.UploadError = ""
+	f.UpdatedAt = time.Now()
+}
+
+// MarkUploadFailed marca a falha no envio do arquivo, guardando a mensagem de erro
+func (f *VideoFile) MarkUploadFailed(errorMessage string) {
+	f.UploadStatus = FileUploadFailed
+	f.UploadError = errorMessage
+	f.UpdatedAt = time.Now()
+}
+
+// NeedsUpload verifica se o arquivo ainda precisa ser enviado (pendente ou com falha)
+// Arquivos em envio não entram, pois outro processo pode estar enviando
+func (f *VideoFile) NeedsUpload() bool {
+	return f.UploadStatus == FileUploadPending || f.UploadStatus == FileUploadFailed
+}
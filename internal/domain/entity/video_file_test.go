@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewVideoFile(t *testing.T) {
+	file := NewVideoFile("video-123", FileTypeSegment, "/tmp/hls/playlist0.ts", 0, 1024, 10*time.Second, "abc")
+
+	if file.ID == "" {
+		t.Error("ID não deveria ser vazio")
+	}
+
+	if file.UploadStatus != FileUploadPending || !file.NeedsUpload() {
+		t.Errorf("Esperado upload pendente, obtido %s", file.UploadStatus)
+	}
+}
+
+func TestVideoFileUploadLifecycle(t *testing.T) {
+	file := NewVideoFile("video-123", FileTypeSegment, "/tmp/hls/playlist0.ts", 0, 1024, 10*time.Second, "abc")
+
+	file.MarkUploading()
+	if file.NeedsUpload() {
+		t.Error("Arquivo em envio não deveria precisar de upload")
+	}
+
+	file.MarkUploadFailed("timeout")
+	if file.UploadStatus != FileUploadFailed || file.UploadError != "timeout" || !file.NeedsUpload() {
+		t.Errorf("Esperada falha de upload retomável, obtido %s (%s)", file.UploadStatus, file.UploadError)
+	}
+
+	file.MarkUploaded("videos/video-123/playlist0.ts")
+	if file.UploadStatus != FileUploadCompleted || file.S3Key != "videos/video-123/playlist0.ts" || file.UploadError != "" {
+		t.Errorf("Esperado upload concluído, obtido %s (%s)", file.UploadStatus, file.S3Key)
+	}
+}
@@ -9,7 +9,7 @@ func NewVideTest(t *testing.T) {
 	title := "Meu Vídeo de Teste"
 	filePath := "/tmp/video.mp4"
 
-	video := NewVideo(title, filePath)
+	video := NewVideo("owner-123", title, "", filePath)
 
 	// Verifica se o ID foi gerado
 	if video.ID == "" {
@@ -67,7 +67,7 @@ func NewVideTest(t *testing.T) {
 }
 
 func TestMarkAsProcessing(t *testing.T) {
-	video := NewVideo("Test Video", "/tmp/video.mp4")
+	video := NewVideo("owner-123", "Test Video", "", "/tmp/video.mp4")
 	oldUpdatedAt := video.UpdatedAt
 
 	// Aguarda um momento para garantir que o timestamp seja diferente
@@ -85,7 +85,7 @@ func TestMarkAsProcessing(t *testing.T) {
 }
 
 func TestMarkAsCompleted(t *testing.T) {
-	video := NewVideo("Test Video", "/tmp/video.mp4")
+	video := NewVideo("owner-123", "Test Video", "", "/tmp/video.mp4")
 	oldUpdatedAt := video.UpdatedAt
 
 	// Aguarda um momento para garantir que o timestamp seja diferente
@@ -114,7 +114,7 @@ func TestMarkAsCompleted(t *testing.T) {
 }
 
 func TestMarkAsFailed(t *testing.T) {
-	video := NewVideo("Test Video", "/tmp/video.mp4")
+	video := NewVideo("owner-123", "Test Video", "", "/tmp/video.mp4")
 	oldUpdatedAt := video.UpdatedAt
 	errorMsg := "Erro ao processar vídeo"
 
@@ -137,7 +137,7 @@ func TestMarkAsFailed(t *testing.T) {
 }
 
 func TestSetS3URL(t *testing.T) {
-	video := NewVideo("Test Video", "/tmp/video.mp4")
+	video := NewVideo("owner-123", "Test Video", "", "/tmp/video.mp4")
 	oldUpdatedAt := video.UpdatedAt
 	url := "https://bucket.s3.amazonaws.com/videos/123/video.m3u8"
 
@@ -156,7 +156,7 @@ func TestSetS3URL(t *testing.T) {
 }
 
 func TestSetS3ManifestURL(t *testing.T) {
-	video := NewVideo("Test Video", "/tmp/video.mp4")
+	video := NewVideo("owner-123", "Test Video", "", "/tmp/video.mp4")
 	oldUpdatedAt := video.UpdatedAt
 	url := "https://bucket.s3.amazonaws.com/videos/123/playlist.m3u8"
 
@@ -175,7 +175,7 @@ func TestSetS3ManifestURL(t *testing.T) {
 }
 
 func TestIsCompleted(t *testing.T) {
-	video := NewVideo("Test Video", "/tmp/video.mp4")
+	video := NewVideo("owner-123", "Test Video", "", "/tmp/video.mp4")
 
 	if video.IsCompleted() {
 		t.Error("Vídeo não deveria estar completo inicialmente")
@@ -192,7 +192,7 @@ func TestIsCompleted(t *testing.T) {
 }
 
 func TestGetHLSDirectory(t *testing.T) {
-	video := NewVideo("Test Video", "/tmp/video.mp4")
+	video := NewVideo("owner-123", "Test Video", "", "/tmp/video.mp4")
 	hlsPath := "/tmp/output/123"
 	video.HLSPath = hlsPath
 
@@ -202,7 +202,7 @@ func TestGetHLSDirectory(t *testing.T) {
 }
 
 func TestGetManifestPath(t *testing.T) {
-	video := NewVideo("Test Video", "/tmp/video.mp4")
+	video := NewVideo("owner-123", "Test Video", "", "/tmp/video.mp4")
 	manifestPath := "/tmp/output/123/playlist.m3u8"
 	video.ManifestPath = manifestPath
 
@@ -212,7 +212,7 @@ func TestGetManifestPath(t *testing.T) {
 }
 
 func TestGenerateOutputPath(t *testing.T) {
-	video := NewVideo("Test Video", "/tmp/video.mp4")
+	video := NewVideo("owner-123", "Test Video", "", "/tmp/video.mp4")
 	baseDir := "/tmp/output"
 
 	oldUpdatedAt := video.UpdatedAt
@@ -241,3 +241,276 @@ func TestGenerateOutputPath(t *testing.T) {
 		t.Error("UpdatedAt não deveria ter sido atualizado")
 	}
 }
+
+func TestNewVideoWithMetadata(t *testing.T) {
+	video := NewVideo("owner-123", "Test Video", "Uma descrição", "/tmp/video.mp4", " golang ", "", "tech", "golang")
+
+	if video.OwnerID != "owner-123" {
+		t.Errorf("Esperado OwnerID %s, obtido %s", "owner-123", video.OwnerID)
+	}
+
+	if video.Description != "Uma descrição" {
+		t.Errorf("Esperado Description %s, obtido %s", "Uma descrição", video.Description)
+	}
+
+	// Tags devem ser normalizadas: sem espaços, vazias ou duplicadas
+	expectedTags := []string{"golang", "tech"}
+	if len(video.Tags) != len(expectedTags) {
+		t.Fatalf("Esperado %d tags, obtido %d (%v)", len(expectedTags), len(video.Tags), video.Tags)
+	}
+	for i, tag := range expectedTags {
+		if video.Tags[i] != tag {
+			t.Errorf("Esperado tag %s na posição %d, obtido %s", tag, i, video.Tags[i])
+		}
+	}
+
+	if !video.HasTag("tech") {
+		t.Error("Vídeo deveria possuir a tag tech")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	video := NewVideo("owner-123", "Test Video", "", "/tmp/video.mp4")
+	if err := video.Validate(); err != nil {
+		t.Errorf("Não esperava erro, obtido %v", err)
+	}
+
+	video.OwnerID = "  "
+	if err := video.Validate(); err != ErrOwnerIDRequired {
+		t.Errorf("Esperado erro %v, obtido %v", ErrOwnerIDRequired, err)
+	}
+}
+
+func TestSetTags(t *testing.T) {
+	video := NewVideo("owner-123", "Test Video", "", "/tmp/video.mp4")
+	oldUpdatedAt := video.UpdatedAt
+
+	// Aguarda um momento para garantir que o timestamp seja diferente
+	time.Sleep(1 * time.Millisecond)
+
+	video.SetTags([]string{"a", "b", "a"})
+
+	if len(video.Tags) != 2 {
+		t.Errorf("Esperado 2 tags, obtido %v", video.Tags)
+	}
+
+	if !video.UpdatedAt.After(oldUpdatedAt) {
+		t.Error("UpdatedAt deveria ter sido atualizado")
+	}
+}
+
+func TestVideoEvents(t *testing.T) {
+	video := NewVideo("owner-123", "Test Video", "", "/tmp/video.mp4")
+
+	video.MarkAsProcessing()
+	video.MarkAsCompleted("/tmp/output/123", "/tmp/output/123/playlist.m3u8")
+	video.MarkUploadCompleted("https://bucket/123", "https://bucket/123/playlist.m3u8")
+
+	events := video.PullEvents()
+	expected := []string{
+		EventVideoRegistered,
+		EventVideoProcessingStarted,
+		EventVideoProcessingCompleted,
+		EventVideoUploadCompleted,
+	}
+
+	if len(events) != len(expected) {
+		t.Fatalf("Esperado %d eventos, obtido %d", len(expected), len(events))
+	}
+
+	for i, name := range expected {
+		if events[i].Name() != name {
+			t.Errorf("Esperado evento %s na posição %d, obtido %s", name, i, events[i].Name())
+		}
+		if events[i].AggregateID() != video.ID {
+			t.Errorf("Esperado AggregateID %s, obtido %s", video.ID, events[i].AggregateID())
+		}
+	}
+
+	// PullEvents deve limpar a lista de eventos pendentes
+	if len(video.Events()) != 0 {
+		t.Errorf("Esperado nenhum evento pendente, obtido %d", len(video.Events()))
+	}
+
+	if video.UploadStatus != UploadStatusCompletedS3 {
+		t.Errorf("Esperado UploadStatus %s, obtido %s", UploadStatusCompletedS3, video.UploadStatus)
+	}
+}
+
+func TestMarkAsFailedRecordsEvent(t *testing.T) {
+	video := NewVideo("owner-123", "Test Video", "", "/tmp/video.mp4")
+	video.PullEvents()
+
+	video.MarkAsFailed("erro")
+
+	events := video.PullEvents()
+	if len(events) != 1 {
+		t.Fatalf("Esperado 1 evento, obtido %d", len(events))
+	}
+
+	failed, ok := events[0].(VideoProcessingFailed)
+	if !ok {
+		t.Fatalf("Esperado evento VideoProcessingFailed, obtido %T", events[0])
+	}
+
+	if failed.ErrorMessage != "erro" {
+		t.Errorf("Esperado ErrorMessage %s, obtido %s", "erro", failed.ErrorMessage)
+	}
+}
+
+func TestUpdateUploadStatusRecordsEventOnCompletion(t *testing.T) {
+	video := NewVideo("owner-123", "Test Video", "", "/tmp/video.mp4")
+	video.SetS3URL("https://bucket/123")
+	video.SetS3ManifestURL("https://bucket/123/playlist.m3u8")
+	video.PullEvents()
+
+	video.UpdateUploadStatus(UploadStatusUploadingS3)
+	if len(video.Events()) != 0 {
+		t.Fatalf("Esperado nenhum evento antes da conclusão, obtido %d", len(video.Events()))
+	}
+
+	video.UpdateUploadStatus(UploadStatusCompletedS3)
+	video.UpdateUploadStatus(UploadStatusCompletedS3)
+
+	events := video.PullEvents()
+	if len(events) != 1 {
+		t.Fatalf("Esperado 1 evento, obtido %d", len(events))
+	}
+
+	completed, ok := events[0].(VideoUploadCompleted)
+	if !ok {
+		t.Fatalf("Esperado evento VideoUploadCompleted, obtido %T", events[0])
+	}
+
+	if completed.S3ManifestURL != "https://bucket/123/playlist.m3u8" {
+		t.Errorf("Esperado S3ManifestURL %s, obtido %s", "https://bucket/123/playlist.m3u8", completed.S3ManifestURL)
+	}
+}
+
+func TestUpdateProgress(t *testing.T) {
+	video := NewVideo("owner-123", "Test Video", "", "/tmp/video.mp4")
+	video.MarkAsProcessing()
+	video.UpdatedAt = time.Now().Add(-time.Hour)
+	eta := time.Now().Add(time.Minute)
+
+	video.UpdateProgress(150, ProcessingStageTranscoding, &eta)
+
+	// O progresso é uma modificação do vídeo
+	if time.Since(video.UpdatedAt) > time.Minute {
+		t.Error("UpdatedAt deveria ter sido atualizado")
+	}
+
+	// O progresso deve ser limitado a 100
+	if video.Progress != 100 {
+		t.Errorf("Esperado Progress 100, obtido %d", video.Progress)
+	}
+
+	if video.ProcessingStage != ProcessingStageTranscoding {
+		t.Errorf("Esperado ProcessingStage %s, obtido %s", ProcessingStageTranscoding, video.ProcessingStage)
+	}
+
+	if video.EstimatedCompletionAt == nil || !video.EstimatedCompletionAt.Equal(eta) {
+		t.Error("EstimatedCompletionAt deveria ter sido atualizado")
+	}
+
+	video.MarkAsCompleted("/tmp/output/123", "/tmp/output/123/playlist.m3u8")
+
+	if video.Progress != 100 || video.ProcessingStage != ProcessingStageNone || video.EstimatedCompletionAt != nil {
+		t.Error("MarkAsCompleted deveria concluir o progresso e limpar a etapa e a estimativa")
+	}
+}
+
+func TestLinkToOriginal(t *testing.T) {
+	original := NewVideo("owner-123", "Original", "", "/tmp/original.mp4")
+	duplicate := NewVideo("owner-123", "Cópia", "", "/tmp/copia.mp4")
+	duplicate.PullEvents()
+
+	// Um original ainda não convertido não pode ser reaproveitado
+	if err := duplicate.LinkToOriginal(original); err != ErrOriginalNotCompleted {
+		t.Errorf("Esperado ErrOriginalNotCompleted, obtido %v", err)
+	}
+
+	original.MarkAsCompleted("/tmp/output/original", "/tmp/output/original/playlist.m3u8")
+	original.MarkUploadCompleted("https://bucket.s3/original", "https://bucket.s3/original/playlist.m3u8")
+
+	if err := duplicate.LinkToOriginal(original); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if duplicate.DuplicateOfID != original.ID || !duplicate.IsDuplicate() {
+		t.Errorf("Esperado DuplicateOfID %s, obtido %s", original.ID, duplicate.DuplicateOfID)
+	}
+
+	if !duplicate.IsCompleted() || duplicate.ManifestPath != original.ManifestPath {
+		t.Error("A duplicata deveria estar concluída com a saída HLS do original")
+	}
+
+	if duplicate.UploadStatus != UploadStatusCompletedS3 || duplicate.S3ManifestURL != original.S3ManifestURL {
+		t.Error("A duplicata deveria herdar as URLs do S3 do original")
+	}
+
+	// Vincular a uma duplicata aponta para o original, sem criar cadeias
+	third := NewVideo("owner-123", "Terceira", "", "/tmp/terceira.mp4")
+	if err := third.LinkToOriginal(duplicate); err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if third.DuplicateOfID != original.ID {
+		t.Errorf("Esperado DuplicateOfID %s, obtido %s", original.ID, third.DuplicateOfID)
+	}
+}
+
+func TestLeaseEndsWithProcessing(t *testing.T) {
+	video := NewVideo("owner-123", "Test Video", "", "/tmp/video.mp4")
+	now := time.Now()
+	expiresAt := now.Add(time.Minute)
+	video.AcquireLease("worker-a", expiresAt)
+
+	if !video.HasActiveLease(now) {
+		t.Error("A concessão deveria estar ativa")
+	}
+
+	if video.HasActiveLease(expiresAt.Add(time.Second)) {
+		t.Error("A concessão não deveria estar ativa após expirar")
+	}
+
+	video.MarkAsFailed("erro")
+
+	if video.LeaseOwner != "" || video.LeaseExpiresAt != nil {
+		t.Error("MarkAsFailed deveria encerrar a concessão")
+	}
+}
+
+func TestVideoIsStaleAndRequeue(t *testing.T) {
+	now := time.Now()
+
+	video := NewVideo("owner-1", "Vídeo", "", "/path/to/video.mp4")
+	video.UpdatedAt = now.Add(-time.Hour)
+
+	if video.IsStale(now) {
+		t.Error("Vídeo pendente não deveria ser considerado abandonado")
+	}
+
+	video.MarkAsProcessing()
+	video.UpdatedAt = now.Add(-time.Hour)
+
+	if !video.IsStale(now.Add(-time.Minute)) {
+		t.Error("Vídeo sem atualização desde o limite deveria ser considerado abandonado")
+	}
+
+	// A concessão, quando existe, prevalece sobre a data de atualização
+	expiresAt := now.Add(time.Minute)
+	video.LeaseOwner = "worker-a"
+	video.LeaseExpiresAt = &expiresAt
+
+	if video.IsStale(now) {
+		t.Error("Vídeo com concessão ativa não deveria ser considerado abandonado")
+	}
+
+	video.Progress = 40
+	video.Requeue()
+
+	if video.Status != StatusPending || video.Progress != 0 || video.LeaseOwner != "" || video.LeaseExpiresAt != nil {
+		t.Errorf("Requeue deveria devolver o vídeo para a fila, obtido %s com progresso %d", video.Status, video.Progress)
+	}
+}
@@ -0,0 +1,65 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// AllEvents é o nome usado para assinar todos os eventos, independentemente do nome
+const AllEvents = "*"
+
+// EventDispatcher implementa a interface Dispatcher entregando os eventos em processo (in-process)
+// Os handlers são executados de forma síncrona, na ordem em que foram registrados
+type EventDispatcher struct {
+	handlers map[string][]Handler
+	mutex    sync.RWMutex
+}
+
+// NewEventDispatcher cria uma nova instância de EventDispatcher
+func NewEventDispatcher() *EventDispatcher {
+	return &EventDispatcher{
+		handlers: make(map[string][]Handler),
+	}
+}
+
+// Subscribe registra um handler para o evento com o nome informado
+func (d *EventDispatcher) Subscribe(name string, handler Handler) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.handlers[name] = append(d.handlers[name], handler)
+}
+
+// Dispatch entrega cada evento aos handlers do seu nome e aos handlers de AllEvents
+// A falha de um handler não impede a entrega aos demais; os erros são agregados no retorno
+func (d *EventDispatcher) Dispatch(ctx context.Context, events ...Event) error {
+	var errs []error
+
+	for _, evt := range events {
+		for _, handler := range d.handlersFor(evt.Name()) {
+			if err := handler(ctx, evt); err != nil {
+				errs = append(errs, fmt.Errorf("erro ao processar evento %s: %w", evt.Name(), err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// handlersFor retorna uma cópia dos handlers que devem receber o evento
+// A cópia permite que handlers registrem novos assinantes sem causar deadlock
+func (d *EventDispatcher) handlersFor(name string) []Handler {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	handlers := make([]Handler, 0, len(d.handlers[name])+len(d.handlers[AllEvents]))
+	handlers = append(handlers, d.handlers[name]...)
+	handlers = append(handlers, d.handlers[AllEvents]...)
+
+	return handlers
+}
+
+// Ensure EventDispatcher implements Dispatcher
+var _ Dispatcher = (*EventDispatcher)(nil)
@@ -0,0 +1,63 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testEvent é um evento simples usado nos testes do dispatcher
+type testEvent struct {
+	name string
+	id   string
+}
+
+func (e testEvent) Name() string          { return e.name }
+func (e testEvent) AggregateID() string   { return e.id }
+func (e testEvent) OccurredAt() time.Time { return time.Time{} }
+
+func TestEventDispatcher_Dispatch(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	var byName, all []string
+	dispatcher.Subscribe("video.registered", func(ctx context.Context, evt Event) error {
+		byName = append(byName, evt.AggregateID())
+		return nil
+	})
+	dispatcher.Subscribe(AllEvents, func(ctx context.Context, evt Event) error {
+		all = append(all, evt.Name())
+		return nil
+	})
+
+	err := dispatcher.Dispatch(context.Background(),
+		testEvent{name: "video.registered", id: "1"},
+		testEvent{name: "video.processing_started", id: "1"},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1"}, byName)
+	assert.Equal(t, []string{"video.registered", "video.processing_started"}, all)
+}
+
+func TestEventDispatcher_DispatchHandlerError(t *testing.T) {
+	dispatcher := NewEventDispatcher()
+
+	var delivered int
+	dispatcher.Subscribe("video.registered", func(ctx context.Context, evt Event) error {
+		return errors.New("falha no assinante")
+	})
+	dispatcher.Subscribe("video.registered", func(ctx context.Context, evt Event) error {
+		delivered++
+		return nil
+	})
+
+	err := dispatcher.Dispatch(context.Background(), testEvent{name: "video.registered", id: "1"})
+
+	// A falha de um handler não deve impedir a entrega aos demais
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "falha no assinante")
+	assert.Equal(t, 1, delivered)
+}
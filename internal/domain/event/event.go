@@ -0,0 +1,32 @@
+package event
+
+import (
+	"context"
+	"time"
+)
+
+// Event representa um evento de domínio, ou seja, algo relevante que já aconteceu
+type Event interface {
+	// Name retorna o nome do evento (ex: "video.registered")
+	Name() string
+
+	// AggregateID retorna o ID da entidade que originou o evento
+	AggregateID() string
+
+	// OccurredAt retorna o momento em que o evento ocorreu
+	OccurredAt() time.Time
+}
+
+// Handler define a função executada quando um evento é entregue a um assinante
+type Handler func(ctx context.Context, evt Event) error
+
+// Dispatcher define as operações para entregar eventos de domínio aos assinantes
+type Dispatcher interface {
+	// Subscribe registra um handler para o evento com o nome informado
+	// Use AllEvents para receber todos os eventos
+	Subscribe(name string, handler Handler)
+
+	// Dispatch entrega os eventos a todos os handlers registrados
+	// Retorna um erro se algum handler falhar
+	Dispatch(ctx context.Context, events ...Event) error
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+)
+
+// ProcessingAttemptRepository define as operações sobre o histórico de tentativas de processamento de vídeos
+type ProcessingAttemptRepository interface {
+	// Create persiste uma nova tentativa de processamento
+	// Retorna um erro se a operação falhar
+	Create(ctx context.Context, attempt *entity.ProcessingAttempt) error
+
+	// Finish persiste o resultado de uma tentativa (término, resultado, erro e stderr)
+	// Retorna um erro se a tentativa não existir ou se a operação falhar
+	Finish(ctx context.Context, attempt *entity.ProcessingAttempt) error
+
+	// ListByVideoID retorna as tentativas de um vídeo, da mais antiga para a mais recente
+	// Retorna a lista de tentativas ou um erro se a operação falhar
+	ListByVideoID(ctx context.Context, videoID string) ([]*entity.ProcessingAttempt, error)
+}
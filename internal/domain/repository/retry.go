@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+)
+
+// DefaultRetryAttempts é o número padrão de tentativas de RetryOnConflict
+const DefaultRetryAttempts = 3
+
+// VideoUpdater é o subconjunto do repositório necessário para um ciclo de leitura-modificação-gravação
+type VideoUpdater interface {
+	FindByID(ctx context.Context, id string) (*entity.Video, error)
+	Update(ctx context.Context, video *entity.Video) error
+}
+
+// RetryOnConflict lê o vídeo, aplica mutate e grava com Update, repetindo o ciclo com uma leitura nova
+// enquanto a gravação falhar com ErrConcurrentModification
+// mutate pode ser chamada mais de uma vez e deve depender apenas do vídeo recebido
+// Retorna o vídeo gravado ou ErrConcurrentModification se todas as tentativas conflitarem
+func RetryOnConflict(ctx context.Context, repo VideoUpdater, id string, attempts int, mutate func(video *entity.Video) error) (*entity.Video, error) {
+	if attempts <= 0 {
+		attempts = DefaultRetryAttempts
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+
+		var video *entity.Video
+		video, err = repo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = mutate(video); err != nil {
+			return nil, err
+		}
+
+		err = repo.Update(ctx, video)
+		if err == nil {
+			return video, nil
+		}
+		if !errors.Is(err, ErrConcurrentModification) {
+			return nil, err
+		}
+	}
+
+	return nil, err
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+)
+
+// conflictingUpdater simula um repositório em que as primeiras gravações conflitam com outro processo
+type conflictingUpdater struct {
+	video     *entity.Video
+	conflicts int
+	reads     int
+	err       error
+}
+
+func (u *conflictingUpdater) FindByID(ctx context.Context, id string) (*entity.Video, error) {
+	u.reads++
+	video := *u.video
+	return &video, nil
+}
+
+func (u *conflictingUpdater) Update(ctx context.Context, video *entity.Video) error {
+	if u.err != nil {
+		return u.err
+	}
+	if u.conflicts > 0 {
+		u.conflicts--
+		return ErrConcurrentModification
+	}
+	video.Version++
+	u.video = video
+	return nil
+}
+
+func TestRetryOnConflict(t *testing.T) {
+	updater := &conflictingUpdater{video: entity.NewVideo("owner-123", "Vídeo", "", "/tmp/video.mp4"), conflicts: 2}
+
+	video, err := RetryOnConflict(context.Background(), updater, updater.video.ID, 3, func(v *entity.Video) error {
+		v.SetDescription("nova descrição")
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if updater.reads != 3 {
+		t.Errorf("Esperadas 3 leituras, obtido %d", updater.reads)
+	}
+
+	if video.Description != "nova descrição" || video.Version != 2 {
+		t.Errorf("Gravação inesperada: descrição %q, versão %d", video.Description, video.Version)
+	}
+}
+
+func TestRetryOnConflictGivesUp(t *testing.T) {
+	updater := &conflictingUpdater{video: entity.NewVideo("owner-123", "Vídeo", "", "/tmp/video.mp4"), conflicts: 5}
+
+	_, err := RetryOnConflict(context.Background(), updater, updater.video.ID, 2, func(v *entity.Video) error {
+		return nil
+	})
+
+	if !errors.Is(err, ErrConcurrentModification) {
+		t.Errorf("Esperado ErrConcurrentModification, obtido %v", err)
+	}
+
+	if updater.reads != 2 {
+		t.Errorf("Esperadas 2 leituras, obtido %d", updater.reads)
+	}
+}
+
+func TestRetryOnConflictStopsOnOtherErrors(t *testing.T) {
+	mutateErr := errors.New("alteração inválida")
+	updater := &conflictingUpdater{video: entity.NewVideo("owner-123", "Vídeo", "", "/tmp/video.mp4")}
+
+	_, err := RetryOnConflict(context.Background(), updater, updater.video.ID, 3, func(v *entity.Video) error {
+		return mutateErr
+	})
+
+	if !errors.Is(err, mutateErr) || updater.reads != 1 {
+		t.Errorf("Esperado erro da alteração após 1 leitura, obtido %v após %d", err, updater.reads)
+	}
+
+	updater.err = ErrVideoNotFound
+	_, err = RetryOnConflict(context.Background(), updater, updater.video.ID, 3, func(v *entity.Video) error {
+		return nil
+	})
+
+	if !errors.Is(err, ErrVideoNotFound) || updater.reads != 2 {
+		t.Errorf("Esperado ErrVideoNotFound sem novas tentativas, obtido %v", err)
+	}
+}
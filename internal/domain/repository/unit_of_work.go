@@ -0,0 +1,19 @@
+package repository
+
+import "context"
+
+// Repositories agrupa os repositórios disponíveis dentro de uma unidade de trabalho
+// Novos repositórios devem ser adicionados aqui para participar das transações
+type Repositories struct {
+	Videos             VideoRepository
+	ProcessingAttempts ProcessingAttemptRepository
+	VideoFiles         VideoFileRepository
+}
+
+// UnitOfWork executa várias operações de repositório de forma atômica
+type UnitOfWork interface {
+	// RunInTx executa fn dentro de uma transação, confirmada se fn retornar nil e desfeita caso contrário
+	// Os repositórios recebidos, e qualquer repositório chamado com o ctx recebido, participam da mesma transação
+	// Chamadas aninhadas de RunInTx reaproveitam a transação em andamento
+	RunInTx(ctx context.Context, fn func(ctx context.Context, repos Repositories) error) error
+}
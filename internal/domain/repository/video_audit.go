@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+)
+
+// AuditAction identifica a operação de repositório que alterou o vídeo
+type AuditAction string
+
+const (
+	AuditActionCreate         AuditAction = "create"
+	AuditActionUpdate         AuditAction = "update"
+	AuditActionUpdateStatus   AuditAction = "update_status"
+	AuditActionUpdateProgress AuditAction = "update_progress"
+	AuditActionUpdateHLSPath  AuditAction = "update_hls_path"
+	AuditActionUpdateS3Status AuditAction = "update_s3_status"
+	AuditActionUpdateS3URLs   AuditAction = "update_s3_urls"
+	AuditActionUpdateS3Keys   AuditAction = "update_s3_keys"
+	AuditActionClaim          AuditAction = "claim"
+	AuditActionRenewLease     AuditAction = "renew_lease"
+	AuditActionReleaseLease   AuditAction = "release_lease"
+	AuditActionRequeue        AuditAction = "requeue"
+	AuditActionDelete         AuditAction = "delete"
+	AuditActionRestore        AuditAction = "restore"
+	AuditActionPurgeStart     AuditAction = "purge_start"
+	AuditActionPurge          AuditAction = "purge"
+)
+
+// ActorType identifica o tipo de quem executou a alteração
+type ActorType string
+
+const (
+	ActorTypeAPIKey ActorType = "api_key"
+	ActorTypeWorker ActorType = "worker"
+	ActorTypeSystem ActorType = "system"
+)
+
+// Actor é quem executou a alteração: uma chave de API, um worker ou o próprio sistema
+type Actor struct {
+	Type ActorType
+	ID   string
+}
+
+// SystemActor é o ator registrado quando o contexto não informa nenhum
+var SystemActor = Actor{Type: ActorTypeSystem, ID: "system"}
+
+// APIKeyActor retorna o ator de uma requisição autenticada pela chave de API informada
+func APIKeyActor(keyID string) Actor {
+	return Actor{Type: ActorTypeAPIKey, ID: keyID}
+}
+
+// WorkerActor retorna o ator de um worker de conversão
+func WorkerActor(workerID string) Actor {
+	return Actor{Type: ActorTypeWorker, ID: workerID}
+}
+
+type actorKey struct{}
+
+type correlationIDKey struct{}
+
+// WithActor retorna um contexto cujas alterações serão atribuídas ao ator informado
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext retorna o ator do contexto, ou SystemActor se não houver
+func ActorFromContext(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorKey{}).(Actor); ok {
+		return actor
+	}
+	return SystemActor
+}
+
+// WithCorrelationID retorna um contexto cujas alterações serão agrupadas pelo ID de correlação informado
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
+// CorrelationIDFromContext retorna o ID de correlação do contexto, ou vazio se não houver
+func CorrelationIDFromContext(ctx context.Context) string {
+	correlationID, _ := ctx.Value(correlationIDKey{}).(string)
+	return correlationID
+}
+
+// jsonNull representa um valor desconhecido ou ausente em FieldChange
+var jsonNull = json.RawMessage("null")
+
+// FieldChange guarda os valores de um campo antes e depois da alteração, em JSON
+// Old é o JSON null quando o valor anterior não é conhecido (ex.: criação do vídeo)
+type FieldChange struct {
+	Old json.RawMessage `json:"old"`
+	New json.RawMessage `json:"new"`
+}
+
+// VideoAuditEntry é um registro do histórico de alterações de um vídeo
+type VideoAuditEntry struct {
+	ID            int64 // Sequencial; entradas mais novas têm IDs maiores
+	VideoID       string
+	Action        AuditAction
+	Changes       map[string]FieldChange // Campos alterados, pelo nome da coluna
+	Actor         Actor
+	CorrelationID string
+	CreatedAt     time.Time
+}
+
+// VideoAuditRepository grava e consulta o histórico de alterações dos vídeos
+type VideoAuditRepository interface {
+	// Append grava uma nova entrada, preenchendo ID e CreatedAt
+	Append(ctx context.Context, entry *VideoAuditEntry) error
+
+	// History retorna o histórico do vídeo, da entrada mais nova para a mais antiga
+	// beforeID restringe às entradas com ID menor (0 para começar pela mais nova);
+	// para a página seguinte, use o ID da última entrada retornada
+	// limit segue DefaultListLimit e MaxListLimit
+	History(ctx context.Context, videoID string, beforeID int64, limit int) ([]*VideoAuditEntry, error)
+}
+
+// auditedFields lista os campos do vídeo comparados por VideoChanges
+var auditedFields = []struct {
+	name  string
+	value func(v *entity.Video) any
+}{
+	{"title", func(v *entity.Video) any { return v.Title }},
+	{"description", func(v *entity.Video) any { return v.Description }},
+	{"tags", func(v *entity.Video) any { return append([]string{}, v.Tags...) }},
+	{"file_path", func(v *entity.Video) any { return v.FilePath }},
+	{"content_hash", func(v *entity.Video) any { return v.ContentHash }},
+	{"duplicate_of", func(v *entity.Video) any { return v.DuplicateOfID }},
+	{"status", func(v *entity.Video) any { return v.Status }},
+	{"upload_status", func(v *entity.Video) any { return v.UploadStatus }},
+	{"hls_path", func(v *entity.Video) any { return v.HLSPath }},
+	{"manifest_path", func(v *entity.Video) any { return v.ManifestPath }},
+	{"s3_url", func(v *entity.Video) any { return v.S3URL }},
+	{"s3_manifest_url", func(v *entity.Video) any { return v.S3ManifestURL }},
+	{"error_message", func(v *entity.Video) any { return v.ErrorMessage }},
+	{"progress", func(v *entity.Video) any { return v.Progress }},
+	{"processing_stage", func(v *entity.Video) any { return v.ProcessingStage }},
+	{"estimated_completion_at", func(v *entity.Video) any { return v.EstimatedCompletionAt }},
+	{"lease_owner", func(v *entity.Video) any { return v.LeaseOwner }},
+	{"lease_expires_at", func(v *entity.Video) any { return v.LeaseExpiresAt }},
+}
+
+// VideoChanges compara dois estados do vídeo e retorna os campos que mudaram
+// Com before nil (criação), retorna os campos preenchidos de after com Old nulo;
+// com after nil (exclusão), não há campos a comparar
+func VideoChanges(before, after *entity.Video) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+	if after == nil {
+		return changes
+	}
+
+	reference := before
+	if reference == nil {
+		reference = &entity.Video{}
+	}
+
+	for _, field := range auditedFields {
+		oldValue, _ := json.Marshal(field.value(reference))
+		newValue, _ := json.Marshal(field.value(after))
+		if bytes.Equal(oldValue, newValue) {
+			continue
+		}
+
+		change := FieldChange{Old: jsonNull, New: newValue}
+		if before != nil {
+			change.Old = oldValue
+		}
+		changes[field.name] = change
+	}
+
+	return changes
+}
+
+// NewFieldChange cria a alteração de um campo a partir dos valores informados
+// Valores nil são gravados como JSON null
+func NewFieldChange(oldValue, newValue any) FieldChange {
+	change := FieldChange{Old: jsonNull, New: jsonNull}
+	if oldValue != nil {
+		change.Old, _ = json.Marshal(oldValue)
+	}
+	if newValue != nil {
+		change.New, _ = json.Marshal(newValue)
+	}
+	return change
+}
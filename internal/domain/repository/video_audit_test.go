@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+)
+
+func TestVideoChanges(t *testing.T) {
+	before := entity.NewVideo("owner-123", "Meu Vídeo", "", "/tmp/video.mp4", "golang")
+	after := *before
+	after.Status = entity.StatusProcessing
+	after.Progress = 40
+	after.Tags = []string{"golang"}
+
+	changes := VideoChanges(before, &after)
+
+	if len(changes) != 2 {
+		t.Fatalf("Esperadas 2 alterações, obtido %v", changes)
+	}
+	if string(changes["status"].Old) != `"pending"` || string(changes["status"].New) != `"processing"` {
+		t.Errorf("Alteração de status inesperada: %s -> %s", changes["status"].Old, changes["status"].New)
+	}
+	if string(changes["progress"].Old) != `0` || string(changes["progress"].New) != `40` {
+		t.Errorf("Alteração de progresso inesperada: %s -> %s", changes["progress"].Old, changes["progress"].New)
+	}
+}
+
+func TestVideoChangesCreateAndDelete(t *testing.T) {
+	video := entity.NewVideo("owner-123", "Meu Vídeo", "", "/tmp/video.mp4")
+
+	changes := VideoChanges(nil, video)
+	if _, ok := changes["description"]; ok {
+		t.Error("Campos vazios não devem ser registrados na criação")
+	}
+	if string(changes["title"].Old) != "null" || string(changes["title"].New) != `"Meu Vídeo"` {
+		t.Errorf("Esperado título sem valor anterior, obtido %s -> %s", changes["title"].Old, changes["title"].New)
+	}
+
+	if changes := VideoChanges(video, nil); len(changes) != 0 {
+		t.Errorf("Esperado nenhum campo na exclusão, obtido %v", changes)
+	}
+}
+
+func TestAuditContext(t *testing.T) {
+	ctx := context.Background()
+
+	if ActorFromContext(ctx) != SystemActor {
+		t.Errorf("Esperado SystemActor por padrão, obtido %+v", ActorFromContext(ctx))
+	}
+
+	ctx = WithCorrelationID(WithActor(ctx, APIKeyActor("key-1")), "req-1")
+
+	if actor := ActorFromContext(ctx); actor.Type != ActorTypeAPIKey || actor.ID != "key-1" {
+		t.Errorf("Ator inesperado: %+v", actor)
+	}
+	if CorrelationIDFromContext(ctx) != "req-1" {
+		t.Errorf("Esperado ID de correlação req-1, obtido %s", CorrelationIDFromContext(ctx))
+	}
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+)
+
+// ErrVideoNotFailed é o resultado de RequeueFailed para um vídeo informado que não está com falha
+var ErrVideoNotFailed = errors.New("o vídeo não está com falha")
+
+// BulkResult é o resultado de uma operação em lote para um vídeo
+type BulkResult struct {
+	ID  string
+	Err error // nil se a operação foi aplicada ao vídeo
+}
+
+// BulkSucceeded retorna os IDs dos vídeos em que a operação foi aplicada, na ordem dos resultados
+func BulkSucceeded(results []BulkResult) []string {
+	var ids []string
+	for _, result := range results {
+		if result.Err == nil {
+			ids = append(ids, result.ID)
+		}
+	}
+	return ids
+}
+
+// RequeueFailedFilter seleciona os vídeos com falha que RequeueFailed devolve para a fila
+// Campos vazios não restringem o resultado
+type RequeueFailedFilter struct {
+	OwnerID      string    // Apenas vídeos da conta de cliente informada
+	VideoIDs     []string  // Apenas os vídeos informados; cada um recebe um resultado, mesmo que não possa voltar para a fila
+	FailedBefore time.Time // Apenas vídeos que falharam (última atualização) antes deste instante
+	Limit        int       // Máximo de vídeos devolvidos para a fila; ignorado quando VideoIDs é informado
+}
+
+// VideoBulkOperations agrupa as operações sobre muitos vídeos de uma vez
+// Cada operação é atômica: os vídeos com resultado sem erro são gravados juntos, na mesma transação,
+// e um erro de infraestrutura (o erro retornado) desfaz a operação inteira
+// Os resultados seguem a ordem dos vídeos ou IDs informados
+type VideoBulkOperations interface {
+	// FindManyByID busca os vídeos pelos IDs, na ordem informada; IDs inexistentes ou excluídos são ignorados
+	FindManyByID(ctx context.Context, ids []string) ([]*entity.Video, error)
+
+	// CreateMany persiste os vídeos com um único comando por lote de linhas
+	// Vídeos inválidos recebem o erro de validação e IDs já usados (ou repetidos na lista), ErrVideoAlreadyExists
+	CreateMany(ctx context.Context, videos []*entity.Video) ([]BulkResult, error)
+
+	// UpdateStatusMany aplica a cada vídeo o mesmo que UpdateStatus(id, status, "")
+	// IDs inexistentes recebem ErrVideoNotFound
+	UpdateStatusMany(ctx context.Context, ids []string, status string) ([]BulkResult, error)
+
+	// RequeueFailed devolve para a fila ("pending") os vídeos com falha que atendem ao filtro,
+	// descartando a mensagem de erro, o progresso e a concessão
+	// Com filter.VideoIDs, os IDs informados que não estão com falha recebem ErrVideoNotFailed e os
+	// inexistentes, ErrVideoNotFound; sem eles, retorna apenas os vídeos devolvidos, em ordem indefinida
+	RequeueFailed(ctx context.Context, filter RequeueFailedFilter) ([]BulkResult, error)
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+)
+
+// ErrVideoFileNotFound é retornado quando o arquivo do vídeo não existe
+var ErrVideoFileNotFound = errors.New("arquivo do vídeo não encontrado")
+
+// VideoFileRepository define as operações sobre os arquivos HLS gerados para cada vídeo
+type VideoFileRepository interface {
+	// ReplaceForVideo substitui todos os arquivos registrados do vídeo pelos informados
+	// Usado ao concluir uma conversão, descartando os arquivos de conversões anteriores
+	// Retorna um erro se a operação falhar
+	ReplaceForVideo(ctx context.Context, videoID string, files []*entity.VideoFile) error
+
+	// ListByVideoID retorna os arquivos do vídeo: o manifesto primeiro e depois os segmentos, em ordem
+	// Retorna a lista de arquivos ou um erro se a operação falhar
+	ListByVideoID(ctx context.Context, videoID string) ([]*entity.VideoFile, error)
+
+	// UpdateUploadStatus grava o estado do upload, a chave no S3 e o erro do arquivo
+	// Retorna ErrVideoFileNotFound se o arquivo não existir
+	UpdateUploadStatus(ctx context.Context, file *entity.VideoFile) error
+
+	// DeleteByVideoID remove os registros de todos os arquivos do vídeo
+	// Retorna um erro se a operação falhar
+	DeleteByVideoID(ctx context.Context, videoID string) error
+}
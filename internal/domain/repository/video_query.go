@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+)
+
+// ErrInvalidCursor é retornado quando o cursor informado está corrompido ou não corresponde à ordenação da consulta
+var ErrInvalidCursor = errors.New("cursor de paginação inválido")
+
+// Limites de itens por página na listagem de vídeos
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// VideoSortField define a coluna usada para ordenar a listagem de vídeos
+type VideoSortField string
+
+const (
+	SortByCreatedAt VideoSortField = "created_at"
+	SortByUpdatedAt VideoSortField = "updated_at"
+	SortByTitle     VideoSortField = "title" // Ordem dos bytes do título, igual em todas as implementações
+)
+
+// SortDirection define a direção da ordenação
+type SortDirection string
+
+const (
+	SortDesc SortDirection = "desc"
+	SortAsc  SortDirection = "asc"
+)
+
+// ListVideosQuery define os filtros, a ordenação e a paginação da listagem de vídeos
+// Campos vazios não restringem o resultado; os intervalos de datas são fechados no início e abertos no fim
+type ListVideosQuery struct {
+	OwnerID        string     // Retorna apenas vídeos da conta de cliente informada
+	Statuses       []string   // Retorna apenas vídeos com um dos status informados
+	UploadStatuses []string   // Retorna apenas vídeos com um dos status de upload informados
+	Tags           []string   // Retorna apenas vídeos que possuem todas as tags informadas
+	TitleContains  string     // Trecho do título, sem diferenciar maiúsculas de minúsculas
+	CreatedFrom    *time.Time // Criados a partir desta data
+	CreatedUntil   *time.Time // Criados antes desta data
+	UpdatedFrom    *time.Time // Atualizados a partir desta data
+	UpdatedUntil   *time.Time // Atualizados antes desta data
+
+	SortBy    VideoSortField // Coluna de ordenação (padrão: SortByCreatedAt)
+	Direction SortDirection  // Direção da ordenação (padrão: SortDesc)
+
+	Cursor       string // Cursor opaco retornado em VideoPage.NextCursor; vazio para a primeira página
+	Limit        int    // Itens por página (padrão: DefaultListLimit, máximo: MaxListLimit)
+	IncludeTotal bool   // Calcula o total de vídeos que atendem aos filtros (consulta adicional)
+}
+
+// Normalize aplica os valores padrão e valida a ordenação e o limite da consulta
+func (q ListVideosQuery) Normalize() (ListVideosQuery, error) {
+	switch q.SortBy {
+	case "":
+		q.SortBy = SortByCreatedAt
+	case SortByCreatedAt, SortByUpdatedAt, SortByTitle:
+	default:
+		return q, errors.New("campo de ordenação inválido: " + string(q.SortBy))
+	}
+
+	switch q.Direction {
+	case "":
+		q.Direction = SortDesc
+	case SortAsc, SortDesc:
+	default:
+		return q, errors.New("direção de ordenação inválida: " + string(q.Direction))
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = DefaultListLimit
+	}
+	q.Limit = min(q.Limit, MaxListLimit)
+	q.Tags = entity.NormalizeTags(q.Tags)
+
+	return q, nil
+}
+
+// VideoPage representa uma página da listagem de vídeos
+type VideoPage struct {
+	Videos     []*entity.Video
+	NextCursor string // Cursor da próxima página; vazio quando não há mais resultados
+	TotalCount *int64 // Total de vídeos que atendem aos filtros; nil quando IncludeTotal é falso
+}
+
+// VideoCursor é a posição do último item de uma página na ordenação da consulta
+// Value guarda o valor da coluna de ordenação e ID desempata itens com o mesmo valor
+type VideoCursor struct {
+	SortBy    VideoSortField `json:"s"`
+	Direction SortDirection  `json:"d"`
+	Value     string         `json:"v"`
+	ID        string         `json:"id"`
+}
+
+// NewVideoCursor cria o cursor que aponta para o vídeo informado
+func NewVideoCursor(video *entity.Video, sortBy VideoSortField, direction SortDirection) VideoCursor {
+	cursor := VideoCursor{SortBy: sortBy, Direction: direction, ID: video.ID}
+
+	switch sortBy {
+	case SortByUpdatedAt:
+		cursor.Value = video.UpdatedAt.Format(time.RFC3339Nano)
+	case SortByTitle:
+		cursor.Value = video.Title
+	default:
+		cursor.Value = video.CreatedAt.Format(time.RFC3339Nano)
+	}
+
+	return cursor
+}
+
+// Encode serializa o cursor em uma string opaca segura para URLs
+func (c VideoCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// TimeValue interpreta o valor do cursor como data, para ordenações por data
+func (c VideoCursor) TimeValue() (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, c.Value)
+	if err != nil {
+		return time.Time{}, ErrInvalidCursor
+	}
+	return t, nil
+}
+
+// DecodeVideoCursor interpreta um cursor gerado por Encode e verifica se ele pertence à ordenação da consulta
+func DecodeVideoCursor(encoded string, sortBy VideoSortField, direction SortDirection) (VideoCursor, error) {
+	var cursor VideoCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, ErrInvalidCursor
+	}
+
+	if cursor.ID == "" || cursor.SortBy != sortBy || cursor.Direction != direction {
+		return cursor, ErrInvalidCursor
+	}
+
+	if sortBy != SortByTitle {
+		if _, err := cursor.TimeValue(); err != nil {
+			return cursor, err
+		}
+	}
+
+	return cursor, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+)
+
+func TestListVideosQueryNormalize(t *testing.T) {
+	query, err := ListVideosQuery{Limit: 1000, Tags: []string{" golang ", "golang"}}.Normalize()
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if query.SortBy != SortByCreatedAt || query.Direction != SortDesc {
+		t.Errorf("Esperada ordenação padrão created_at desc, obtido %s %s", query.SortBy, query.Direction)
+	}
+
+	if query.Limit != MaxListLimit {
+		t.Errorf("Esperado Limit %d, obtido %d", MaxListLimit, query.Limit)
+	}
+
+	if len(query.Tags) != 1 || query.Tags[0] != "golang" {
+		t.Errorf("Esperado Tags [golang], obtido %v", query.Tags)
+	}
+
+	if _, err := (ListVideosQuery{SortBy: "file_path"}).Normalize(); err == nil {
+		t.Error("Esperado erro para campo de ordenação inválido")
+	}
+}
+
+func TestVideoCursorRoundTrip(t *testing.T) {
+	video := entity.NewVideo("owner-123", "Meu Vídeo", "", "/tmp/video.mp4")
+	video.CreatedAt = time.Date(2024, 5, 10, 12, 30, 0, 123456000, time.UTC)
+
+	encoded := NewVideoCursor(video, SortByCreatedAt, SortDesc).Encode()
+
+	cursor, err := DecodeVideoCursor(encoded, SortByCreatedAt, SortDesc)
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
+
+	if cursor.ID != video.ID {
+		t.Errorf("Esperado ID %s, obtido %s", video.ID, cursor.ID)
+	}
+
+	value, err := cursor.TimeValue()
+	if err != nil || !value.Equal(video.CreatedAt) {
+		t.Errorf("Esperado valor %v, obtido %v (%v)", video.CreatedAt, value, err)
+	}
+}
+
+func TestDecodeVideoCursorInvalid(t *testing.T) {
+	video := entity.NewVideo("owner-123", "Meu Vídeo", "", "/tmp/video.mp4")
+	encoded := NewVideoCursor(video, SortByTitle, SortAsc).Encode()
+
+	tests := []struct {
+		name      string
+		encoded   string
+		sortBy    VideoSortField
+		direction SortDirection
+	}{
+		{"não é base64", "%%%", SortByTitle, SortAsc},
+		{"não é JSON", "bm90LWpzb24", SortByTitle, SortAsc},
+		{"outro campo de ordenação", encoded, SortByCreatedAt, SortAsc},
+		{"outra direção", encoded, SortByTitle, SortDesc},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeVideoCursor(tt.encoded, tt.sortBy, tt.direction); err != ErrInvalidCursor {
+				t.Errorf("Esperado ErrInvalidCursor, obtido %v", err)
+			}
+		})
+	}
+}
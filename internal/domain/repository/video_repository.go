@@ -2,11 +2,37 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
 )
 
+// ErrVideoNotFound é retornado quando o vídeo não existe ou foi excluído
+var ErrVideoNotFound = errors.New("vídeo não encontrado")
+
+// ErrVideoAlreadyExists é retornado ao criar um vídeo com um ID já utilizado
+var ErrVideoAlreadyExists = errors.New("já existe um vídeo com este ID")
+
+// ErrConcurrentModification é retornado quando o vídeo foi alterado por outro processo desde a leitura
+var ErrConcurrentModification = errors.New("o vídeo foi modificado concorrentemente")
+
+// ErrNoPendingVideo é retornado por ClaimNextPending quando não há vídeo disponível para processamento
+var ErrNoPendingVideo = errors.New("nenhum vídeo pendente disponível")
+
+// ErrLeaseNotHeld é retornado quando o worker não detém a concessão de processamento do vídeo
+var ErrLeaseNotHeld = errors.New("o worker não detém a concessão do vídeo")
+
+// VideoFilter define os filtros opcionais aplicados na listagem de vídeos
+// Campos vazios não restringem o resultado
+type VideoFilter struct {
+	OwnerID string   // Retorna apenas vídeos da conta de cliente informada
+	Tags    []string // Retorna apenas vídeos que possuem todas as tags informadas
+}
+
 // VideoRepository define as operações que podem ser realizadas em um repositório de vídeos
+// Os métodos Update* específicos e Delete gravam apenas se a versão no repositório ainda for expectedVersion,
+// retornando ErrConcurrentModification caso contrário, e incrementam a versão (UpdateProgress é a exceção)
 type VideoRepository interface {
 	// Create persiste um novo vídeo no repositório
 	// Retorna um erro se a operação falhar
@@ -17,31 +43,81 @@ type VideoRepository interface {
 	FindByID(ctx context.Context, id string) (*entity.Video, error)
 
 	// List retorna uma lista de vídeos com paginação
+	// filter restringe o resultado por dono e tags
 	// page começa em 1, pageSize é o número de itens por página
 	// Retorna a lista de vídeos ou um erro se a operação falhar
-	List(ctx context.Context, page, pageSize int) ([]*entity.Video, error)
+	List(ctx context.Context, filter VideoFilter, page, pageSize int) ([]*entity.Video, error)
+
+	// ListVideos retorna uma página de vídeos filtrada e ordenada conforme a consulta, paginada por cursor
+	// Retorna ErrInvalidCursor se o cursor não pertencer à ordenação da consulta
+	ListVideos(ctx context.Context, query ListVideosQuery) (*VideoPage, error)
+
+	// VideoSearcher busca vídeos por palavras do título e da descrição
+	VideoSearcher
+
+	// VideoBulkOperations cria e altera muitos vídeos de uma vez
+	VideoBulkOperations
+
+	// VideoRetention restaura vídeos excluídos e remove definitivamente os que passaram da retenção
+	VideoRetention
+
+	// VideoStatistics calcula estatísticas agregadas dos vídeos
+	VideoStatistics
+
+	// FindCompletedByContentHash busca um vídeo já convertido da conta de cliente com a mesma impressão digital
+	// Retorna ErrVideoNotFound se não houver vídeo concluído com o conteúdo informado
+	FindCompletedByContentHash(ctx context.Context, ownerID, contentHash string) (*entity.Video, error)
+
+	// Update grava todos os campos editáveis do vídeo, desde que a versão no repositório seja video.Version
+	// Em caso de sucesso, video.Version é incrementada; se outro processo gravou antes, retorna ErrConcurrentModification
+	Update(ctx context.Context, video *entity.Video) error
 
 	// UpdateStatus atualiza o status de um vídeo e a mensagem de erro (quando aplicável)
+	// Retorna ErrConcurrentModification se a versão não for expectedVersion, ou um erro se a operação falhar
+	UpdateStatus(ctx context.Context, id string, expectedVersion int64, status string, errorMessage string) error
+
+	// UpdateProgress atualiza apenas o progresso (0 a 100), a etapa e a estimativa de término da conversão
+	// Chamado com frequência durante a conversão, por isso altera apenas essas colunas e updated_at, sem incrementar a versão
 	// Retorna um erro se a operação falhar
-	UpdateStatus(ctx context.Context, id string, status string, errorMessage string) error
+	UpdateProgress(ctx context.Context, id string, progress int, stage string, estimatedCompletionAt *time.Time) error
 
 	// UpdateHLSPath atualiza os caminhos HLS de um vídeo
-	// Retorna um erro se a operação falhar
-	UpdateHLSPath(ctx context.Context, id string, hlsPath, manifestPath string) error
+	// Retorna ErrConcurrentModification se a versão não for expectedVersion, ou um erro se a operação falhar
+	UpdateHLSPath(ctx context.Context, id string, expectedVersion int64, hlsPath, manifestPath string) error
 
 	// UpdateS3Status atualiza o status de upload para S3 de um vídeo
-	// Retorna um erro se a operação falhar
-	UpdateS3Status(ctx context.Context, id string, uploadStatus string) error
+	// Retorna ErrConcurrentModification se a versão não for expectedVersion, ou um erro se a operação falhar
+	UpdateS3Status(ctx context.Context, id string, expectedVersion int64, uploadStatus string) error
 
 	// UpdateS3URLs atualiza as URLs do S3 de um vídeo
-	// Retorna um erro se a operação falhar
-	UpdateS3URLs(ctx context.Context, id string, s3URL, s3ManifestURL string) error
+	// Retorna ErrConcurrentModification se a versão não for expectedVersion, ou um erro se a operação falhar
+	UpdateS3URLs(ctx context.Context, id string, expectedVersion int64, s3URL, s3ManifestURL string) error
 
 	// UpdateS3Keys atualiza as chaves do S3 de um vídeo (segmentKey e manifestKey)
-	// Retorna um erro se a operação falhar
-	UpdateS3Keys(ctx context.Context, id string, segmentKey string, manifestKey string) error
+	// Retorna ErrConcurrentModification se a versão não for expectedVersion, ou um erro se a operação falhar
+	UpdateS3Keys(ctx context.Context, id string, expectedVersion int64, segmentKey string, manifestKey string) error
+
+	// ClaimNextPending reivindica atomicamente o vídeo pendente mais antigo (ou um vídeo em processamento
+	// cuja concessão expirou), movendo-o para "processing" com uma concessão de leaseDuration para workerID
+	// Workers concorrentes nunca recebem o mesmo vídeo
+	// Retorna ErrNoPendingVideo se não houver vídeo disponível
+	ClaimNextPending(ctx context.Context, workerID string, leaseDuration time.Duration) (*entity.Video, error)
+
+	// RenewLease estende por leaseDuration, a partir de agora, a concessão que workerID detém sobre o vídeo
+	// Retorna ErrLeaseNotHeld se a concessão pertencer a outro worker ou o vídeo não estiver mais em processamento
+	RenewLease(ctx context.Context, id, workerID string, leaseDuration time.Duration) error
+
+	// ReleaseLease encerra a concessão que workerID detém sobre o vídeo
+	// Se o vídeo ainda estiver em processamento, ele volta para "pending" e pode ser reivindicado novamente
+	// Retorna ErrLeaseNotHeld se a concessão pertencer a outro worker
+	ReleaseLease(ctx context.Context, id, workerID string) error
+
+	// ListStaleProcessing retorna até limit vídeos em processamento considerados abandonados em staleBefore:
+	// a concessão terminou antes desse instante ou, sem concessão, a última atualização é anterior a ele
+	// Os vídeos são ordenados do abandonado há mais tempo para o mais recente
+	ListStaleProcessing(ctx context.Context, staleBefore time.Time, limit int) ([]*entity.Video, error)
 
 	// Delete remove um vídeo do repositório
-	// Retorna um erro se a operação falhar
-	Delete(ctx context.Context, id string) error
+	// Retorna ErrConcurrentModification se a versão não for expectedVersion, ou um erro se a operação falhar
+	Delete(ctx context.Context, id string, expectedVersion int64) error
 }
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+)
+
+// ErrVideoNotPurgeable é retornado quando o vídeo não atende mais aos critérios de remoção definitiva
+// (foi restaurado, voltou a ser processado ou passou a ser referenciado por uma duplicata)
+// ou quando Purge é chamado sem BeginPurge
+var ErrVideoNotPurgeable = errors.New("o vídeo não pode ser removido definitivamente")
+
+// PurgeReason indica por que o vídeo é candidato à remoção definitiva
+type PurgeReason string
+
+const (
+	PurgeReasonDeleted PurgeReason = "deleted" // Excluído há mais tempo que a retenção de vídeos excluídos
+	PurgeReasonFailed  PurgeReason = "failed"  // Com falha há mais tempo que a retenção de vídeos com falha
+)
+
+// PurgeCriteria define os vídeos que já passaram do período de retenção
+// Instantes zerados desativam o critério correspondente
+type PurgeCriteria struct {
+	DeletedBefore time.Time // Vídeos excluídos antes deste instante
+	FailedBefore  time.Time // Vídeos com falha cuja última atualização é anterior a este instante
+	Limit         int       // Máximo de candidatos retornados
+}
+
+// PurgeCandidate é um vídeo que pode ser removido definitivamente
+type PurgeCandidate struct {
+	Video     *entity.Video
+	Reason    PurgeReason
+	DeletedAt *time.Time // Momento da exclusão; nil para vídeos com falha ainda não excluídos
+	S3Keys    []string   // Chaves do S3 gravadas no vídeo por UpdateS3Keys, sem as vazias
+}
+
+// VideoRetention agrupa a restauração de vídeos excluídos e a remoção definitiva dos que passaram da retenção
+// A remoção acontece em duas etapas: BeginPurge retira o vídeo de uso de forma irreversível e Purge apaga a linha,
+// o que permite remover os arquivos entre as duas sem que o vídeo seja restaurado no meio do caminho
+// Vídeos em processamento com concessão ativa e originais referenciados por duplicatas (que reaproveitam a sua
+// saída HLS) nunca são candidatos
+type VideoRetention interface {
+	// Restore desfaz a exclusão do vídeo
+	// Retorna ErrVideoNotFound se não houver vídeo excluído com o ID, ou se a remoção definitiva já começou
+	Restore(ctx context.Context, id string) error
+
+	// ListPurgeCandidates retorna até criteria.Limit vídeos que atendem aos critérios, além dos que tiveram a remoção
+	// iniciada e não concluída, do que está há mais tempo excluído ou com falha para o mais recente
+	ListPurgeCandidates(ctx context.Context, criteria PurgeCriteria) ([]PurgeCandidate, error)
+
+	// BeginPurge confere que o vídeo ainda atende aos critérios e o marca como em remoção, excluindo-o se ainda
+	// não estiver excluído; a partir daí ele não pode ser restaurado
+	// Retorna ErrVideoNotPurgeable se o vídeo não atender mais aos critérios
+	BeginPurge(ctx context.Context, id string, criteria PurgeCriteria) error
+
+	// Purge apaga definitivamente o vídeo marcado por BeginPurge
+	// Retorna ErrVideoNotPurgeable se a remoção do vídeo não tiver sido iniciada
+	Purge(ctx context.Context, id string) error
+}
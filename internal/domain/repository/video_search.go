@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+)
+
+// ErrEmptySearchQuery é retornado quando o termo de busca não contém nenhuma palavra
+var ErrEmptySearchQuery = errors.New("termo de busca vazio")
+
+// Marcadores que envolvem os termos encontrados nos trechos destacados
+// O texto ao redor não é escapado; quem renderizar em HTML deve escapá-lo antes de aplicar os marcadores
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// VideoSearcher busca vídeos por palavras do título e da descrição
+// Separado de VideoRepository para que a busca possa ser atendida por outro mecanismo (ex.: um índice externo)
+type VideoSearcher interface {
+	// Search retorna os vídeos que contêm todas as palavras de query, do mais para o menos relevante
+	// cursor é o NextCursor da página anterior, ou vazio para a primeira página
+	// Retorna ErrEmptySearchQuery se query não tiver palavras e ErrInvalidCursor se o cursor não for desta busca,
+	// com os mesmos filtros
+	Search(ctx context.Context, query string, filters VideoSearchFilters, cursor string) (*VideoSearchPage, error)
+}
+
+// VideoSearchFilters restringe os resultados da busca
+// Campos vazios não restringem o resultado
+type VideoSearchFilters struct {
+	OwnerID  string   // Retorna apenas vídeos da conta de cliente informada
+	Statuses []string // Retorna apenas vídeos com um dos status informados
+	Tags     []string // Retorna apenas vídeos que possuem todas as tags informadas
+	Limit    int      // Itens por página (padrão: DefaultListLimit, máximo: MaxListLimit)
+}
+
+// Normalize aplica os valores padrão dos filtros
+func (f VideoSearchFilters) Normalize() VideoSearchFilters {
+	if f.Limit <= 0 {
+		f.Limit = DefaultListLimit
+	}
+	f.Limit = min(f.Limit, MaxListLimit)
+	f.Tags = entity.NormalizeTags(f.Tags)
+	return f
+}
+
+// fingerprint identifica os filtros que restringem o resultado, sem depender da ordem dos status e das tags
+// Limit não faz parte: mudar o tamanho da página não altera os resultados seguintes
+func (f VideoSearchFilters) fingerprint() string {
+	f = f.Normalize()
+	statuses := slices.Sorted(slices.Values(f.Statuses))
+	tags := slices.Sorted(slices.Values(f.Tags))
+
+	data, _ := json.Marshal([]any{f.OwnerID, statuses, tags})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ListQuery converte os filtros na consulta de listagem equivalente, para reaproveitar suas condições
+func (f VideoSearchFilters) ListQuery() ListVideosQuery {
+	return ListVideosQuery{OwnerID: f.OwnerID, Statuses: f.Statuses, Tags: f.Tags}
+}
+
+// VideoSearchResult é um vídeo encontrado pela busca
+type VideoSearchResult struct {
+	Video *entity.Video
+
+	// Rank é a relevância do vídeo para a busca; maior é mais relevante
+	// A escala depende da implementação e serve apenas para comparar resultados da mesma busca
+	Rank float64
+
+	TitleHighlight     string // Título com os termos encontrados destacados
+	DescriptionSnippet string // Trecho da descrição ao redor dos termos encontrados, com destaque
+}
+
+// VideoSearchPage representa uma página de resultados da busca
+type VideoSearchPage struct {
+	Results    []VideoSearchResult
+	NextCursor string // Cursor da próxima página; vazio quando não há mais resultados
+}
+
+// VideoSearchCursor guarda a posição da próxima página de uma busca
+// A relevância não é uma chave estável, por isso a paginação é por deslocamento
+// O deslocamento só vale para a mesma busca com os mesmos filtros, identificados por Filters
+type VideoSearchCursor struct {
+	Query   string `json:"q"`
+	Filters string `json:"f"`
+	Offset  int    `json:"o"`
+}
+
+// NewVideoSearchCursor cria o cursor que aponta para o deslocamento informado da busca
+func NewVideoSearchCursor(query string, filters VideoSearchFilters, offset int) VideoSearchCursor {
+	return VideoSearchCursor{Query: query, Filters: filters.fingerprint(), Offset: offset}
+}
+
+// Encode serializa o cursor em uma string opaca segura para URLs
+func (c VideoSearchCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeVideoSearchCursor interpreta um cursor gerado por Encode e verifica se ele pertence à busca informada,
+// com os mesmos filtros
+// Cursor vazio corresponde à primeira página
+func DecodeVideoSearchCursor(encoded, query string, filters VideoSearchFilters) (VideoSearchCursor, error) {
+	cursor := NewVideoSearchCursor(query, filters, 0)
+	if encoded == "" {
+		return cursor, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, ErrInvalidCursor
+	}
+
+	if cursor.Query != query || cursor.Filters != filters.fingerprint() || cursor.Offset < 0 {
+		return cursor, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// SearchTerms divide o termo de busca em palavras minúsculas, sem repetições
+// Qualquer caractere que não seja letra ou dígito separa as palavras
+func SearchTerms(query string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(fields))
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if !seen[field] {
+			seen[field] = true
+			terms = append(terms, field)
+		}
+	}
+
+	return terms
+}
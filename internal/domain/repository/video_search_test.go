@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchTerms(t *testing.T) {
+	terms := SearchTerms(`  "Curso" de GoLang: golang/concorrência -- 2024 `)
+
+	expected := []string{"curso", "de", "golang", "concorrência", "2024"}
+	if !reflect.DeepEqual(terms, expected) {
+		t.Errorf("Esperado %v, obtido %v", expected, terms)
+	}
+
+	if len(SearchTerms(" !? ")) != 0 {
+		t.Error("Esperado nenhum termo para busca sem palavras")
+	}
+}
+
+func TestVideoSearchCursor(t *testing.T) {
+	filters := VideoSearchFilters{OwnerID: "owner-1", Statuses: []string{"completed", "pending"}, Tags: []string{"curso"}}
+	encoded := NewVideoSearchCursor("golang", filters, 20).Encode()
+
+	cursor, err := DecodeVideoSearchCursor(encoded, "golang", filters)
+	if err != nil || cursor.Offset != 20 {
+		t.Errorf("Esperado deslocamento 20, obtido %d (%v)", cursor.Offset, err)
+	}
+
+	// A ordem dos status e o tamanho da página não mudam os filtros
+	reordered := VideoSearchFilters{OwnerID: "owner-1", Statuses: []string{"pending", "completed"}, Tags: []string{"curso"}, Limit: 5}
+	if _, err := DecodeVideoSearchCursor(encoded, "golang", reordered); err != nil {
+		t.Errorf("Esperado cursor válido com os mesmos filtros, obtido %v", err)
+	}
+
+	cursor, err = DecodeVideoSearchCursor("", "golang", filters)
+	if err != nil || cursor.Offset != 0 {
+		t.Errorf("Esperado deslocamento 0 para cursor vazio, obtido %d (%v)", cursor.Offset, err)
+	}
+
+	if _, err := DecodeVideoSearchCursor(encoded, "rust", filters); err != ErrInvalidCursor {
+		t.Errorf("Esperado ErrInvalidCursor para outra busca, obtido %v", err)
+	}
+
+	if _, err := DecodeVideoSearchCursor(encoded, "golang", VideoSearchFilters{OwnerID: "owner-2"}); err != ErrInvalidCursor {
+		t.Errorf("Esperado ErrInvalidCursor para outros filtros, obtido %v", err)
+	}
+
+	if _, err := DecodeVideoSearchCursor("%%%", "golang", filters); err != ErrInvalidCursor {
+		t.Errorf("Esperado ErrInvalidCursor, obtido %v", err)
+	}
+}
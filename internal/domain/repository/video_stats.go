@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+)
+
+// VideoStatistics calcula estatísticas agregadas dos vídeos, para acompanhar a vazão do processamento
+type VideoStatistics interface {
+	// Stats retorna as estatísticas dos vídeos não excluídos que atendem ao filtro
+	Stats(ctx context.Context, filter VideoStatsFilter) (*VideoStats, error)
+}
+
+// VideoStatsFilter restringe os vídeos considerados nas estatísticas
+// Campos vazios não restringem o resultado
+type VideoStatsFilter struct {
+	OwnerID      string     // Considera apenas vídeos da conta de cliente informada
+	CreatedFrom  *time.Time // Considera apenas vídeos criados a partir deste instante (inclusive)
+	CreatedUntil *time.Time // Considera apenas vídeos criados antes deste instante (exclusive)
+}
+
+// ListQuery converte o filtro na consulta de listagem equivalente, para reaproveitar suas condições
+func (f VideoStatsFilter) ListQuery() ListVideosQuery {
+	return ListVideosQuery{OwnerID: f.OwnerID, CreatedFrom: f.CreatedFrom, CreatedUntil: f.CreatedUntil}
+}
+
+// VideoStats reúne as estatísticas dos vídeos selecionados pelo filtro
+type VideoStats struct {
+	Total          int
+	ByStatus       map[string]int // Quantidade de vídeos por status
+	ByUploadStatus map[string]int // Quantidade de vídeos por status de upload
+	ProcessingTime ProcessingTimeStats
+	Failures       FailureStats
+	Daily          []DailyVideoStats // Totais por dia de criação (UTC), em ordem cronológica; dias sem vídeos são omitidos
+}
+
+// ProcessingTimeStats resume o tempo de processamento dos vídeos concluídos: do último início do
+// processamento até a conclusão
+// Duplicatas, que não passam pela conversão, ficam de fora
+type ProcessingTimeStats struct {
+	Count   int // Vídeos considerados; zero quando nenhum foi concluído
+	Average time.Duration
+	P50     time.Duration
+	P90     time.Duration
+	P95     time.Duration
+	P99     time.Duration
+}
+
+// FailureStats resume as falhas entre os vídeos que terminaram o processamento (concluídos ou com falha)
+type FailureStats struct {
+	Finished int
+	Failed   int
+	Rate     float64           // Failed / Finished; zero quando nenhum vídeo terminou
+	ByClass  []ErrorClassStats // Da classe com mais falhas para a com menos
+}
+
+// ErrorClassStats é a quantidade de falhas de uma classe de erro
+type ErrorClassStats struct {
+	Class entity.ErrorClass
+	Count int
+	Rate  float64 // Count / Finished
+}
+
+// DailyVideoStats são os totais dos vídeos criados em um dia
+type DailyVideoStats struct {
+	Date      time.Time // Início do dia, em UTC
+	Created   int
+	Completed int // Vídeos criados no dia que estão concluídos
+	Failed    int // Vídeos criados no dia que estão com falha
+}
+
+// NewVideoStats cria estatísticas vazias, com os mapas inicializados
+func NewVideoStats() *VideoStats {
+	return &VideoStats{
+		ByStatus:       make(map[string]int),
+		ByUploadStatus: make(map[string]int),
+		Daily:          []DailyVideoStats{},
+	}
+}
+
+// NewProcessingTimeStats calcula a média e os percentis das durações
+// Os percentis são interpolados linearmente entre as amostras, como percentile_cont do PostgreSQL
+func NewProcessingTimeStats(durations []time.Duration) ProcessingTimeStats {
+	if len(durations) == 0 {
+		return ProcessingTimeStats{}
+	}
+
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+
+	var total float64
+	for _, d := range sorted {
+		total += float64(d)
+	}
+
+	return ProcessingTimeStats{
+		Count:   len(sorted),
+		Average: time.Duration(math.Round(total / float64(len(sorted)))),
+		P50:     percentile(sorted, 0.50),
+		P90:     percentile(sorted, 0.90),
+		P95:     percentile(sorted, 0.95),
+		P99:     percentile(sorted, 0.99),
+	}
+}
+
+// percentile retorna o percentil p (0 a 1) das durações ordenadas
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	position := p * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+
+	fraction := position - float64(lower)
+	value := float64(sorted[lower]) + fraction*float64(sorted[upper]-sorted[lower])
+
+	return time.Duration(math.Round(value))
+}
+
+// NewFailureStats calcula a taxa de falhas, agrupando as mensagens de erro por entity.ClassifyError
+// failuresByMessage é a quantidade de vídeos com falha por mensagem de erro
+func NewFailureStats(finished int, failuresByMessage map[string]int) FailureStats {
+	stats := FailureStats{Finished: finished, ByClass: []ErrorClassStats{}}
+
+	byClass := make(map[entity.ErrorClass]int)
+	for message, count := range failuresByMessage {
+		byClass[entity.ClassifyError(message)] += count
+		stats.Failed += count
+	}
+
+	for class, count := range byClass {
+		stats.ByClass = append(stats.ByClass, ErrorClassStats{Class: class, Count: count, Rate: rate(count, finished)})
+	}
+
+	slices.SortFunc(stats.ByClass, func(a, b ErrorClassStats) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return cmp.Compare(a.Class, b.Class)
+	})
+
+	stats.Rate = rate(stats.Failed, finished)
+
+	return stats
+}
+
+// rate retorna part / total, ou zero quando total é zero
+func rate(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+)
+
+func TestNewProcessingTimeStats(t *testing.T) {
+	durations := []time.Duration{4 * time.Second, 1 * time.Second, 3 * time.Second, 2 * time.Second, 10 * time.Second}
+
+	stats := NewProcessingTimeStats(durations)
+
+	if stats.Count != 5 || stats.Average != 4*time.Second {
+		t.Errorf("Esperado Count 5 e Average 4s, obtido %d e %s", stats.Count, stats.Average)
+	}
+
+	// Interpolação linear entre as amostras ordenadas (1s, 2s, 3s, 4s, 10s)
+	if stats.P50 != 3*time.Second {
+		t.Errorf("Esperado P50 3s, obtido %s", stats.P50)
+	}
+	if want := 7600 * time.Millisecond; stats.P90 != want {
+		t.Errorf("Esperado P90 %s, obtido %s", want, stats.P90)
+	}
+	if want := 9760 * time.Millisecond; stats.P99 != want {
+		t.Errorf("Esperado P99 %s, obtido %s", want, stats.P99)
+	}
+
+	if durations[0] != 4*time.Second {
+		t.Error("As durações informadas não deveriam ser reordenadas")
+	}
+
+	if empty := NewProcessingTimeStats(nil); empty != (ProcessingTimeStats{}) {
+		t.Errorf("Esperadas estatísticas zeradas sem durações, obtido %+v", empty)
+	}
+}
+
+func TestNewFailureStats(t *testing.T) {
+	stats := NewFailureStats(10, map[string]int{
+		"erro na conversão FFmpeg: exit status 1":    2,
+		"erro na conversão FFmpeg: exit status 69":   1,
+		"conversão abandonada após 3 tentativas":     1,
+		"erro na conversão FFmpeg: context canceled": 1,
+	})
+
+	if stats.Finished != 10 || stats.Failed != 5 || stats.Rate != 0.5 {
+		t.Errorf("Esperado 5 falhas em 10 (0.5), obtido %d em %d (%v)", stats.Failed, stats.Finished, stats.Rate)
+	}
+
+	want := []ErrorClassStats{
+		{Class: entity.ErrorClassFFmpeg, Count: 3, Rate: 0.3},
+		{Class: entity.ErrorClassAbandoned, Count: 1, Rate: 0.1},
+		{Class: entity.ErrorClassCanceled, Count: 1, Rate: 0.1},
+	}
+	if len(stats.ByClass) != len(want) {
+		t.Fatalf("Esperadas %d classes, obtido %+v", len(want), stats.ByClass)
+	}
+	for i := range want {
+		if stats.ByClass[i] != want[i] {
+			t.Errorf("Classe %d: esperado %+v, obtido %+v", i, want[i], stats.ByClass[i])
+		}
+	}
+
+	if empty := NewFailureStats(0, nil); empty.Rate != 0 || len(empty.ByClass) != 0 {
+		t.Errorf("Esperada taxa zero sem vídeos terminados, obtido %+v", empty)
+	}
+}
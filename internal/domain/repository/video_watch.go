@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// VideoChange descreve a criação de um vídeo ou a mudança do seu status ou status de upload
+type VideoChange struct {
+	VideoID              string
+	OwnerID              string
+	Status               string
+	UploadStatus         string
+	PreviousStatus       string // Vazio na criação do vídeo e nas mudanças ressincronizadas
+	PreviousUploadStatus string // Vazio na criação do vídeo e nas mudanças ressincronizadas
+	Version              int64
+	UpdatedAt            time.Time
+
+	// Resync indica que a mudança foi obtida relendo os vídeos após uma reconexão
+	// Traz o estado atual do vídeo e pode repetir uma mudança já entregue
+	Resync bool
+}
+
+// VideoWatchFilter restringe as mudanças entregues por Watch
+// Campos vazios não restringem o resultado
+type VideoWatchFilter struct {
+	OwnerID  string   // Apenas vídeos da conta de cliente informada
+	VideoIDs []string // Apenas os vídeos informados
+}
+
+// Matches verifica se a mudança atende ao filtro
+func (f VideoWatchFilter) Matches(change VideoChange) bool {
+	if f.OwnerID != "" && change.OwnerID != f.OwnerID {
+		return false
+	}
+	if len(f.VideoIDs) > 0 && !slices.Contains(f.VideoIDs, change.VideoID) {
+		return false
+	}
+	return true
+}
+
+// VideoWatcher acompanha as mudanças de status dos vídeos sem consultas periódicas
+type VideoWatcher interface {
+	// Watch entrega as mudanças que atendem ao filtro, na ordem em que foram confirmadas, até o contexto
+	// ser cancelado, quando o canal é fechado
+	// A conexão é refeita automaticamente; depois de cada reconexão, os vídeos alterados enquanto ela
+	// estava fora são entregues novamente com Resync = true
+	Watch(ctx context.Context, filter VideoWatchFilter) <-chan VideoChange
+}
@@ -0,0 +1,28 @@
+package repository
+
+import "testing"
+
+func TestVideoWatchFilterMatches(t *testing.T) {
+	change := VideoChange{VideoID: "video-1", OwnerID: "owner-1"}
+
+	tests := []struct {
+		name     string
+		filter   VideoWatchFilter
+		expected bool
+	}{
+		{"sem filtro", VideoWatchFilter{}, true},
+		{"mesmo dono", VideoWatchFilter{OwnerID: "owner-1"}, true},
+		{"outro dono", VideoWatchFilter{OwnerID: "owner-2"}, false},
+		{"vídeo listado", VideoWatchFilter{VideoIDs: []string{"video-2", "video-1"}}, true},
+		{"vídeo não listado", VideoWatchFilter{VideoIDs: []string{"video-2"}}, false},
+		{"dono e vídeo", VideoWatchFilter{OwnerID: "owner-2", VideoIDs: []string{"video-1"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(change); got != tt.expected {
+				t.Errorf("Esperado %v, obtido %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// healthCheckTimeout limita cada verificação de saúde, para que um banco travado não prenda as demais
+const healthCheckTimeout = 5 * time.Second
+
+// primaryKey marca no contexto as leituras que devem ir ao primário
+type primaryKey struct{}
+
+// WithPrimary faz as leituras feitas com o contexto retornado irem ao primário
+// Usado quando a leitura precisa enxergar uma gravação recém-feita, que as réplicas podem ainda não ter recebido
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// primaryRequired verifica se o contexto exige leituras no primário
+func primaryRequired(ctx context.Context) bool {
+	required, _ := ctx.Value(primaryKey{}).(bool)
+	return required
+}
+
+// replica é uma réplica de leitura e o resultado da sua última verificação de saúde
+type replica struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// Cluster reúne o banco primário e as réplicas de leitura opcionais
+// As gravações e as transações usam sempre o primário; Reader distribui as leituras entre as réplicas
+// saudáveis, voltando ao primário quando nenhuma está disponível
+type Cluster struct {
+	driver   string
+	primary  *sql.DB
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+// NewCluster cria um Cluster a partir de conexões já abertas, com todas as réplicas consideradas saudáveis
+func NewCluster(driver string, primary *sql.DB, replicas ...*sql.DB) *Cluster {
+	cluster := &Cluster{
+		driver:  driver,
+		primary: primary,
+	}
+
+	for i, db := range replicas {
+		r := &replica{name: fmt.Sprintf("replica-%d", i+1), db: db}
+		r.healthy.Store(true)
+		cluster.replicas = append(cluster.replicas, r)
+	}
+
+	return cluster
+}
+
+// OpenCluster abre o primário com Open e as réplicas de config.ReplicaURLs
+// Uma réplica fora do ar na abertura não impede a inicialização: ela fica de fora das leituras
+// até uma verificação de saúde (CheckHealth) a encontrar disponível
+func OpenCluster(config Config) (*Cluster, error) {
+	primary, err := Open(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.ReplicaURLs) > 0 && config.Driver == DriverSQLite {
+		primary.Close()
+		return nil, errors.New("réplicas de leitura não são suportadas com SQLite")
+	}
+
+	cluster := NewCluster(config.Driver, primary)
+
+	for i, url := range config.ReplicaURLs {
+		db, err := sql.Open("postgres", url)
+		if err != nil {
+			cluster.Close()
+			return nil, fmt.Errorf("erro ao abrir conexão com a réplica %d: %w", i+1, err)
+		}
+		configurePool(db, config)
+
+		r := &replica{name: fmt.Sprintf("replica-%d", i+1), db: db}
+		r.healthy.Store(checkDB(context.Background(), r.name, db).Healthy)
+		cluster.replicas = append(cluster.replicas, r)
+	}
+
+	return cluster, nil
+}
+
+// Driver retorna o driver do banco primário
+func (c *Cluster) Driver() string {
+	return c.driver
+}
+
+// Primary retorna o banco primário, usado para gravações, transações e leituras que não toleram atraso
+func (c *Cluster) Primary() *sql.DB {
+	return c.primary
+}
+
+// Reader retorna o banco para uma leitura que tolera o atraso de replicação
+// Alterna entre as réplicas saudáveis; usa o primário se não houver nenhuma ou se o contexto
+// tiver sido marcado com WithPrimary
+func (c *Cluster) Reader(ctx context.Context) *sql.DB {
+	if len(c.replicas) == 0 || primaryRequired(ctx) {
+		return c.primary
+	}
+
+	start := c.next.Add(1)
+	for i := range uint64(len(c.replicas)) {
+		r := c.replicas[(start+i)%uint64(len(c.replicas))]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+
+	return c.primary
+}
+
+// DBHealth é o resultado da verificação de saúde de um banco
+type DBHealth struct {
+	Name    string
+	Healthy bool
+	Latency time.Duration // Duração do ping
+	Error   string        // Motivo da falha, quando não está saudável
+	Stats   sql.DBStats   // Situação do pool de conexões
+}
+
+// ClusterHealth é o resultado da verificação de saúde do primário e das réplicas
+type ClusterHealth struct {
+	Primary  DBHealth
+	Replicas []DBHealth
+}
+
+// Healthy informa se o cluster atende às requisições, o que depende apenas do primário
+func (h ClusterHealth) Healthy() bool {
+	return h.Primary.Healthy
+}
+
+// Degraded informa se alguma réplica está fora, com as leituras concentradas nas demais ou no primário
+func (h ClusterHealth) Degraded() bool {
+	for _, replica := range h.Replicas {
+		if !replica.Healthy {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckHealth verifica o primário e as réplicas, tirando das leituras as réplicas que falharem
+// e devolvendo as que voltaram
+func (c *Cluster) CheckHealth(ctx context.Context) ClusterHealth {
+	health := ClusterHealth{Primary: checkDB(ctx, "primary", c.primary)}
+
+	for _, r := range c.replicas {
+		replicaHealth := checkDB(ctx, r.name, r.db)
+		r.healthy.Store(replicaHealth.Healthy)
+		health.Replicas = append(health.Replicas, replicaHealth)
+	}
+
+	return health
+}
+
+// checkDB verifica um banco com um ping limitado por healthCheckTimeout
+func checkDB(ctx context.Context, name string, db *sql.DB) DBHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := db.PingContext(ctx)
+
+	health := DBHealth{
+		Name:    name,
+		Healthy: err == nil,
+		Latency: time.Since(start),
+		Stats:   db.Stats(),
+	}
+	if err != nil {
+		health.Error = err.Error()
+	}
+
+	return health
+}
+
+// RunHealthChecks executa CheckHealth a cada interval até o contexto ser cancelado,
+// registrando no log as mudanças de saúde de cada banco
+func (c *Cluster) RunHealthChecks(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	previous := c.CheckHealth(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := c.CheckHealth(ctx)
+		if logger != nil {
+			logHealthChanges(logger, previous, current)
+		}
+		previous = current
+	}
+}
+
+// logHealthChanges registra os bancos que ficaram fora do ar ou voltaram desde a verificação anterior
+func logHealthChanges(logger *slog.Logger, previous, current ClusterHealth) {
+	before := append([]DBHealth{previous.Primary}, previous.Replicas...)
+	after := append([]DBHealth{current.Primary}, current.Replicas...)
+
+	for i, health := range after {
+		switch {
+		case i >= len(before) || health.Healthy == before[i].Healthy:
+		case health.Healthy:
+			logger.Info("Banco de dados voltou a responder", "database", health.Name, "latency", health.Latency)
+		default:
+			logger.Error("Banco de dados não responde", "database", health.Name, "error", health.Error)
+		}
+	}
+}
+
+// Close fecha as conexões do primário e das réplicas
+func (c *Cluster) Close() error {
+	errs := []error{Close(c.primary)}
+	for _, r := range c.replicas {
+		errs = append(errs, Close(r.db))
+	}
+	return errors.Join(errs...)
+}
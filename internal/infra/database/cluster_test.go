@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func openTestDB(t *testing.T, name string) *sql.DB {
+	t.Helper()
+
+	db, err := NewSQLiteConnection(filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatalf("Erro ao abrir banco: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestClusterReader(t *testing.T) {
+	primary := openTestDB(t, "primary.db")
+	replica1 := openTestDB(t, "replica1.db")
+	replica2 := openTestDB(t, "replica2.db")
+	ctx := context.Background()
+
+	if reader := NewCluster(DriverSQLite, primary).Reader(ctx); reader != primary {
+		t.Error("Sem réplicas, as leituras deveriam ir ao primário")
+	}
+
+	cluster := NewCluster(DriverPostgres, primary, replica1, replica2)
+
+	seen := map[*sql.DB]bool{}
+	for range 4 {
+		seen[cluster.Reader(ctx)] = true
+	}
+	if len(seen) != 2 || seen[primary] {
+		t.Errorf("Esperadas as leituras alternando entre as 2 réplicas, obtido %d bancos", len(seen))
+	}
+
+	if reader := cluster.Reader(WithPrimary(ctx)); reader != primary {
+		t.Error("Com WithPrimary, a leitura deveria ir ao primário")
+	}
+}
+
+func TestClusterCheckHealth(t *testing.T) {
+	primary := openTestDB(t, "primary.db")
+	healthy := openTestDB(t, "replica1.db")
+	down := openTestDB(t, "replica2.db")
+	ctx := context.Background()
+
+	cluster := NewCluster(DriverPostgres, primary, healthy, down)
+	down.Close()
+
+	health := cluster.CheckHealth(ctx)
+
+	if !health.Healthy() || !health.Degraded() {
+		t.Errorf("Esperado cluster saudável e degradado, obtido %+v", health)
+	}
+	if len(health.Replicas) != 2 || health.Replicas[1].Healthy || health.Replicas[1].Error == "" {
+		t.Errorf("Esperada a segunda réplica fora do ar, obtido %+v", health.Replicas)
+	}
+
+	// A réplica fora do ar deixa de receber leituras
+	for range 4 {
+		if reader := cluster.Reader(ctx); reader != healthy {
+			t.Fatal("As leituras deveriam ir apenas à réplica saudável")
+		}
+	}
+
+	healthy.Close()
+	cluster.CheckHealth(ctx)
+
+	if reader := cluster.Reader(ctx); reader != primary {
+		t.Error("Sem réplicas saudáveis, as leituras deveriam voltar ao primário")
+	}
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// ReaperLockKey identifica o advisory lock do PostgreSQL que elege a instância que executa o reaper
+const ReaperLockKey int64 = 4_820_191_338
+
+// PurgeLockKey identifica o advisory lock do PostgreSQL que elege a instância que executa a remoção definitiva
+const PurgeLockKey int64 = 4_820_191_339
+
+// AdvisoryLock garante que apenas uma instância execute uma tarefa por vez
+// No PostgreSQL usa um advisory lock de sessão, válido para todo o cluster;
+// no SQLite o banco é local, então um mutex do processo basta
+type AdvisoryLock struct {
+	db     *sql.DB
+	driver string
+	key    int64
+	mu     sync.Mutex
+}
+
+// NewAdvisoryLock cria um AdvisoryLock identificado por key
+func NewAdvisoryLock(db *sql.DB, driver string, key int64) *AdvisoryLock {
+	return &AdvisoryLock{
+		db:     db,
+		driver: driver,
+		key:    key,
+	}
+}
+
+// TryRun executa fn se conseguir o lock sem esperar, liberando-o ao final
+// Retorna false, sem executar fn, se outra instância detém o lock
+func (l *AdvisoryLock) TryRun(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	if !l.mu.TryLock() {
+		return false, nil
+	}
+	defer l.mu.Unlock()
+
+	if l.driver == DriverSQLite {
+		return true, fn(ctx)
+	}
+
+	// O advisory lock é de sessão, por isso é adquirido e liberado na mesma conexão
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("erro ao obter conexão para o lock: %w", err)
+	}
+	defer conn.Close()
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, l.key).Scan(&acquired); err != nil {
+		return false, fmt.Errorf("erro ao adquirir lock: %w", err)
+	}
+	if !acquired {
+		return false, nil
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, l.key)
+
+	return true, fn(ctx)
+}
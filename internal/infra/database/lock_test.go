@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAdvisoryLockTryRun(t *testing.T) {
+	lock := NewAdvisoryLock(nil, DriverSQLite, ReaperLockKey)
+	ctx := context.Background()
+
+	var nested bool
+	ran, err := lock.TryRun(ctx, func(ctx context.Context) error {
+		// Enquanto a tarefa executa, outra chamada não consegue o lock
+		acquired, err := lock.TryRun(ctx, func(ctx context.Context) error {
+			nested = true
+			return nil
+		})
+		if acquired || err != nil {
+			t.Errorf("Esperado lock ocupado, obtido acquired=%v err=%v", acquired, err)
+		}
+		return nil
+	})
+	if !ran || err != nil {
+		t.Fatalf("Esperado lock adquirido, obtido ran=%v err=%v", ran, err)
+	}
+	if nested {
+		t.Error("A tarefa aninhada não deveria ter executado")
+	}
+
+	// O erro da tarefa é repassado e o lock é liberado
+	errTask := errors.New("falha")
+	ran, err = lock.TryRun(ctx, func(ctx context.Context) error { return errTask })
+	if !ran || !errors.Is(err, errTask) {
+		t.Errorf("Esperado erro da tarefa, obtido ran=%v err=%v", ran, err)
+	}
+}
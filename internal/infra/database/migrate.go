@@ -0,0 +1,379 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/infra/database/migrations"
+)
+
+// migrationLockKey identifica o advisory lock do PostgreSQL usado pelo Migrator
+// Qualquer valor fixo serve, desde que nenhum outro componente use o mesmo
+const migrationLockKey int64 = 4_820_191_337
+
+// ErrMigrationNotFound é retornado quando a versão pedida não existe ou não possui migração de reversão
+var ErrMigrationNotFound = errors.New("migração não encontrada")
+
+// Migration representa um par de arquivos NNNNNN_nome.up.sql / NNNNNN_nome.down.sql
+type Migration struct {
+	Version int64
+	Name    string
+	up      string
+	down    string
+}
+
+// MigrationStatus informa se uma migração já foi aplicada
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// Migrator aplica e reverte as migrações embarcadas, registrando as versões aplicadas em schema_migrations
+// No PostgreSQL, um advisory lock impede que instâncias iniciadas ao mesmo tempo executem as migrações em paralelo;
+// no SQLite, o próprio lock de escrita do arquivo serializa as instâncias
+type Migrator struct {
+	db         *sql.DB
+	driver     string
+	migrations []Migration
+}
+
+// NewMigrator cria um Migrator com as migrações embarcadas do driver informado
+func NewMigrator(db *sql.DB, driver string) (*Migrator, error) {
+	switch driver {
+	case "", DriverPostgres:
+		return NewMigratorWithFS(db, DriverPostgres, migrations.Postgres)
+	case DriverSQLite:
+		fsys, err := fs.Sub(migrations.SQLite, "sqlite")
+		if err != nil {
+			return nil, fmt.Errorf("erro ao abrir migrações do SQLite: %w", err)
+		}
+		return NewMigratorWithFS(db, DriverSQLite, fsys)
+	default:
+		return nil, fmt.Errorf("driver de banco de dados não suportado: %s", driver)
+	}
+}
+
+// NewMigratorWithFS cria um Migrator com as migrações encontradas na raiz de fsys
+func NewMigratorWithFS(db *sql.DB, driver string, fsys fs.FS) (*Migrator, error) {
+	loaded, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		driver:     driver,
+		migrations: loaded,
+	}, nil
+}
+
+// loadMigrations lê os arquivos *.up.sql e *.down.sql e os agrupa por versão, em ordem crescente
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar migrações: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, file := range files {
+		base := path.Base(file)
+
+		var direction string
+		switch {
+		case strings.HasSuffix(base, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		prefix, name, ok := strings.Cut(strings.TrimSuffix(base, "."+direction+".sql"), "_")
+		if !ok {
+			return nil, fmt.Errorf("nome de migração inválido: %s", base)
+		}
+
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("nome de migração inválido %s: %w", base, err)
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler migração %s: %w", base, err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+
+		if direction == "up" {
+			migration.up = string(content)
+		} else {
+			migration.down = string(content)
+		}
+	}
+
+	loaded := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.up) == "" {
+			return nil, fmt.Errorf("migração %06d_%s sem arquivo .up.sql", migration.Version, migration.Name)
+		}
+		loaded = append(loaded, *migration)
+	}
+
+	sort.Slice(loaded, func(i, j int) bool {
+		return loaded[i].Version < loaded[j].Version
+	})
+
+	return loaded, nil
+}
+
+// placeholder retorna o n-ésimo parâmetro no formato do driver
+func (m *Migrator) placeholder(n int) string {
+	if m.driver == DriverSQLite {
+		return "?" + strconv.Itoa(n)
+	}
+	return "$" + strconv.Itoa(n)
+}
+
+// appliedAt retorna a data de aplicação no formato gravado pelo driver (microssegundos no SQLite)
+func (m *Migrator) appliedAt(t time.Time) any {
+	if m.driver == DriverSQLite {
+		return t.UnixMicro()
+	}
+	return t
+}
+
+// withLock executa fn em uma conexão dedicada, com a tabela de controle criada e o lock adquirido
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao obter conexão para as migrações: %w", err)
+	}
+	defer conn.Close()
+
+	if m.driver != DriverSQLite {
+		// O advisory lock é de sessão, por isso todas as migrações usam a mesma conexão
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+			return fmt.Errorf("erro ao adquirir lock das migrações: %w", err)
+		}
+		defer conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+	}
+
+	createTable := `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT PRIMARY KEY, applied_at TIMESTAMP NOT NULL)`
+	if m.driver == DriverSQLite {
+		createTable = `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY, applied_at INTEGER NOT NULL)`
+	}
+	if _, err := conn.ExecContext(ctx, createTable); err != nil {
+		return fmt.Errorf("erro ao criar tabela de migrações: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// appliedVersions retorna as versões aplicadas e suas datas de aplicação
+func (m *Migrator) appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar migrações aplicadas: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt any
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("erro ao escanear migração aplicada: %w", err)
+		}
+
+		switch v := appliedAt.(type) {
+		case time.Time:
+			applied[version] = v
+		case int64:
+			applied[version] = time.UnixMicro(v)
+		default:
+			applied[version] = time.Time{}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre as migrações aplicadas: %w", err)
+	}
+
+	return applied, nil
+}
+
+// apply executa uma migração e atualiza schema_migrations na mesma transação
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration, up bool) error {
+	script := migration.up
+	record := fmt.Sprintf(`INSERT INTO schema_migrations (version, applied_at) VALUES (%s, %s)`, m.placeholder(1), m.placeholder(2))
+	args := []any{migration.Version, m.appliedAt(time.Now())}
+	direction := "aplicar"
+
+	if !up {
+		if strings.TrimSpace(migration.down) == "" {
+			return fmt.Errorf("%w: %06d_%s não possui arquivo .down.sql", ErrMigrationNotFound, migration.Version, migration.Name)
+		}
+		script = migration.down
+		record = fmt.Sprintf(`DELETE FROM schema_migrations WHERE version = %s`, m.placeholder(1))
+		args = args[:1]
+		direction = "reverter"
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação da migração %06d: %w", migration.Version, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("erro ao %s migração %06d_%s: %w", direction, migration.Version, migration.Name, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("erro ao registrar migração %06d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar migração %06d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return nil
+}
+
+// Up aplica todas as migrações pendentes, em ordem crescente
+// Retorna as versões aplicadas nesta chamada
+func (m *Migrator) Up(ctx context.Context) ([]int64, error) {
+	if len(m.migrations) == 0 {
+		return nil, nil
+	}
+	return m.To(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down reverte a última migração aplicada
+// Retorna a versão revertida, ou nil se nenhuma migração estiver aplicada
+func (m *Migrator) Down(ctx context.Context) ([]int64, error) {
+	var reverted []int64
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+
+			if err := m.apply(ctx, conn, migration, false); err != nil {
+				return err
+			}
+			reverted = append(reverted, migration.Version)
+			return nil
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// To aplica ou reverte migrações até que a versão informada seja a última aplicada
+// Versão 0 reverte todas as migrações
+// Retorna as versões aplicadas ou revertidas, na ordem em que foram executadas
+func (m *Migrator) To(ctx context.Context, version int64) ([]int64, error) {
+	if version != 0 && !m.hasVersion(version) {
+		return nil, fmt.Errorf("%w: versão %d", ErrMigrationNotFound, version)
+	}
+
+	var executed []int64
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		// Reverte, da mais nova para a mais antiga, as migrações acima da versão alvo
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok || migration.Version <= version {
+				continue
+			}
+
+			if err := m.apply(ctx, conn, migration, false); err != nil {
+				return err
+			}
+			executed = append(executed, migration.Version)
+		}
+
+		// Aplica, da mais antiga para a mais nova, as migrações pendentes até a versão alvo
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok || migration.Version > version {
+				continue
+			}
+
+			if err := m.apply(ctx, conn, migration, true); err != nil {
+				return err
+			}
+			executed = append(executed, migration.Version)
+		}
+
+		return nil
+	})
+
+	return executed, err
+}
+
+// Status retorna todas as migrações conhecidas e se cada uma já foi aplicada
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+			if appliedAt, ok := applied[migration.Version]; ok {
+				status.Applied = true
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// hasVersion verifica se existe uma migração com a versão informada
+func (m *Migrator) hasVersion(version int64) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
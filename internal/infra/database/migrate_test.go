@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func newTestMigrator(t *testing.T) (*Migrator, *sql.DB) {
+	t.Helper()
+
+	db, err := NewSQLiteConnection(filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Fatalf("Erro ao abrir banco: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	fsys := fstest.MapFS{
+		"000001_create_a.up.sql":   {Data: []byte(`CREATE TABLE a (id INTEGER PRIMARY KEY);`)},
+		"000001_create_a.down.sql": {Data: []byte(`DROP TABLE a;`)},
+		"000002_create_b.up.sql":   {Data: []byte(`CREATE TABLE b (id INTEGER PRIMARY KEY);`)},
+		"000002_create_b.down.sql": {Data: []byte(`DROP TABLE b;`)},
+		"000003_create_c.up.sql":   {Data: []byte(`CREATE TABLE c (id INTEGER PRIMARY KEY);`)},
+		"README.md":                {Data: []byte(`ignorado`)},
+	}
+
+	migrator, err := NewMigratorWithFS(db, DriverSQLite, fsys)
+	if err != nil {
+		t.Fatalf("Erro ao criar migrator: %v", err)
+	}
+
+	return migrator, db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?1`, name).Scan(&count); err != nil {
+		t.Fatalf("Erro ao consultar tabela %s: %v", name, err)
+	}
+	return count > 0
+}
+
+func TestMigratorUpDownTo(t *testing.T) {
+	migrator, db := newTestMigrator(t)
+	ctx := context.Background()
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Erro inesperado no Up: %v", err)
+	}
+	if len(applied) != 3 || !tableExists(t, db, "c") {
+		t.Fatalf("Esperadas 3 migrações aplicadas, obtido %v", applied)
+	}
+
+	applied, err = migrator.Up(ctx)
+	if err != nil || len(applied) != 0 {
+		t.Errorf("Esperado Up idempotente, obtido %v (%v)", applied, err)
+	}
+
+	// A migração 3 não tem .down.sql
+	if _, err := migrator.Down(ctx); !errors.Is(err, ErrMigrationNotFound) {
+		t.Errorf("Esperado ErrMigrationNotFound, obtido %v", err)
+	}
+
+	if _, err := db.Exec(`DROP TABLE c; DELETE FROM schema_migrations WHERE version = 3`); err != nil {
+		t.Fatalf("Erro ao remover migração 3: %v", err)
+	}
+
+	reverted, err := migrator.To(ctx, 1)
+	if err != nil {
+		t.Fatalf("Erro inesperado no To: %v", err)
+	}
+	if len(reverted) != 1 || reverted[0] != 2 || tableExists(t, db, "b") || !tableExists(t, db, "a") {
+		t.Errorf("Esperada apenas a migração 2 revertida, obtido %v", reverted)
+	}
+
+	reverted, err = migrator.Down(ctx)
+	if err != nil || len(reverted) != 1 || reverted[0] != 1 || tableExists(t, db, "a") {
+		t.Errorf("Esperada a migração 1 revertida, obtido %v (%v)", reverted, err)
+	}
+
+	if _, err := migrator.To(ctx, 42); !errors.Is(err, ErrMigrationNotFound) {
+		t.Errorf("Esperado ErrMigrationNotFound para versão inexistente, obtido %v", err)
+	}
+}
+
+func TestMigratorStatus(t *testing.T) {
+	migrator, _ := newTestMigrator(t)
+	ctx := context.Background()
+
+	if _, err := migrator.To(ctx, 2); err != nil {
+		t.Fatalf("Erro inesperado no To: %v", err)
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("Erro inesperado no Status: %v", err)
+	}
+
+	if len(statuses) != 3 {
+		t.Fatalf("Esperados 3 status, obtido %d", len(statuses))
+	}
+
+	for _, status := range statuses {
+		wantApplied := status.Version <= 2
+		if status.Applied != wantApplied || (status.AppliedAt != nil) != wantApplied {
+			t.Errorf("Migração %d: esperado aplicada=%v, obtido %+v", status.Version, wantApplied, status)
+		}
+	}
+
+	if statuses[1].Name != "create_b" {
+		t.Errorf("Esperado nome create_b, obtido %s", statuses[1].Name)
+	}
+}
+
+func TestNewMigratorEmbedded(t *testing.T) {
+	for _, driver := range []string{DriverPostgres, DriverSQLite} {
+		migrator, err := NewMigrator(nil, driver)
+		if err != nil {
+			t.Fatalf("Erro ao carregar migrações de %s: %v", driver, err)
+		}
+		if len(migrator.migrations) == 0 {
+			t.Errorf("Esperadas migrações embarcadas para %s", driver)
+		}
+	}
+}
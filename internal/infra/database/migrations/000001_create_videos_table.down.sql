@@ -1 +1 @@
-DROP TABLE IF EXISTS videos; 
\ No newline at end of file
+DROP TABLE IF EXISTS videos; 
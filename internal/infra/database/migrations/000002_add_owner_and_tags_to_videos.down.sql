@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_videos_tags;
+DROP INDEX IF EXISTS idx_videos_owner_id_created_at;
+
+ALTER TABLE videos DROP COLUMN IF EXISTS tags;
+ALTER TABLE videos DROP COLUMN IF EXISTS owner_id;
@@ -0,0 +1,9 @@
+-- Todo vídeo pertence a uma conta de cliente (tenant). Registros antigos recebem
+-- um owner vazio apenas para permitir a criação da coluna NOT NULL.
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS owner_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE videos ALTER COLUMN owner_id DROP DEFAULT;
+
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_videos_owner_id_created_at ON videos (owner_id, created_at DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_videos_tags ON videos USING GIN (tags);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS processing_attempts;
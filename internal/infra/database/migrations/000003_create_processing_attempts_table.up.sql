@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS processing_attempts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    video_id UUID NOT NULL REFERENCES videos (id) ON DELETE CASCADE,
+    worker_id VARCHAR(255) NOT NULL,
+    encoding_params JSONB NOT NULL DEFAULT '{}',
+    stderr_excerpt TEXT NOT NULL DEFAULT '',
+    outcome VARCHAR(50) NOT NULL DEFAULT 'running',
+    error_message TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_processing_attempts_video_id_started_at ON processing_attempts (video_id, started_at);
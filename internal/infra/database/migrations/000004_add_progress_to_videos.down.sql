@@ -0,0 +1,3 @@
+ALTER TABLE videos DROP COLUMN IF EXISTS estimated_completion_at;
+ALTER TABLE videos DROP COLUMN IF EXISTS processing_stage;
+ALTER TABLE videos DROP COLUMN IF EXISTS progress;
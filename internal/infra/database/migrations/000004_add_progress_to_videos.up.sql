@@ -0,0 +1,5 @@
+-- As colunas de progresso são atualizadas com frequência durante a conversão.
+-- Elas não devem ser indexadas, para que o PostgreSQL possa usar HOT updates.
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS progress SMALLINT NOT NULL DEFAULT 0 CHECK (progress BETWEEN 0 AND 100);
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS processing_stage VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS estimated_completion_at TIMESTAMP;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_videos_owner_content_hash;
+ALTER TABLE videos DROP COLUMN IF EXISTS duplicate_of;
+ALTER TABLE videos DROP COLUMN IF EXISTS content_hash;
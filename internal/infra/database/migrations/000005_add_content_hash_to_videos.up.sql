@@ -0,0 +1,7 @@
+-- Impressão digital SHA-256 (hex) do arquivo original, usada para detectar uploads duplicados
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS content_hash CHAR(64);
+-- Vídeo original cuja saída HLS foi reaproveitada por esta duplicata
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS duplicate_of UUID;
+
+CREATE INDEX IF NOT EXISTS idx_videos_owner_content_hash ON videos (owner_id, content_hash)
+    WHERE content_hash IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_videos_title_trgm;
+DROP INDEX IF EXISTS idx_videos_status_created_at_id;
+DROP INDEX IF EXISTS idx_videos_owner_status_created_at_id;
+DROP INDEX IF EXISTS idx_videos_owner_created_at_id;
+DROP INDEX IF EXISTS idx_videos_title_id;
+DROP INDEX IF EXISTS idx_videos_updated_at_id;
+DROP INDEX IF EXISTS idx_videos_created_at_id;
+
+CREATE INDEX IF NOT EXISTS idx_videos_owner_id_created_at ON videos (owner_id, created_at DESC) WHERE deleted_at IS NULL;
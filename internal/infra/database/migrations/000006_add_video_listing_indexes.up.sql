@@ -0,0 +1,17 @@
+-- Índices da listagem paginada por cursor: cada ordenação usa o par (coluna, id),
+-- para que a página seguinte seja lida diretamente a partir do último item.
+CREATE INDEX IF NOT EXISTS idx_videos_created_at_id ON videos (created_at DESC, id DESC) WHERE deleted_at IS NULL;
+-- As gravações de progresso também atualizam updated_at; com este índice elas deixam de ser HOT updates,
+-- custo aceito para que a ordenação por data de atualização reflita o andamento da conversão.
+CREATE INDEX IF NOT EXISTS idx_videos_updated_at_id ON videos (updated_at DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_videos_title_id ON videos (title, id) WHERE deleted_at IS NULL;
+
+-- Listagens por conta de cliente, com ou sem filtro de status
+DROP INDEX IF EXISTS idx_videos_owner_id_created_at;
+CREATE INDEX IF NOT EXISTS idx_videos_owner_created_at_id ON videos (owner_id, created_at DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_videos_owner_status_created_at_id ON videos (owner_id, status, created_at DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_videos_status_created_at_id ON videos (status, created_at DESC, id DESC) WHERE deleted_at IS NULL;
+
+-- Busca por trecho do título (ILIKE '%...%')
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_videos_title_trgm ON videos USING GIN (title gin_trgm_ops) WHERE deleted_at IS NULL;
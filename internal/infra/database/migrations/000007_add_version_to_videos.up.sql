@@ -0,0 +1,2 @@
+-- Versão do registro para controle de concorrência otimista
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_videos_processing_lease;
+DROP INDEX IF EXISTS idx_videos_pending_queue;
+ALTER TABLE videos DROP COLUMN IF EXISTS lease_expires_at;
+ALTER TABLE videos DROP COLUMN IF EXISTS lease_owner;
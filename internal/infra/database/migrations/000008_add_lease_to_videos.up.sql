@@ -0,0 +1,10 @@
+-- Concessão de processamento: o worker que reivindicou o vídeo e até quando a concessão vale
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS lease_owner VARCHAR(255);
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMP;
+
+-- Fila de vídeos pendentes, na ordem em que são reivindicados
+CREATE INDEX IF NOT EXISTS idx_videos_pending_queue ON videos (created_at, id)
+    WHERE status = 'pending' AND deleted_at IS NULL;
+-- Concessões expiradas, que podem ser reivindicadas novamente
+CREATE INDEX IF NOT EXISTS idx_videos_processing_lease ON videos (lease_expires_at)
+    WHERE status = 'processing' AND deleted_at IS NULL;
@@ -0,0 +1,8 @@
+-- Busca textual no título (peso A) e na descrição (peso B)
+-- A configuração 'simple' não remove stopwords nem reduz palavras ao radical, pois os vídeos podem estar em qualquer idioma
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_videos_search_vector ON videos USING GIN (search_vector) WHERE deleted_at IS NULL;
@@ -0,0 +1,15 @@
+-- Histórico de alterações dos vídeos, gravado pelo AuditedVideoRepository
+-- Sem chave estrangeira para videos: o histórico precisa sobreviver à remoção definitiva do vídeo
+CREATE TABLE IF NOT EXISTS video_audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    video_id UUID NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    actor_type VARCHAR(20) NOT NULL,
+    actor_id VARCHAR(255) NOT NULL DEFAULT '',
+    correlation_id VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_audit_log_video_id_id ON video_audit_log (video_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_video_audit_log_correlation_id ON video_audit_log (correlation_id) WHERE correlation_id <> '';
@@ -0,0 +1,21 @@
+-- Arquivos HLS gerados em cada conversão, com o estado do upload de cada um
+CREATE TABLE IF NOT EXISTS video_files (
+    id UUID PRIMARY KEY,
+    video_id UUID NOT NULL REFERENCES videos (id) ON DELETE CASCADE,
+    file_type VARCHAR(20) NOT NULL,
+    sequence INTEGER NOT NULL DEFAULT 0,
+    local_path TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    checksum CHAR(64) NOT NULL,
+    s3_key TEXT NOT NULL DEFAULT '',
+    upload_status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    upload_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (video_id, local_path)
+);
+
+-- Arquivos ainda não enviados, para retomar uploads interrompidos
+CREATE INDEX IF NOT EXISTS idx_video_files_pending_upload ON video_files (video_id)
+    WHERE upload_status IN ('pending', 'failed');
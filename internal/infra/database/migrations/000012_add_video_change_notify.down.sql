@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS videos_notify_status ON videos;
+DROP TRIGGER IF EXISTS videos_notify_insert ON videos;
+DROP FUNCTION IF EXISTS notify_video_change();
@@ -0,0 +1,38 @@
+-- Publica no canal video_changes cada criação de vídeo e cada mudança de status ou de status de upload
+-- O NOTIFY só é entregue quando a transação que alterou o vídeo é confirmada
+CREATE OR REPLACE FUNCTION notify_video_change() RETURNS trigger AS $$
+DECLARE
+    previous_status TEXT;
+    previous_upload_status TEXT;
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        previous_status := OLD.status;
+        previous_upload_status := OLD.upload_status;
+    END IF;
+
+    PERFORM pg_notify('video_changes', json_build_object(
+        'id', NEW.id,
+        'owner_id', NEW.owner_id,
+        'status', NEW.status,
+        'upload_status', NEW.upload_status,
+        'previous_status', previous_status,
+        'previous_upload_status', previous_upload_status,
+        'version', NEW.version,
+        'updated_at', (EXTRACT(EPOCH FROM NEW.updated_at) * 1000000)::BIGINT
+    )::text);
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS videos_notify_insert ON videos;
+CREATE TRIGGER videos_notify_insert
+    AFTER INSERT ON videos
+    FOR EACH ROW EXECUTE FUNCTION notify_video_change();
+
+DROP TRIGGER IF EXISTS videos_notify_status ON videos;
+CREATE TRIGGER videos_notify_status
+    AFTER UPDATE OF status, upload_status ON videos
+    FOR EACH ROW
+    WHEN (OLD.status IS DISTINCT FROM NEW.status OR OLD.upload_status IS DISTINCT FROM NEW.upload_status)
+    EXECUTE FUNCTION notify_video_change();
@@ -0,0 +1,9 @@
+-- Início da remoção definitiva do vídeo; depois dele o vídeo não pode mais ser restaurado
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS purge_started_at TIMESTAMP;
+
+-- Duplicatas de cada original, que impedem a remoção da saída HLS compartilhada
+CREATE INDEX IF NOT EXISTS idx_videos_duplicate_of ON videos (duplicate_of) WHERE duplicate_of IS NOT NULL;
+
+-- Candidatos à remoção definitiva: excluídos e com falha
+CREATE INDEX IF NOT EXISTS idx_videos_deleted_at ON videos (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_videos_failed_updated_at ON videos (updated_at) WHERE status = 'failed';
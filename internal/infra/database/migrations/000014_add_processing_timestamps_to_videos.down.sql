@@ -0,0 +1,2 @@
+ALTER TABLE videos DROP COLUMN IF EXISTS completed_at;
+ALTER TABLE videos DROP COLUMN IF EXISTS processing_started_at;
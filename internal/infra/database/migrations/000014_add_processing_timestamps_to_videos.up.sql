@@ -0,0 +1,15 @@
+-- Último início do processamento e conclusão do vídeo, usados nas estatísticas de tempo de processamento
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS processing_started_at TIMESTAMP;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP;
+
+-- Vídeos já concluídos herdam os instantes da última tentativa bem-sucedida, quando houver
+UPDATE videos v
+SET processing_started_at = a.started_at, completed_at = a.finished_at
+FROM (
+    SELECT DISTINCT ON (video_id) video_id, started_at, finished_at
+    FROM processing_attempts
+    WHERE outcome = 'succeeded' AND finished_at IS NOT NULL
+    ORDER BY video_id, started_at DESC
+) a
+WHERE a.video_id = v.id AND v.status = 'completed';
+
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_videos_title_c_id;
+CREATE INDEX IF NOT EXISTS idx_videos_title_id ON videos (title, id) WHERE deleted_at IS NULL;
@@ -0,0 +1,4 @@
+-- A ordenação por título usa a collation "C" (ordem dos bytes), a mesma do SQLite e do repositório em memória,
+-- para que a ordem e o cursor não dependam da collation do banco. O índice precisa usar a mesma collation.
+DROP INDEX IF EXISTS idx_videos_title_id;
+CREATE INDEX IF NOT EXISTS idx_videos_title_c_id ON videos ((title COLLATE "C"), id) WHERE deleted_at IS NULL;
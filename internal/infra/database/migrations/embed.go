@@ -0,0 +1,14 @@
+// Package migrations embute os arquivos de migração no binário
+package migrations
+
+import "embed"
+
+// Postgres contém as migrações do PostgreSQL, na raiz deste diretório
+//
+//go:embed *.sql
+var Postgres embed.FS
+
+// SQLite contém as migrações do banco SQLite embarcado, no diretório sqlite/
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
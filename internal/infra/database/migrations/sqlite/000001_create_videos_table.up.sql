@@ -0,0 +1,39 @@
+-- Esquema SQLite equivalente às migrações PostgreSQL da tabela videos.
+-- Datas são gravadas em microssegundos desde a época Unix (UTC), para ordenação e comparação numéricas.
+-- Tags são gravadas como um array JSON.
+CREATE TABLE IF NOT EXISTS videos (
+    id TEXT PRIMARY KEY,
+    owner_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    tags TEXT NOT NULL DEFAULT '[]',
+    file_path TEXT NOT NULL,
+    content_hash TEXT NOT NULL DEFAULT '',
+    duplicate_of TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending',
+    upload_status TEXT NOT NULL DEFAULT 'none',
+    error_message TEXT NOT NULL DEFAULT '',
+    hls_path TEXT NOT NULL DEFAULT '',
+    manifest_path TEXT NOT NULL DEFAULT '',
+    s3_url TEXT NOT NULL DEFAULT '',
+    s3_manifest_url TEXT NOT NULL DEFAULT '',
+    segment_key TEXT NOT NULL DEFAULT '',
+    manifest_key TEXT NOT NULL DEFAULT '',
+    progress INTEGER NOT NULL DEFAULT 0 CHECK (progress BETWEEN 0 AND 100),
+    processing_stage TEXT NOT NULL DEFAULT '',
+    estimated_completion_at INTEGER,
+    lease_owner TEXT NOT NULL DEFAULT '',
+    lease_expires_at INTEGER,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL,
+    deleted_at INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_videos_created_at_id ON videos (created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_videos_updated_at_id ON videos (updated_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_videos_title_id ON videos (title, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_videos_owner_created_at_id ON videos (owner_id, created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_videos_status_created_at_id ON videos (status, created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_videos_owner_content_hash ON videos (owner_id, content_hash) WHERE content_hash <> '' AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_videos_processing_lease ON videos (lease_expires_at) WHERE status = 'processing' AND deleted_at IS NULL;
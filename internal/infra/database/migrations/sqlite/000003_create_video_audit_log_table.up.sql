@@ -0,0 +1,14 @@
+-- Histórico de alterações dos vídeos, equivalente à tabela video_audit_log do PostgreSQL.
+CREATE TABLE IF NOT EXISTS video_audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    video_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    changes TEXT NOT NULL DEFAULT '{}',
+    actor_type TEXT NOT NULL,
+    actor_id TEXT NOT NULL DEFAULT '',
+    correlation_id TEXT NOT NULL DEFAULT '',
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_video_audit_log_video_id_id ON video_audit_log (video_id, id);
+CREATE INDEX IF NOT EXISTS idx_video_audit_log_correlation_id ON video_audit_log (correlation_id) WHERE correlation_id <> '';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_videos_failed_updated_at;
+DROP INDEX IF EXISTS idx_videos_deleted_at;
+DROP INDEX IF EXISTS idx_videos_duplicate_of;
+ALTER TABLE videos DROP COLUMN purge_started_at;
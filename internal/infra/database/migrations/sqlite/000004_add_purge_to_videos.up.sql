@@ -0,0 +1,6 @@
+-- Equivalente à migração 000013 do PostgreSQL.
+ALTER TABLE videos ADD COLUMN purge_started_at INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_videos_duplicate_of ON videos (duplicate_of) WHERE duplicate_of <> '';
+CREATE INDEX IF NOT EXISTS idx_videos_deleted_at ON videos (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_videos_failed_updated_at ON videos (updated_at) WHERE status = 'failed';
@@ -0,0 +1,2 @@
+ALTER TABLE videos DROP COLUMN completed_at;
+ALTER TABLE videos DROP COLUMN processing_started_at;
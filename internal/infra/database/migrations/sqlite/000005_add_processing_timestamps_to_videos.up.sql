@@ -0,0 +1,3 @@
+-- Equivalente à migração 000014 do PostgreSQL; o SQLite não registra tentativas, então não há o que preencher.
+ALTER TABLE videos ADD COLUMN processing_started_at INTEGER;
+ALTER TABLE videos ADD COLUMN completed_at INTEGER;
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+	"github.com/devfullcycle/golangtechweek/internal/infra/database"
+)
+
+// NewVideoRepository cria o repositório de vídeos correspondente ao driver configurado (database.Config.Driver)
+// Toda gravação é registrada no histórico de auditoria do mesmo banco
+func NewVideoRepository(driver string, db *sql.DB) (domainRepository.VideoRepository, error) {
+	audit, err := NewVideoAuditRepository(driver, db)
+	if err != nil {
+		return nil, err
+	}
+
+	switch driver {
+	case "", database.DriverPostgres:
+		return NewAuditedVideoRepository(NewVideoRepositoryPostgres(db), audit, db), nil
+	default:
+		return NewAuditedVideoRepository(NewVideoRepositorySQLite(db), audit, db), nil
+	}
+}
+
+// NewVideoAuditRepository cria o repositório do histórico de auditoria correspondente ao driver configurado
+func NewVideoAuditRepository(driver string, db *sql.DB) (domainRepository.VideoAuditRepository, error) {
+	switch driver {
+	case "", database.DriverPostgres:
+		return NewVideoAuditRepositoryPostgres(db), nil
+	case database.DriverSQLite:
+		return NewVideoAuditRepositorySQLite(db), nil
+	default:
+		return nil, fmt.Errorf("driver de banco de dados não suportado: %s", driver)
+	}
+}
+
+// NewVideoRepositoryFromCluster cria o repositório de vídeos do driver do cluster
+// No PostgreSQL, as listagens e buscas são feitas nas réplicas de leitura, quando houver
+func NewVideoRepositoryFromCluster(cluster *database.Cluster) (domainRepository.VideoRepository, error) {
+	if cluster.Driver() != database.DriverSQLite {
+		db := cluster.Primary()
+		return NewAuditedVideoRepository(NewVideoRepositoryPostgresWithCluster(cluster), NewVideoAuditRepositoryPostgres(db), db), nil
+	}
+	return NewVideoRepository(cluster.Driver(), cluster.Primary())
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+)
+
+var ErrProcessingAttemptNotFound = errors.New("tentativa de processamento não encontrada")
+
+// ProcessingAttemptRepositoryPostgres implementa a interface ProcessingAttemptRepository usando PostgreSQL
+type ProcessingAttemptRepositoryPostgres struct {
+	db *sql.DB
+}
+
+// NewProcessingAttemptRepositoryPostgres cria uma nova instância de ProcessingAttemptRepositoryPostgres
+func NewProcessingAttemptRepositoryPostgres(db *sql.DB) *ProcessingAttemptRepositoryPostgres {
+	return &ProcessingAttemptRepositoryPostgres{
+		db: db,
+	}
+}
+
+// Create persiste uma nova tentativa de processamento no banco de dados
+func (r *ProcessingAttemptRepositoryPostgres) Create(ctx context.Context, attempt *entity.ProcessingAttempt) error {
+	encodingParams := attempt.EncodingParams
+	if encodingParams == nil {
+		encodingParams = map[string]string{}
+	}
+
+	params, err := json.Marshal(encodingParams)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar parâmetros de codificação: %w", err)
+	}
+
+	query := `
+		INSERT INTO processing_attempts (
+			id, video_id, worker_id, encoding_params, stderr_excerpt, outcome, error_message, started_at, finished_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		)
+	`
+
+	_, err = conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		attempt.ID,
+		attempt.VideoID,
+		attempt.WorkerID,
+		string(params),
+		attempt.StderrExcerpt,
+		attempt.Outcome,
+		attempt.ErrorMessage,
+		attempt.StartedAt,
+		attempt.FinishedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao criar tentativa de processamento: %w", err)
+	}
+
+	return nil
+}
+
+// Finish persiste o resultado de uma tentativa de processamento
+func (r *ProcessingAttemptRepositoryPostgres) Finish(ctx context.Context, attempt *entity.ProcessingAttempt) error {
+	query := `
+		UPDATE processing_attempts
+		SET outcome = $1, error_message = $2, stderr_excerpt = $3, finished_at = $4
+		WHERE id = $5
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, attempt.Outcome, attempt.ErrorMessage, attempt.StderrExcerpt, attempt.FinishedAt, attempt.ID)
+	if err != nil {
+		return fmt.Errorf("erro ao finalizar tentativa de processamento: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao obter linhas afetadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrProcessingAttemptNotFound
+	}
+
+	return nil
+}
+
+// ListByVideoID retorna as tentativas de um vídeo, da mais antiga para a mais recente
+func (r *ProcessingAttemptRepositoryPostgres) ListByVideoID(ctx context.Context, videoID string) ([]*entity.ProcessingAttempt, error) {
+	query := `
+		SELECT
+			id, video_id, worker_id, encoding_params, stderr_excerpt, outcome, error_message, started_at, finished_at
+		FROM processing_attempts
+		WHERE video_id = $1
+		ORDER BY started_at ASC, id ASC
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar tentativas de processamento: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []*entity.ProcessingAttempt
+
+	for rows.Next() {
+		var attempt entity.ProcessingAttempt
+		var params []byte
+		var finishedAt sql.NullTime
+
+		err := rows.Scan(
+			&attempt.ID,
+			&attempt.VideoID,
+			&attempt.WorkerID,
+			&params,
+			&attempt.StderrExcerpt,
+			&attempt.Outcome,
+			&attempt.ErrorMessage,
+			&attempt.StartedAt,
+			&finishedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear tentativa de processamento: %w", err)
+		}
+
+		if err := json.Unmarshal(params, &attempt.EncodingParams); err != nil {
+			return nil, fmt.Errorf("erro ao desserializar parâmetros de codificação: %w", err)
+		}
+
+		if finishedAt.Valid {
+			attempt.FinishedAt = &finishedAt.Time
+		}
+
+		attempts = append(attempts, &attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os resultados: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// Ensure ProcessingAttemptRepositoryPostgres implements ProcessingAttemptRepository
+var _ domainRepository.ProcessingAttemptRepository = (*ProcessingAttemptRepositoryPostgres)(nil)
@@ -0,0 +1,100 @@
+//go:build integration
+// +build integration
+
+// Este arquivo contém testes de integração que acessam o banco de dados real.
+// Para executar estes testes, use o comando:
+// go test -tags=integration ./internal/infra/database/repository
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	"github.com/devfullcycle/golangtechweek/internal/infra/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProcessingAttemptRepositoryTestSuite struct {
+	suite.Suite
+	db         *sql.DB
+	repository *ProcessingAttemptRepositoryPostgres
+	videoRepo  *VideoRepositoryPostgres
+	ctx        context.Context
+}
+
+func (suite *ProcessingAttemptRepositoryTestSuite) SetupSuite() {
+	var err error
+	suite.db, err = database.NewConnection(testDBConfig())
+	if err != nil {
+		suite.T().Fatalf("Erro ao conectar ao banco de dados: %v", err)
+	}
+
+	suite.repository = NewProcessingAttemptRepositoryPostgres(suite.db)
+	suite.videoRepo = NewVideoRepositoryPostgres(suite.db)
+	suite.ctx = context.Background()
+}
+
+func (suite *ProcessingAttemptRepositoryTestSuite) TearDownSuite() {
+	// As tentativas são removidas em cascata junto com os vídeos
+	_, err := suite.db.Exec("DELETE FROM videos")
+	if err != nil {
+		suite.T().Fatalf("Erro ao limpar a tabela de vídeos: %v", err)
+	}
+
+	if suite.db != nil {
+		suite.db.Close()
+	}
+}
+
+func (suite *ProcessingAttemptRepositoryTestSuite) TestCreateFinishAndList() {
+	// Criar um vídeo para o teste
+	video := entity.NewVideo(testOwnerID, "Teste de Tentativas", "", "/path/to/attempts.mp4")
+	err := suite.videoRepo.Create(suite.ctx, video)
+	assert.NoError(suite.T(), err)
+
+	// Registrar uma tentativa que falha e outra que termina com sucesso
+	failed := entity.NewProcessingAttempt(video.ID, "worker-1", map[string]string{"c:v": "h264"})
+	err = suite.repository.Create(suite.ctx, failed)
+	assert.NoError(suite.T(), err)
+
+	failed.MarkAsFailed("erro na conversão", "moov atom not found")
+	err = suite.repository.Finish(suite.ctx, failed)
+	assert.NoError(suite.T(), err)
+
+	succeeded := entity.NewProcessingAttempt(video.ID, "worker-2", map[string]string{"c:v": "h264"})
+	err = suite.repository.Create(suite.ctx, succeeded)
+	assert.NoError(suite.T(), err)
+
+	succeeded.MarkAsSucceeded()
+	err = suite.repository.Finish(suite.ctx, succeeded)
+	assert.NoError(suite.T(), err)
+
+	// Listar o histórico do vídeo
+	attempts, err := suite.repository.ListByVideoID(suite.ctx, video.ID)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), attempts, 2) {
+		assert.Equal(suite.T(), failed.ID, attempts[0].ID)
+		assert.Equal(suite.T(), entity.AttemptOutcomeFailed, attempts[0].Outcome)
+		assert.Equal(suite.T(), "moov atom not found", attempts[0].StderrExcerpt)
+		assert.Equal(suite.T(), "h264", attempts[0].EncodingParams["c:v"])
+		assert.NotNil(suite.T(), attempts[0].FinishedAt)
+		assert.Equal(suite.T(), entity.AttemptOutcomeSucceeded, attempts[1].Outcome)
+		assert.Equal(suite.T(), "worker-2", attempts[1].WorkerID)
+	}
+}
+
+func (suite *ProcessingAttemptRepositoryTestSuite) TestFinishNotFound() {
+	attempt := entity.NewProcessingAttempt("00000000-0000-0000-0000-000000000000", "worker-1", nil)
+	attempt.MarkAsSucceeded()
+
+	err := suite.repository.Finish(suite.ctx, attempt)
+	assert.Equal(suite.T(), ErrProcessingAttemptNotFound, err)
+}
+
+func TestProcessingAttemptRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ProcessingAttemptRepositoryTestSuite))
+}
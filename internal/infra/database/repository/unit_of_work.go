@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+)
+
+// DBTX é o conjunto de operações comum a *sql.DB e *sql.Tx usado pelos repositórios
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// txKey identifica a transação em andamento no contexto
+type txKey struct{}
+
+// conn retorna a transação em andamento no contexto ou, se não houver, a conexão do repositório
+// Todo acesso ao banco dos repositórios deve passar por aqui para participar das transações do UnitOfWork
+func conn(ctx context.Context, db *sql.DB) DBTX {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// UnitOfWorkPostgres implementa a interface UnitOfWork usando transações do PostgreSQL
+type UnitOfWorkPostgres struct {
+	db    *sql.DB
+	repos domainRepository.Repositories
+}
+
+// NewUnitOfWorkPostgres cria uma nova instância de UnitOfWorkPostgres
+func NewUnitOfWorkPostgres(db *sql.DB) *UnitOfWorkPostgres {
+	return &UnitOfWorkPostgres{
+		db: db,
+		repos: domainRepository.Repositories{
+			Videos:             NewAuditedVideoRepository(NewVideoRepositoryPostgres(db), NewVideoAuditRepositoryPostgres(db), db),
+			ProcessingAttempts: NewProcessingAttemptRepositoryPostgres(db),
+			VideoFiles:         NewVideoFileRepositoryPostgres(db),
+		},
+	}
+}
+
+// RunInTx executa fn em uma transação propagada pelo contexto
+// Se fn entrar em pânico, a transação é desfeita e o pânico é propagado
+func (u *UnitOfWorkPostgres) RunInTx(ctx context.Context, fn func(ctx context.Context, repos domainRepository.Repositories) error) error {
+	return runInTx(ctx, u.db, func(ctx context.Context) error {
+		return fn(ctx, u.repos)
+	})
+}
+
+// runInTx executa fn em uma transação de db guardada no contexto, ou na transação já em andamento
+func runInTx(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			return errors.Join(err, fmt.Errorf("erro ao desfazer transação: %w", rollbackErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+
+	return nil
+}
+
+// Ensure UnitOfWorkPostgres implements UnitOfWork
+var _ domainRepository.UnitOfWork = (*UnitOfWorkPostgres)(nil)
@@ -0,0 +1,127 @@
+//go:build integration
+// +build integration
+
+// Este arquivo contém testes de integração que acessam o banco de dados real.
+// Para executar estes testes, use o comando:
+// go test -tags=integration ./internal/infra/database/repository
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+	"github.com/devfullcycle/golangtechweek/internal/infra/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type UnitOfWorkTestSuite struct {
+	suite.Suite
+	db        *sql.DB
+	uow       *UnitOfWorkPostgres
+	videoRepo *VideoRepositoryPostgres
+	ctx       context.Context
+}
+
+func (suite *UnitOfWorkTestSuite) SetupSuite() {
+	var err error
+	suite.db, err = database.NewConnection(testDBConfig())
+	if err != nil {
+		suite.T().Fatalf("Erro ao conectar ao banco de dados: %v", err)
+	}
+
+	suite.uow = NewUnitOfWorkPostgres(suite.db)
+	suite.videoRepo = NewVideoRepositoryPostgres(suite.db)
+	suite.ctx = context.Background()
+}
+
+func (suite *UnitOfWorkTestSuite) TearDownSuite() {
+	_, err := suite.db.Exec("DELETE FROM videos")
+	if err != nil {
+		suite.T().Fatalf("Erro ao limpar a tabela de vídeos: %v", err)
+	}
+
+	if suite.db != nil {
+		suite.db.Close()
+	}
+}
+
+func (suite *UnitOfWorkTestSuite) TestCommit() {
+	video := entity.NewVideo(testOwnerID, "Transação Confirmada", "", "/path/to/tx-commit.mp4")
+
+	err := suite.uow.RunInTx(suite.ctx, func(ctx context.Context, repos domainRepository.Repositories) error {
+		if err := repos.Videos.Create(ctx, video); err != nil {
+			return err
+		}
+		if err := repos.Videos.UpdateHLSPath(ctx, video.ID, video.Version, "/hls", "/hls/playlist.m3u8"); err != nil {
+			return err
+		}
+		return repos.Videos.UpdateStatus(ctx, video.ID, video.Version+1, entity.StatusCompleted, "")
+	})
+	assert.NoError(suite.T(), err)
+
+	found, err := suite.videoRepo.FindByID(suite.ctx, video.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), entity.StatusCompleted, found.Status)
+	assert.Equal(suite.T(), "/hls/playlist.m3u8", found.ManifestPath)
+}
+
+func (suite *UnitOfWorkTestSuite) TestRollback() {
+	video := entity.NewVideo(testOwnerID, "Transação Desfeita", "", "/path/to/tx-rollback.mp4")
+	err := suite.videoRepo.Create(suite.ctx, video)
+	assert.NoError(suite.T(), err)
+
+	failure := errors.New("falha na segunda etapa")
+
+	err = suite.uow.RunInTx(suite.ctx, func(ctx context.Context, repos domainRepository.Repositories) error {
+		if err := repos.Videos.UpdateHLSPath(ctx, video.ID, video.Version, "/hls", "/hls/playlist.m3u8"); err != nil {
+			return err
+		}
+
+		// Um repositório criado fora da unidade de trabalho participa da transação pelo contexto
+		attempt := entity.NewProcessingAttempt(video.ID, "worker-1", nil)
+		if err := NewProcessingAttemptRepositoryPostgres(suite.db).Create(ctx, attempt); err != nil {
+			return err
+		}
+
+		return failure
+	})
+	assert.ErrorIs(suite.T(), err, failure)
+
+	found, err := suite.videoRepo.FindByID(suite.ctx, video.ID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), found.ManifestPath)
+
+	attempts, err := NewProcessingAttemptRepositoryPostgres(suite.db).ListByVideoID(suite.ctx, video.ID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), attempts)
+}
+
+func (suite *UnitOfWorkTestSuite) TestNestedJoinsOuterTransaction() {
+	video := entity.NewVideo(testOwnerID, "Transação Aninhada", "", "/path/to/tx-nested.mp4")
+	failure := errors.New("falha na transação externa")
+
+	err := suite.uow.RunInTx(suite.ctx, func(ctx context.Context, repos domainRepository.Repositories) error {
+		err := suite.uow.RunInTx(ctx, func(ctx context.Context, repos domainRepository.Repositories) error {
+			return repos.Videos.Create(ctx, video)
+		})
+		if err != nil {
+			return err
+		}
+		return failure
+	})
+	assert.ErrorIs(suite.T(), err, failure)
+
+	// A gravação interna é desfeita junto com a transação externa
+	_, err = suite.videoRepo.FindByID(suite.ctx, video.ID)
+	assert.ErrorIs(suite.T(), err, ErrVideoNotFound)
+}
+
+func TestUnitOfWorkTestSuite(t *testing.T) {
+	suite.Run(t, new(UnitOfWorkTestSuite))
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+)
+
+// VideoAuditRepositoryPostgres implementa a interface VideoAuditRepository usando PostgreSQL
+type VideoAuditRepositoryPostgres struct {
+	db *sql.DB
+}
+
+// NewVideoAuditRepositoryPostgres cria uma nova instância de VideoAuditRepositoryPostgres
+func NewVideoAuditRepositoryPostgres(db *sql.DB) *VideoAuditRepositoryPostgres {
+	return &VideoAuditRepositoryPostgres{
+		db: db,
+	}
+}
+
+// auditHistoryLimit aplica o padrão e o máximo de entradas por página do histórico
+func auditHistoryLimit(limit int) int {
+	if limit <= 0 {
+		return domainRepository.DefaultListLimit
+	}
+	return min(limit, domainRepository.MaxListLimit)
+}
+
+// marshalAuditChanges serializa os campos alterados, gravando um objeto vazio quando não há nenhum
+func marshalAuditChanges(changes map[string]domainRepository.FieldChange) ([]byte, error) {
+	if changes == nil {
+		changes = map[string]domainRepository.FieldChange{}
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar alterações: %w", err)
+	}
+	return data, nil
+}
+
+// Append grava uma nova entrada no histórico de auditoria
+func (r *VideoAuditRepositoryPostgres) Append(ctx context.Context, entry *domainRepository.VideoAuditEntry) error {
+	changes, err := marshalAuditChanges(entry.Changes)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO video_audit_log (video_id, action, changes, actor_type, actor_id, correlation_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	now := time.Now()
+
+	err = conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		entry.VideoID,
+		entry.Action,
+		string(changes),
+		entry.Actor.Type,
+		entry.Actor.ID,
+		entry.CorrelationID,
+		now,
+	).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar entrada de auditoria: %w", err)
+	}
+
+	entry.CreatedAt = now
+	return nil
+}
+
+// History retorna o histórico de alterações do vídeo, da entrada mais nova para a mais antiga
+func (r *VideoAuditRepositoryPostgres) History(ctx context.Context, videoID string, beforeID int64, limit int) ([]*domainRepository.VideoAuditEntry, error) {
+	query := `
+		SELECT id, video_id, action, changes, actor_type, actor_id, correlation_id, created_at
+		FROM video_audit_log
+		WHERE video_id = $1 AND ($2::bigint = 0 OR id < $2::bigint)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, videoID, beforeID, auditHistoryLimit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar histórico do vídeo: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*domainRepository.VideoAuditEntry
+
+	for rows.Next() {
+		var entry domainRepository.VideoAuditEntry
+		var changes []byte
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.VideoID,
+			&entry.Action,
+			&changes,
+			&entry.Actor.Type,
+			&entry.Actor.ID,
+			&entry.CorrelationID,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear entrada de auditoria: %w", err)
+		}
+
+		if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+			return nil, fmt.Errorf("erro ao desserializar alterações: %w", err)
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os resultados: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Ensure VideoAuditRepositoryPostgres implements VideoAuditRepository
+var _ domainRepository.VideoAuditRepository = (*VideoAuditRepositoryPostgres)(nil)
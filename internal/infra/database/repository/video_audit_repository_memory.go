@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+)
+
+// VideoAuditRepositoryMemory implementa a interface VideoAuditRepository em memória
+// Indicado para testes e execuções locais sem banco de dados
+type VideoAuditRepositoryMemory struct {
+	mu      sync.RWMutex
+	entries []domainRepository.VideoAuditEntry
+}
+
+// NewVideoAuditRepositoryMemory cria uma nova instância de VideoAuditRepositoryMemory
+func NewVideoAuditRepositoryMemory() *VideoAuditRepositoryMemory {
+	return &VideoAuditRepositoryMemory{}
+}
+
+// cloneAuditEntry copia a entrada passando as alterações por JSON, como nos repositórios com banco
+func cloneAuditEntry(src domainRepository.VideoAuditEntry) (*domainRepository.VideoAuditEntry, error) {
+	changes, err := marshalAuditChanges(src.Changes)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := src
+	entry.Changes = nil
+	if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+		return nil, fmt.Errorf("erro ao desserializar alterações: %w", err)
+	}
+	return &entry, nil
+}
+
+// Append grava uma nova entrada no histórico de auditoria
+func (r *VideoAuditRepositoryMemory) Append(ctx context.Context, entry *domainRepository.VideoAuditEntry) error {
+	stored, err := cloneAuditEntry(*entry)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored.ID = int64(len(r.entries) + 1)
+	stored.CreatedAt = time.Now()
+	r.entries = append(r.entries, *stored)
+
+	entry.ID = stored.ID
+	entry.CreatedAt = stored.CreatedAt
+	return nil
+}
+
+// History retorna o histórico de alterações do vídeo, da entrada mais nova para a mais antiga
+func (r *VideoAuditRepositoryMemory) History(ctx context.Context, videoID string, beforeID int64, limit int) ([]*domainRepository.VideoAuditEntry, error) {
+	limit = auditHistoryLimit(limit)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []*domainRepository.VideoAuditEntry
+	for i := len(r.entries) - 1; i >= 0 && len(entries) < limit; i-- {
+		stored := r.entries[i]
+		if stored.VideoID != videoID || (beforeID != 0 && stored.ID >= beforeID) {
+			continue
+		}
+
+		entry, err := cloneAuditEntry(stored)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Ensure VideoAuditRepositoryMemory implements VideoAuditRepository
+var _ domainRepository.VideoAuditRepository = (*VideoAuditRepositoryMemory)(nil)
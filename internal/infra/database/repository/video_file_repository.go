@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+)
+
+// VideoFileRepositoryPostgres implementa a interface VideoFileRepository usando PostgreSQL
+type VideoFileRepositoryPostgres struct {
+	db *sql.DB
+}
+
+// NewVideoFileRepositoryPostgres cria uma nova instância de VideoFileRepositoryPostgres
+func NewVideoFileRepositoryPostgres(db *sql.DB) *VideoFileRepositoryPostgres {
+	return &VideoFileRepositoryPostgres{
+		db: db,
+	}
+}
+
+// ReplaceForVideo remove os arquivos registrados do vídeo e grava os novos na mesma transação
+func (r *VideoFileRepositoryPostgres) ReplaceForVideo(ctx context.Context, videoID string, files []*entity.VideoFile) error {
+	return runInTx(ctx, r.db, func(ctx context.Context) error {
+		if err := r.DeleteByVideoID(ctx, videoID); err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO video_files (
+				id, video_id, file_type, sequence, local_path, size_bytes, duration_ms, checksum,
+				s3_key, upload_status, upload_error, created_at, updated_at
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+			)
+		`
+
+		for _, file := range files {
+			_, err := conn(ctx, r.db).ExecContext(
+				ctx,
+				query,
+				file.ID,
+				videoID,
+				file.Type,
+				file.Sequence,
+				file.LocalPath,
+				file.SizeBytes,
+				file.Duration.Milliseconds(),
+				file.Checksum,
+				file.S3Key,
+				file.UploadStatus,
+				file.UploadError,
+				file.CreatedAt,
+				file.UpdatedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("erro ao gravar arquivo do vídeo %s: %w", file.LocalPath, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ListByVideoID retorna os arquivos do vídeo: o manifesto primeiro e depois os segmentos, em ordem
+func (r *VideoFileRepositoryPostgres) ListByVideoID(ctx context.Context, videoID string) ([]*entity.VideoFile, error) {
+	query := `
+		SELECT
+			id, video_id, file_type, sequence, local_path, size_bytes, duration_ms, checksum,
+			s3_key, upload_status, upload_error, created_at, updated_at
+		FROM video_files
+		WHERE video_id = $1
+		ORDER BY file_type = 'manifest' DESC, sequence ASC, local_path ASC
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, videoID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar arquivos do vídeo: %w", err)
+	}
+	defer rows.Close()
+
+	var files []*entity.VideoFile
+
+	for rows.Next() {
+		var file entity.VideoFile
+		var durationMs int64
+
+		err := rows.Scan(
+			&file.ID,
+			&file.VideoID,
+			&file.Type,
+			&file.Sequence,
+			&file.LocalPath,
+			&file.SizeBytes,
+			&durationMs,
+			&file.Checksum,
+			&file.S3Key,
+			&file.UploadStatus,
+			&file.UploadError,
+			&file.CreatedAt,
+			&file.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear arquivo do vídeo: %w", err)
+		}
+
+		file.Duration = time.Duration(durationMs) * time.Millisecond
+		files = append(files, &file)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os resultados: %w", err)
+	}
+
+	return files, nil
+}
+
+// UpdateUploadStatus grava o estado do upload, a chave no S3 e o erro do arquivo
+func (r *VideoFileRepositoryPostgres) UpdateUploadStatus(ctx context.Context, file *entity.VideoFile) error {
+	query := `
+		UPDATE video_files
+		SET upload_status = $1, s3_key = $2, upload_error = $3, updated_at = $4
+		WHERE id = $5
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, file.UploadStatus, file.S3Key, file.UploadError, time.Now(), file.ID)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar upload do arquivo: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao obter linhas afetadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domainRepository.ErrVideoFileNotFound
+	}
+
+	return nil
+}
+
+// DeleteByVideoID remove os registros de todos os arquivos do vídeo
+func (r *VideoFileRepositoryPostgres) DeleteByVideoID(ctx context.Context, videoID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM video_files WHERE video_id = $1`, videoID)
+	if err != nil {
+		return fmt.Errorf("erro ao remover arquivos do vídeo: %w", err)
+	}
+
+	return nil
+}
+
+// Ensure VideoFileRepositoryPostgres implements VideoFileRepository
+var _ domainRepository.VideoFileRepository = (*VideoFileRepositoryPostgres)(nil)
@@ -0,0 +1,113 @@
+//go:build integration
+// +build integration
+
+// Este arquivo contém testes de integração que acessam o banco de dados real.
+// Para executar estes testes, use o comando:
+// go test -tags=integration ./internal/infra/database/repository
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+	"github.com/devfullcycle/golangtechweek/internal/infra/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type VideoFileRepositoryTestSuite struct {
+	suite.Suite
+	db         *sql.DB
+	repository *VideoFileRepositoryPostgres
+	videoRepo  *VideoRepositoryPostgres
+	ctx        context.Context
+}
+
+func (suite *VideoFileRepositoryTestSuite) SetupSuite() {
+	var err error
+	suite.db, err = database.NewConnection(testDBConfig())
+	if err != nil {
+		suite.T().Fatalf("Erro ao conectar ao banco de dados: %v", err)
+	}
+
+	suite.repository = NewVideoFileRepositoryPostgres(suite.db)
+	suite.videoRepo = NewVideoRepositoryPostgres(suite.db)
+	suite.ctx = context.Background()
+}
+
+func (suite *VideoFileRepositoryTestSuite) TearDownSuite() {
+	// Os arquivos são removidos em cascata junto com os vídeos
+	_, err := suite.db.Exec("DELETE FROM videos")
+	if err != nil {
+		suite.T().Fatalf("Erro ao limpar a tabela de vídeos: %v", err)
+	}
+
+	if suite.db != nil {
+		suite.db.Close()
+	}
+}
+
+func (suite *VideoFileRepositoryTestSuite) TestReplaceAndList() {
+	video := entity.NewVideo(testOwnerID, "Teste de Arquivos", "", "/path/to/files.mp4")
+	err := suite.videoRepo.Create(suite.ctx, video)
+	assert.NoError(suite.T(), err)
+
+	// Uma conversão anterior que será substituída
+	old := entity.NewVideoFile(video.ID, entity.FileTypeSegment, "/old/playlist0.ts", 0, 10, time.Second, "old")
+	err = suite.repository.ReplaceForVideo(suite.ctx, video.ID, []*entity.VideoFile{old})
+	assert.NoError(suite.T(), err)
+
+	segment1 := entity.NewVideoFile(video.ID, entity.FileTypeSegment, "/hls/playlist1.ts", 1, 200, 4500*time.Millisecond, "b")
+	segment0 := entity.NewVideoFile(video.ID, entity.FileTypeSegment, "/hls/playlist0.ts", 0, 100, 10*time.Second, "a")
+	manifest := entity.NewVideoFile(video.ID, entity.FileTypeManifest, "/hls/playlist.m3u8", 0, 50, 0, "m")
+
+	err = suite.repository.ReplaceForVideo(suite.ctx, video.ID, []*entity.VideoFile{segment1, segment0, manifest})
+	assert.NoError(suite.T(), err)
+
+	files, err := suite.repository.ListByVideoID(suite.ctx, video.ID)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), files, 3) {
+		assert.Equal(suite.T(), manifest.ID, files[0].ID)
+		assert.Equal(suite.T(), segment0.ID, files[1].ID)
+		assert.Equal(suite.T(), 10*time.Second, files[1].Duration)
+		assert.Equal(suite.T(), int64(100), files[1].SizeBytes)
+		assert.Equal(suite.T(), segment1.ID, files[2].ID)
+		assert.Equal(suite.T(), entity.FileUploadPending, files[2].UploadStatus)
+	}
+
+	// Estado do upload de um arquivo
+	segment0.MarkUploaded("videos/" + video.ID + "/playlist0.ts")
+	err = suite.repository.UpdateUploadStatus(suite.ctx, segment0)
+	assert.NoError(suite.T(), err)
+
+	files, err = suite.repository.ListByVideoID(suite.ctx, video.ID)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), files, 3) {
+		assert.Equal(suite.T(), entity.FileUploadCompleted, files[1].UploadStatus)
+		assert.Equal(suite.T(), segment0.S3Key, files[1].S3Key)
+	}
+
+	err = suite.repository.DeleteByVideoID(suite.ctx, video.ID)
+	assert.NoError(suite.T(), err)
+
+	files, err = suite.repository.ListByVideoID(suite.ctx, video.ID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), files)
+}
+
+func (suite *VideoFileRepositoryTestSuite) TestUpdateUploadStatusNotFound() {
+	file := entity.NewVideoFile("00000000-0000-0000-0000-000000000000", entity.FileTypeSegment, "/hls/x.ts", 0, 1, 0, "x")
+	file.MarkUploadFailed("timeout")
+
+	err := suite.repository.UpdateUploadStatus(suite.ctx, file)
+	assert.Equal(suite.T(), domainRepository.ErrVideoFileNotFound, err)
+}
+
+func TestVideoFileRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(VideoFileRepositoryTestSuite))
+}
@@ -5,17 +5,28 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
 	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+	"github.com/devfullcycle/golangtechweek/internal/infra/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-var ErrVideoNotFound = errors.New("vídeo não encontrado")
+// ErrVideoNotFound é mantido como alias do erro de domínio para compatibilidade com os chamadores existentes
+var ErrVideoNotFound = domainRepository.ErrVideoNotFound
+
+// uniqueViolation é o código de erro do PostgreSQL para violação de restrição UNIQUE
+const uniqueViolation = "23505"
 
 // VideoRepositoryPostgres implementa a interface VideoRepository usando PostgreSQL
 type VideoRepositoryPostgres struct {
-	db *sql.DB
+	db      *sql.DB
+	cluster *database.Cluster
 }
 
 // NewVideoRepositoryPostgres cria uma nova instância de VideoRepositoryPostgres
@@ -25,23 +36,126 @@ func NewVideoRepositoryPostgres(db *sql.DB) *VideoRepositoryPostgres {
 	}
 }
 
-// Create persiste um novo vídeo no banco de dados
-func (r *VideoRepositoryPostgres) Create(ctx context.Context, video *entity.Video) error {
-	query := `
-		INSERT INTO videos (
-			id, title, file_path, status, upload_status, hls_path, manifest_path, 
-			s3_url, s3_manifest_url, error_message, created_at, updated_at
-		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
-		)
-	`
+// NewVideoRepositoryPostgresWithCluster cria um VideoRepositoryPostgres que grava no primário do cluster
+// e faz as listagens e buscas nas réplicas de leitura
+func NewVideoRepositoryPostgresWithCluster(cluster *database.Cluster) *VideoRepositoryPostgres {
+	return &VideoRepositoryPostgres{
+		db:      cluster.Primary(),
+		cluster: cluster,
+	}
+}
 
-	_, err := r.db.ExecContext(
-		ctx,
-		query,
+// reader retorna a conexão das listagens e buscas, que toleram o atraso de replicação
+// Dentro de uma transação, usa a própria transação, que enxerga as gravações ainda não confirmadas
+func (r *VideoRepositoryPostgres) reader(ctx context.Context) DBTX {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok || r.cluster == nil {
+		return conn(ctx, r.db)
+	}
+	return r.cluster.Reader(ctx)
+}
+
+// videoColumns lista as colunas lidas em todas as consultas de vídeos, na ordem esperada por scanVideo
+const videoColumns = `
+	id, owner_id, title, COALESCE(description, ''), tags, file_path,
+	COALESCE(content_hash::text, ''), COALESCE(duplicate_of::text, ''), status, upload_status,
+	hls_path, manifest_path, s3_url, s3_manifest_url, error_message,
+	progress, processing_stage, estimated_completion_at, COALESCE(lease_owner, ''), lease_expires_at,
+	created_at, updated_at, version
+`
+
+// rowScanner abstrai *sql.Row e *sql.Rows para reaproveitar a leitura das colunas
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanVideo converte uma linha do banco de dados em uma entidade Video
+// extra recebe as colunas selecionadas após videoColumns, quando houver
+func scanVideo(row rowScanner, extra ...any) (*entity.Video, error) {
+	var video entity.Video
+	var createdAt, updatedAt time.Time
+	var estimatedCompletionAt, leaseExpiresAt sql.NullTime
+	var tags pq.StringArray
+
+	dest := []any{
+		&video.ID,
+		&video.OwnerID,
+		&video.Title,
+		&video.Description,
+		&tags,
+		&video.FilePath,
+		&video.ContentHash,
+		&video.DuplicateOfID,
+		&video.Status,
+		&video.UploadStatus,
+		&video.HLSPath,
+		&video.ManifestPath,
+		&video.S3URL,
+		&video.S3ManifestURL,
+		&video.ErrorMessage,
+		&video.Progress,
+		&video.ProcessingStage,
+		&estimatedCompletionAt,
+		&video.LeaseOwner,
+		&leaseExpiresAt,
+		&createdAt,
+		&updatedAt,
+		&video.Version,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if estimatedCompletionAt.Valid {
+		video.EstimatedCompletionAt = &estimatedCompletionAt.Time
+	}
+	if leaseExpiresAt.Valid {
+		video.LeaseExpiresAt = &leaseExpiresAt.Time
+	}
+	video.Tags = []string(tags)
+	video.CreatedAt = createdAt
+	video.UpdatedAt = updatedAt
+
+	return &video, nil
+}
+
+// videoInsertColumns são as colunas gravadas na criação de vídeos, na ordem de videoInsertArgs
+const videoInsertColumns = `id, owner_id, title, description, tags, file_path, content_hash, duplicate_of, status, upload_status,
+	hls_path, manifest_path, s3_url, s3_manifest_url, error_message, progress, processing_stage,
+	estimated_completion_at, lease_owner, lease_expires_at, created_at, updated_at, version`
+
+// videoInsertColumnCount é o número de colunas (e de parâmetros) por vídeo inserido
+const videoInsertColumnCount = 23
+
+// videoInsertRows é o máximo de vídeos por INSERT em CreateMany, abaixo do limite de 65535 parâmetros
+const videoInsertRows = 1000
+
+// videoInsertRow retorna a tupla VALUES de um vídeo cujos parâmetros começam após offset
+func videoInsertRow(offset int) string {
+	values := make([]string, videoInsertColumnCount)
+	for i := range values {
+		values[i] = fmt.Sprintf("$%d", offset+i+1)
+	}
+
+	// content_hash, duplicate_of e lease_owner vazios são gravados como NULL
+	values[6] = fmt.Sprintf("NULLIF(%s, '')", values[6])
+	values[7] = fmt.Sprintf("NULLIF(%s, '')::uuid", values[7])
+	values[18] = fmt.Sprintf("NULLIF(%s, '')", values[18])
+
+	return "(" + strings.Join(values, ", ") + ")"
+}
+
+// videoInsertArgs retorna os parâmetros de um vídeo na ordem de videoInsertColumns
+func videoInsertArgs(video *entity.Video) []any {
+	return []any{
 		video.ID,
+		video.OwnerID,
 		video.Title,
+		video.Description,
+		pq.Array(entity.NormalizeTags(video.Tags)),
 		video.FilePath,
+		video.ContentHash,
+		video.DuplicateOfID,
 		video.Status,
 		video.UploadStatus,
 		video.HLSPath,
@@ -49,10 +163,31 @@ func (r *VideoRepositoryPostgres) Create(ctx context.Context, video *entity.Vide
 		video.S3URL,
 		video.S3ManifestURL,
 		video.ErrorMessage,
+		video.Progress,
+		video.ProcessingStage,
+		video.EstimatedCompletionAt,
+		video.LeaseOwner,
+		video.LeaseExpiresAt,
 		video.CreatedAt,
 		video.UpdatedAt,
-	)
+		max(video.Version, 1),
+	}
+}
+
+// Create persiste um novo vídeo no banco de dados
+func (r *VideoRepositoryPostgres) Create(ctx context.Context, video *entity.Video) error {
+	if err := video.Validate(); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO videos (` + videoInsertColumns + `) VALUES ` + videoInsertRow(0)
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, videoInsertArgs(video)...)
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == "videos_pkey" {
+			return fmt.Errorf("erro ao criar vídeo: %w", domainRepository.ErrVideoAlreadyExists)
+		}
 		return fmt.Errorf("erro ao criar vídeo: %w", err)
 	}
 
@@ -61,31 +196,12 @@ func (r *VideoRepositoryPostgres) Create(ctx context.Context, video *entity.Vide
 
 // FindByID busca um vídeo pelo seu ID
 func (r *VideoRepositoryPostgres) FindByID(ctx context.Context, id string) (*entity.Video, error) {
-	query := `
-		SELECT 
-			id, title, file_path, status, upload_status, hls_path, manifest_path, 
-			s3_url, s3_manifest_url, error_message, created_at, updated_at
+	query := `SELECT ` + videoColumns + `
 		FROM videos
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	var video entity.Video
-	var createdAt, updatedAt time.Time
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&video.ID,
-		&video.Title,
-		&video.FilePath,
-		&video.Status,
-		&video.UploadStatus,
-		&video.HLSPath,
-		&video.ManifestPath,
-		&video.S3URL,
-		&video.S3ManifestURL,
-		&video.ErrorMessage,
-		&createdAt,
-		&updatedAt,
-	)
+	video, err := scanVideo(conn(ctx, r.db).QueryRowContext(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrVideoNotFound
@@ -93,14 +209,246 @@ func (r *VideoRepositoryPostgres) FindByID(ctx context.Context, id string) (*ent
 		return nil, fmt.Errorf("erro ao buscar vídeo: %w", err)
 	}
 
-	video.CreatedAt = createdAt
-	video.UpdatedAt = updatedAt
+	return video, nil
+}
 
-	return &video, nil
+// ListVideos retorna uma página de vídeos filtrada, ordenada e paginada por cursor (keyset pagination)
+// Em vez de OFFSET, a página seguinte começa após o par (coluna de ordenação, id) do último item,
+// o que mantém o custo constante em tabelas grandes
+func (r *VideoRepositoryPostgres) ListVideos(ctx context.Context, query domainRepository.ListVideosQuery) (*domainRepository.VideoPage, error) {
+	query, err := query.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	var args queryArgs
+	where := videoQueryConditions(query, &args)
+	filterArgs := len(args)
+
+	// O título é comparado pela ordem dos bytes (collation "C"), como no SQLite e no repositório em memória
+	sortColumn := string(query.SortBy)
+	if query.SortBy == domainRepository.SortByTitle {
+		sortColumn = `title COLLATE "C"`
+	}
+	comparison, order := "<", "DESC"
+	if query.Direction == domainRepository.SortAsc {
+		comparison, order = ">", "ASC"
+	}
+
+	conditions := where
+	if query.Cursor != "" {
+		cursor, err := domainRepository.DecodeVideoCursor(query.Cursor, query.SortBy, query.Direction)
+		if err != nil {
+			return nil, err
+		}
+
+		var value any = cursor.Value
+		if query.SortBy != domainRepository.SortByTitle {
+			if value, err = cursor.TimeValue(); err != nil {
+				return nil, err
+			}
+		}
+
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s, %s::uuid)", sortColumn, comparison, args.add(value), args.add(cursor.ID)))
+	}
+
+	// Um item a mais indica se existe uma próxima página
+	sqlQuery := `SELECT ` + videoColumns + `
+		FROM videos
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + sortColumn + ` ` + order + `, id ` + order + `
+		LIMIT ` + args.add(query.Limit+1)
+
+	// A página e o total são lidos do mesmo banco, para que o atraso entre réplicas não os torne incoerentes
+	reader := r.reader(ctx)
+
+	rows, err := reader.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar vídeos: %w", err)
+	}
+	defer rows.Close()
+
+	page := &domainRepository.VideoPage{}
+
+	for rows.Next() {
+		video, err := scanVideo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear vídeo: %w", err)
+		}
+
+		page.Videos = append(page.Videos, video)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os resultados: %w", err)
+	}
+
+	if len(page.Videos) > query.Limit {
+		page.Videos = page.Videos[:query.Limit]
+		last := page.Videos[len(page.Videos)-1]
+		page.NextCursor = domainRepository.NewVideoCursor(last, query.SortBy, query.Direction).Encode()
+	}
+
+	if query.IncludeTotal {
+		countQuery := `SELECT COUNT(*) FROM videos WHERE ` + strings.Join(where, " AND ")
+
+		var total int64
+		if err := reader.QueryRowContext(ctx, countQuery, args[:filterArgs]...).Scan(&total); err != nil {
+			return nil, fmt.Errorf("erro ao contar vídeos: %w", err)
+		}
+		page.TotalCount = &total
+	}
+
+	return page, nil
 }
 
-// List retorna uma lista de vídeos com paginação
-func (r *VideoRepositoryPostgres) List(ctx context.Context, page, pageSize int) ([]*entity.Video, error) {
+// videoQueryConditions monta as condições do WHERE correspondentes aos filtros da consulta
+func videoQueryConditions(query domainRepository.ListVideosQuery, args *queryArgs) []string {
+	conditions := []string{"deleted_at IS NULL"}
+
+	if query.OwnerID != "" {
+		conditions = append(conditions, "owner_id = "+args.add(query.OwnerID))
+	}
+	if len(query.Statuses) > 0 {
+		conditions = append(conditions, "status = ANY("+args.add(pq.Array(query.Statuses))+"::text[])")
+	}
+	if len(query.UploadStatuses) > 0 {
+		conditions = append(conditions, "upload_status = ANY("+args.add(pq.Array(query.UploadStatuses))+"::text[])")
+	}
+	if len(query.Tags) > 0 {
+		conditions = append(conditions, "tags @> "+args.add(pq.Array(query.Tags))+"::text[]")
+	}
+	if title := strings.TrimSpace(query.TitleContains); title != "" {
+		conditions = append(conditions, "title ILIKE "+args.add("%"+escapeLike(title)+"%"))
+	}
+	if query.CreatedFrom != nil {
+		conditions = append(conditions, "created_at >= "+args.add(*query.CreatedFrom))
+	}
+	if query.CreatedUntil != nil {
+		conditions = append(conditions, "created_at < "+args.add(*query.CreatedUntil))
+	}
+	if query.UpdatedFrom != nil {
+		conditions = append(conditions, "updated_at >= "+args.add(*query.UpdatedFrom))
+	}
+	if query.UpdatedUntil != nil {
+		conditions = append(conditions, "updated_at < "+args.add(*query.UpdatedUntil))
+	}
+
+	return conditions
+}
+
+// queryArgs acumula os parâmetros de uma consulta montada dinamicamente
+type queryArgs []any
+
+// add registra um parâmetro e retorna seu placeholder ($1, $2, ...)
+func (a *queryArgs) add(value any) string {
+	*a = append(*a, value)
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+// escapeLike escapa os curingas do LIKE para que o trecho seja buscado literalmente
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// Opções do ts_headline: o título é destacado por inteiro e a descrição é reduzida a um trecho
+var (
+	titleHeadlineOptions       = "HighlightAll=true, StartSel=" + domainRepository.HighlightStart + ", StopSel=" + domainRepository.HighlightEnd
+	descriptionHeadlineOptions = "MaxWords=35, MinWords=15, StartSel=" + domainRepository.HighlightStart + ", StopSel=" + domainRepository.HighlightEnd
+)
+
+// Search busca vídeos pela coluna search_vector
+// Todos os termos de SearchTerms precisam aparecer, como no SQLite; a relevância é calculada por ts_rank_cd
+// e os destaques só são gerados para os vídeos da página, por serem caros
+func (r *VideoRepositoryPostgres) Search(ctx context.Context, query string, filters domainRepository.VideoSearchFilters, cursor string) (*domainRepository.VideoSearchPage, error) {
+	terms := domainRepository.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, domainRepository.ErrEmptySearchQuery
+	}
+
+	searchCursor, err := domainRepository.DecodeVideoSearchCursor(cursor, query, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	filters = filters.Normalize()
+
+	// Cada termo entre aspas simples é tratado literalmente pelo to_tsquery; & exige todos os termos
+	tsQuery := "'" + strings.Join(terms, "' & '") + "'"
+
+	var args queryArgs
+	tsQueryArg := args.add(tsQuery)
+	conditions := append(videoQueryConditions(filters.ListQuery(), &args), "search_vector @@ q")
+
+	// Um item a mais indica se existe uma próxima página
+	sqlQuery := `WITH matches AS (
+			SELECT id, ts_rank_cd(search_vector, q) AS rank, q
+			FROM videos, to_tsquery('simple', ` + tsQueryArg + `) AS q
+			WHERE ` + strings.Join(conditions, " AND ") + `
+			ORDER BY rank DESC, id
+			LIMIT ` + args.add(filters.Limit+1) + ` OFFSET ` + args.add(searchCursor.Offset) + `
+		)
+		SELECT ` + videoColumns + `, matches.rank,
+			ts_headline('simple', title, matches.q, ` + args.add(titleHeadlineOptions) + `),
+			ts_headline('simple', COALESCE(description, ''), matches.q, ` + args.add(descriptionHeadlineOptions) + `)
+		FROM matches
+		JOIN videos USING (id)
+		ORDER BY matches.rank DESC, id`
+
+	rows, err := r.reader(ctx).QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar vídeos: %w", err)
+	}
+	defer rows.Close()
+
+	page := &domainRepository.VideoSearchPage{}
+
+	for rows.Next() {
+		var result domainRepository.VideoSearchResult
+
+		result.Video, err = scanVideo(rows, &result.Rank, &result.TitleHighlight, &result.DescriptionSnippet)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear vídeo: %w", err)
+		}
+
+		page.Results = append(page.Results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os resultados: %w", err)
+	}
+
+	if len(page.Results) > filters.Limit {
+		page.Results = page.Results[:filters.Limit]
+		page.NextCursor = domainRepository.NewVideoSearchCursor(query, filters, searchCursor.Offset+filters.Limit).Encode()
+	}
+
+	return page, nil
+}
+
+// FindCompletedByContentHash busca um vídeo concluído da conta de cliente com a mesma impressão digital
+// Vídeos originais têm preferência sobre duplicatas e, entre eles, o mais antigo é retornado
+func (r *VideoRepositoryPostgres) FindCompletedByContentHash(ctx context.Context, ownerID, contentHash string) (*entity.Video, error) {
+	query := `SELECT ` + videoColumns + `
+		FROM videos
+		WHERE owner_id = $1 AND content_hash = $2 AND status = $3 AND deleted_at IS NULL
+		ORDER BY (duplicate_of IS NULL) DESC, created_at ASC
+		LIMIT 1
+	`
+
+	video, err := scanVideo(conn(ctx, r.db).QueryRowContext(ctx, query, ownerID, contentHash, entity.StatusCompleted))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrVideoNotFound
+		}
+		return nil, fmt.Errorf("erro ao buscar vídeo pela impressão digital: %w", err)
+	}
+
+	return video, nil
+}
+
+// List retorna uma lista de vídeos com paginação, filtrada por dono e tags
+func (r *VideoRepositoryPostgres) List(ctx context.Context, filter domainRepository.VideoFilter, page, pageSize int) ([]*entity.Video, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -110,17 +458,19 @@ func (r *VideoRepositoryPostgres) List(ctx context.Context, page, pageSize int)
 
 	offset := (page - 1) * pageSize
 
-	query := `
-		SELECT 
-			id, title, file_path, status, upload_status, hls_path, manifest_path, 
-			s3_url, s3_manifest_url, error_message, created_at, updated_at
+	// Filtros vazios são ignorados pelas condições "$n = ''" e "cardinality($n) = 0"
+	tags := entity.NormalizeTags(filter.Tags)
+
+	query := `SELECT ` + videoColumns + `
 		FROM videos
 		WHERE deleted_at IS NULL
+			AND ($1 = '' OR owner_id = $1)
+			AND (cardinality($2::text[]) = 0 OR tags @> $2::text[])
 		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
+		LIMIT $3 OFFSET $4
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, pageSize, offset)
+	rows, err := r.reader(ctx).QueryContext(ctx, query, filter.OwnerID, pq.Array(tags), pageSize, offset)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao listar vídeos: %w", err)
 	}
@@ -129,31 +479,12 @@ func (r *VideoRepositoryPostgres) List(ctx context.Context, page, pageSize int)
 	var videos []*entity.Video
 
 	for rows.Next() {
-		var video entity.Video
-		var createdAt, updatedAt time.Time
-
-		err := rows.Scan(
-			&video.ID,
-			&video.Title,
-			&video.FilePath,
-			&video.Status,
-			&video.UploadStatus,
-			&video.HLSPath,
-			&video.ManifestPath,
-			&video.S3URL,
-			&video.S3ManifestURL,
-			&video.ErrorMessage,
-			&createdAt,
-			&updatedAt,
-		)
+		video, err := scanVideo(rows)
 		if err != nil {
 			return nil, fmt.Errorf("erro ao escanear vídeo: %w", err)
 		}
 
-		video.CreatedAt = createdAt
-		video.UpdatedAt = updatedAt
-
-		videos = append(videos, &video)
+		videos = append(videos, video)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -163,42 +494,135 @@ func (r *VideoRepositoryPostgres) List(ctx context.Context, page, pageSize int)
 	return videos, nil
 }
 
-// UpdateStatus atualiza o status de um vídeo e a mensagem de erro (quando aplicável)
-func (r *VideoRepositoryPostgres) UpdateStatus(ctx context.Context, id string, status string, errorMessage string) error {
+// Update grava todos os campos editáveis do vídeo se a versão no banco ainda for video.Version
+func (r *VideoRepositoryPostgres) Update(ctx context.Context, video *entity.Video) error {
+	if err := video.Validate(); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE videos
-		SET status = $1, error_message = $2, updated_at = $3
-		WHERE id = $4 AND deleted_at IS NULL
+		SET title = $1, description = $2, tags = $3, file_path = $4, content_hash = NULLIF($5, ''),
+			duplicate_of = NULLIF($6, '')::uuid, status = $7, upload_status = $8, hls_path = $9, manifest_path = $10,
+			s3_url = $11, s3_manifest_url = $12, error_message = $13, progress = $14, processing_stage = $15,
+			estimated_completion_at = $16, lease_owner = NULLIF($17, ''), lease_expires_at = $18,
+			updated_at = $19, version = version + 1, ` + statusTimestampsSet("$7", "$19") + `
+		WHERE id = $20 AND version = $21 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, status, errorMessage, time.Now(), id)
+	now := time.Now()
+
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		video.Title,
+		video.Description,
+		pq.Array(entity.NormalizeTags(video.Tags)),
+		video.FilePath,
+		video.ContentHash,
+		video.DuplicateOfID,
+		video.Status,
+		video.UploadStatus,
+		video.HLSPath,
+		video.ManifestPath,
+		video.S3URL,
+		video.S3ManifestURL,
+		video.ErrorMessage,
+		min(max(video.Progress, 0), 100),
+		video.ProcessingStage,
+		video.EstimatedCompletionAt,
+		video.LeaseOwner,
+		video.LeaseExpiresAt,
+		now,
+		video.ID,
+		video.Version,
+	)
 	if err != nil {
-		return fmt.Errorf("erro ao atualizar status do vídeo: %w", err)
+		return fmt.Errorf("erro ao atualizar vídeo: %w", err)
+	}
+
+	if err := r.checkVersionedWrite(ctx, result, video.ID); err != nil {
+		return err
 	}
 
+	video.Version++
+	video.UpdatedAt = now
+
+	return nil
+}
+
+// checkVersionedWrite confere o resultado de uma gravação condicionada à versão do vídeo
+// Quando nenhuma linha é afetada, uma segunda consulta distingue vídeo inexistente de conflito de versão
+func (r *VideoRepositoryPostgres) checkVersionedWrite(ctx context.Context, result sql.Result, id string) error {
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("erro ao obter linhas afetadas: %w", err)
 	}
 
-	if rowsAffected == 0 {
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	var exists bool
+	err = conn(ctx, r.db).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM videos WHERE id = $1 AND deleted_at IS NULL)`, id).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("erro ao verificar existência do vídeo: %w", err)
+	}
+	if !exists {
 		return ErrVideoNotFound
 	}
+	return domainRepository.ErrConcurrentModification
+}
 
-	return nil
+// statusTimestampsSet retorna as atribuições de processing_started_at e completed_at para a transição ao status
+// do parâmetro status no instante do parâmetro now; um vídeo que volta a ser processado perde a conclusão anterior
+// Serve aos dois bancos: dentro do SET, status ainda é o valor anterior à atualização
+func statusTimestampsSet(status, now string) string {
+	return `processing_started_at = CASE WHEN ` + status + ` = '` + entity.StatusProcessing + `' AND status <> '` + entity.StatusProcessing + `'
+			THEN ` + now + ` ELSE processing_started_at END,
+		completed_at = CASE WHEN ` + status + ` = '` + entity.StatusCompleted + `' AND status <> '` + entity.StatusCompleted + `' THEN ` + now + `
+			WHEN ` + status + ` = '` + entity.StatusProcessing + `' THEN NULL ELSE completed_at END`
 }
 
-// UpdateHLSPath atualiza os caminhos HLS de um vídeo
-func (r *VideoRepositoryPostgres) UpdateHLSPath(ctx context.Context, id string, hlsPath, manifestPath string) error {
+// updateStatusSet é a cláusula SET de UpdateStatus e UpdateStatusMany: $1 é o status, $2 a mensagem de erro
+// e $3 a data de atualização
+var updateStatusSet = `
+	SET status = $1, error_message = $2, updated_at = $3, version = version + 1,
+		progress = CASE $1 WHEN 'processing' THEN 0 WHEN 'completed' THEN 100 ELSE progress END,
+		processing_stage = CASE $1 WHEN 'processing' THEN 'transcoding' ELSE '' END,
+		estimated_completion_at = NULL,
+		lease_owner = CASE $1 WHEN 'processing' THEN lease_owner END,
+		lease_expires_at = CASE $1 WHEN 'processing' THEN lease_expires_at END,
+		` + statusTimestampsSet("$1", "$3")
+
+// UpdateStatus atualiza o status de um vídeo e a mensagem de erro (quando aplicável)
+// O progresso acompanha a transição de status, da mesma forma que os métodos Mark* da entidade,
+// e a concessão de processamento é encerrada quando o vídeo sai de "processing"
+func (r *VideoRepositoryPostgres) UpdateStatus(ctx context.Context, id string, expectedVersion int64, status string, errorMessage string) error {
+	query := `UPDATE videos ` + updateStatusSet + ` WHERE id = $4 AND version = $5 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, status, errorMessage, time.Now(), id, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar status do vídeo: %w", err)
+	}
+
+	return r.checkVersionedWrite(ctx, result, id)
+}
+
+// UpdateProgress atualiza o progresso, a etapa e a estimativa de término da conversão
+// Assim como as demais gravações, atualiza updated_at; a versão não é incrementada
+func (r *VideoRepositoryPostgres) UpdateProgress(ctx context.Context, id string, progress int, stage string, estimatedCompletionAt *time.Time) error {
 	query := `
 		UPDATE videos
-		SET hls_path = $1, manifest_path = $2, updated_at = $3
-		WHERE id = $4 AND deleted_at IS NULL
+		SET progress = $1, processing_stage = $2, estimated_completion_at = $3, updated_at = $4
+		WHERE id = $5 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, hlsPath, manifestPath, time.Now(), id)
+	progress = min(max(progress, 0), 100)
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, progress, stage, estimatedCompletionAt, time.Now(), id)
 	if err != nil {
-		return fmt.Errorf("erro ao atualizar caminhos HLS do vídeo: %w", err)
+		return fmt.Errorf("erro ao atualizar progresso do vídeo: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -213,42 +637,123 @@ func (r *VideoRepositoryPostgres) UpdateHLSPath(ctx context.Context, id string,
 	return nil
 }
 
+// UpdateHLSPath atualiza os caminhos HLS de um vídeo
+func (r *VideoRepositoryPostgres) UpdateHLSPath(ctx context.Context, id string, expectedVersion int64, hlsPath, manifestPath string) error {
+	query := `
+		UPDATE videos
+		SET hls_path = $1, manifest_path = $2, updated_at = $3, version = version + 1
+		WHERE id = $4 AND version = $5 AND deleted_at IS NULL
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, hlsPath, manifestPath, time.Now(), id, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar caminhos HLS do vídeo: %w", err)
+	}
+
+	return r.checkVersionedWrite(ctx, result, id)
+}
+
 // UpdateS3Status atualiza o status de upload para S3 de um vídeo
-func (r *VideoRepositoryPostgres) UpdateS3Status(ctx context.Context, id string, uploadStatus string) error {
+func (r *VideoRepositoryPostgres) UpdateS3Status(ctx context.Context, id string, expectedVersion int64, uploadStatus string) error {
 	query := `
 		UPDATE videos
-		SET upload_status = $1, updated_at = $2
-		WHERE id = $3 AND deleted_at IS NULL
+		SET upload_status = $1, updated_at = $2, version = version + 1
+		WHERE id = $3 AND version = $4 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, uploadStatus, time.Now(), id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, uploadStatus, time.Now(), id, expectedVersion)
 	if err != nil {
 		return fmt.Errorf("erro ao atualizar status de upload do vídeo: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	return r.checkVersionedWrite(ctx, result, id)
+}
+
+// UpdateS3URLs atualiza as URLs do S3 de um vídeo
+func (r *VideoRepositoryPostgres) UpdateS3URLs(ctx context.Context, id string, expectedVersion int64, s3URL, s3ManifestURL string) error {
+	query := `
+		UPDATE videos
+		SET s3_url = $1, s3_manifest_url = $2, updated_at = $3, version = version + 1
+		WHERE id = $4 AND version = $5 AND deleted_at IS NULL
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, s3URL, s3ManifestURL, time.Now(), id, expectedVersion)
 	if err != nil {
-		return fmt.Errorf("erro ao obter linhas afetadas: %w", err)
+		return fmt.Errorf("erro ao atualizar URLs do S3 do vídeo: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return ErrVideoNotFound
+	return r.checkVersionedWrite(ctx, result, id)
+}
+
+// UpdateS3Keys atualiza as chaves do S3 de um vídeo
+func (r *VideoRepositoryPostgres) UpdateS3Keys(ctx context.Context, id string, expectedVersion int64, segmentKey string, manifestKey string) error {
+	query := `
+		UPDATE videos
+		SET segment_key = $1, manifest_key = $2, updated_at = $3, version = version + 1
+		WHERE id = $4 AND version = $5 AND deleted_at IS NULL
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, segmentKey, manifestKey, time.Now(), id, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar chaves do S3 do vídeo: %w", err)
 	}
 
-	return nil
+	return r.checkVersionedWrite(ctx, result, id)
 }
 
-// UpdateS3URLs atualiza as URLs do S3 de um vídeo
-func (r *VideoRepositoryPostgres) UpdateS3URLs(ctx context.Context, id string, s3URL, s3ManifestURL string) error {
+// ClaimNextPending reivindica o vídeo disponível mais antigo para o worker
+// FOR UPDATE SKIP LOCKED faz workers concorrentes pularem a linha que outro worker está reivindicando,
+// em vez de esperar por ela ou reivindicá-la em duplicidade
+func (r *VideoRepositoryPostgres) ClaimNextPending(ctx context.Context, workerID string, leaseDuration time.Duration) (*entity.Video, error) {
+	query := `
+		UPDATE videos
+		SET status = $1, lease_owner = $2, lease_expires_at = $3, progress = 0, processing_stage = $4,
+			estimated_completion_at = NULL, error_message = '', updated_at = $5, version = version + 1,
+			processing_started_at = $5, completed_at = NULL
+		WHERE id = (
+			SELECT id
+			FROM videos
+			WHERE deleted_at IS NULL
+				AND (status = $6 OR (status = $1 AND lease_expires_at < $5))
+			ORDER BY created_at ASC, id ASC
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + videoColumns
+
+	now := time.Now()
+
+	video, err := scanVideo(conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		entity.StatusProcessing,
+		workerID,
+		now.Add(leaseDuration),
+		entity.ProcessingStageTranscoding,
+		now,
+		entity.StatusPending,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domainRepository.ErrNoPendingVideo
+		}
+		return nil, fmt.Errorf("erro ao reivindicar vídeo pendente: %w", err)
+	}
+
+	return video, nil
+}
+
+// RenewLease estende a concessão do worker sobre um vídeo em processamento
+func (r *VideoRepositoryPostgres) RenewLease(ctx context.Context, id, workerID string, leaseDuration time.Duration) error {
 	query := `
 		UPDATE videos
-		SET s3_url = $1, s3_manifest_url = $2, updated_at = $3
-		WHERE id = $4 AND deleted_at IS NULL
+		SET lease_expires_at = $1
+		WHERE id = $2 AND lease_owner = $3 AND status = $4 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, s3URL, s3ManifestURL, time.Now(), id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now().Add(leaseDuration), id, workerID, entity.StatusProcessing)
 	if err != nil {
-		return fmt.Errorf("erro ao atualizar URLs do S3 do vídeo: %w", err)
+		return fmt.Errorf("erro ao renovar concessão do vídeo: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -257,23 +762,27 @@ func (r *VideoRepositoryPostgres) UpdateS3URLs(ctx context.Context, id string, s
 	}
 
 	if rowsAffected == 0 {
-		return ErrVideoNotFound
+		return domainRepository.ErrLeaseNotHeld
 	}
 
 	return nil
 }
 
-// UpdateS3Keys atualiza as chaves do S3 de um vídeo
-func (r *VideoRepositoryPostgres) UpdateS3Keys(ctx context.Context, id string, segmentKey string, manifestKey string) error {
+// ReleaseLease encerra a concessão do worker, devolvendo à fila um vídeo que ainda não terminou
+func (r *VideoRepositoryPostgres) ReleaseLease(ctx context.Context, id, workerID string) error {
 	query := `
 		UPDATE videos
-		SET segment_key = $1, manifest_key = $2, updated_at = $3
-		WHERE id = $4 AND deleted_at IS NULL
+		SET lease_owner = NULL, lease_expires_at = NULL,
+			status = CASE WHEN status = $1 THEN $2 ELSE status END,
+			processing_stage = CASE WHEN status = $1 THEN '' ELSE processing_stage END,
+			estimated_completion_at = NULL,
+			updated_at = $3, version = version + 1
+		WHERE id = $4 AND lease_owner = $5 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, segmentKey, manifestKey, time.Now(), id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, entity.StatusProcessing, entity.StatusPending, time.Now(), id, workerID)
 	if err != nil {
-		return fmt.Errorf("erro ao atualizar chaves do S3 do vídeo: %w", err)
+		return fmt.Errorf("erro ao liberar concessão do vídeo: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -282,36 +791,526 @@ func (r *VideoRepositoryPostgres) UpdateS3Keys(ctx context.Context, id string, s
 	}
 
 	if rowsAffected == 0 {
-		return ErrVideoNotFound
+		return domainRepository.ErrLeaseNotHeld
 	}
 
 	return nil
 }
 
-// Delete remove um vídeo do repositório (soft delete)
-func (r *VideoRepositoryPostgres) Delete(ctx context.Context, id string) error {
+// ListStaleProcessing lista os vídeos em processamento cuja concessão (ou, sem ela, a última atualização)
+// é anterior a staleBefore
+func (r *VideoRepositoryPostgres) ListStaleProcessing(ctx context.Context, staleBefore time.Time, limit int) ([]*entity.Video, error) {
+	if limit < 1 {
+		limit = 10
+	}
+
+	query := `SELECT ` + videoColumns + `
+		FROM videos
+		WHERE deleted_at IS NULL
+			AND status = $1
+			AND COALESCE(lease_expires_at, updated_at) < $2
+		ORDER BY COALESCE(lease_expires_at, updated_at) ASC, id ASC
+		LIMIT $3
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, entity.StatusProcessing, staleBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar vídeos abandonados: %w", err)
+	}
+	defer rows.Close()
+
+	var videos []*entity.Video
+
+	for rows.Next() {
+		video, err := scanVideo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear vídeo: %w", err)
+		}
+
+		videos = append(videos, video)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os resultados: %w", err)
+	}
+
+	return videos, nil
+}
+
+// validUUIDs descarta os IDs que não são UUIDs, que o PostgreSQL rejeitaria em vez de não encontrar
+func validUUIDs(ids []string) []string {
+	valid := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err == nil {
+			valid = append(valid, id)
+		}
+	}
+	return valid
+}
+
+// queryIDs executa uma consulta que retorna uma coluna de IDs
+func (r *VideoRepositoryPostgres) queryIDs(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// FindManyByID busca os vídeos pelos IDs em uma única consulta
+func (r *VideoRepositoryPostgres) FindManyByID(ctx context.Context, ids []string) ([]*entity.Video, error) {
+	query := `SELECT ` + videoColumns + `
+		FROM videos
+		WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, pq.Array(validUUIDs(ids)))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar vídeos: %w", err)
+	}
+	defer rows.Close()
+
+	var videos []*entity.Video
+
+	for rows.Next() {
+		video, err := scanVideo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear vídeo: %w", err)
+		}
+
+		videos = append(videos, video)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os resultados: %w", err)
+	}
+
+	return orderByIDs(videos, ids), nil
+}
+
+// CreateMany insere os vídeos com INSERT de várias linhas, em lotes de videoInsertRows
+// ON CONFLICT DO NOTHING mantém a transação válida quando um ID já existe; os IDs ausentes do RETURNING são os repetidos
+func (r *VideoRepositoryPostgres) CreateMany(ctx context.Context, videos []*entity.Video) ([]domainRepository.BulkResult, error) {
+	results, candidates := prepareBulkCreate(videos)
+	inserted := make(map[string]bool, len(candidates))
+
+	err := runInTx(ctx, r.db, func(ctx context.Context) error {
+		for batch := range slices.Chunk(candidates, videoInsertRows) {
+			rows := make([]string, len(batch))
+			args := make([]any, 0, len(batch)*videoInsertColumnCount)
+			for i, video := range batch {
+				rows[i] = videoInsertRow(len(args))
+				args = append(args, videoInsertArgs(video)...)
+			}
+
+			query := `INSERT INTO videos (` + videoInsertColumns + `) VALUES ` + strings.Join(rows, ", ") + `
+				ON CONFLICT (id) DO NOTHING
+				RETURNING id`
+
+			ids, err := r.queryIDs(ctx, query, args...)
+			if err != nil {
+				return fmt.Errorf("erro ao criar vídeos: %w", err)
+			}
+			for _, id := range ids {
+				inserted[id] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	markBulkResults(results, inserted, domainRepository.ErrVideoAlreadyExists)
+	return results, nil
+}
+
+// UpdateStatusMany atualiza o status de todos os vídeos em um único UPDATE
+func (r *VideoRepositoryPostgres) UpdateStatusMany(ctx context.Context, ids []string, status string) ([]domainRepository.BulkResult, error) {
+	query := `UPDATE videos ` + updateStatusSet + `
+		WHERE id = ANY($4::uuid[]) AND deleted_at IS NULL
+		RETURNING id`
+
+	updated, err := r.queryIDs(ctx, query, status, "", time.Now(), pq.Array(validUUIDs(ids)))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao atualizar status dos vídeos: %w", err)
+	}
+
+	applied := make(map[string]bool, len(updated))
+	for _, id := range updated {
+		applied[id] = true
+	}
+
+	results := newBulkResults(ids)
+	markBulkResults(results, applied, domainRepository.ErrVideoNotFound)
+	return results, nil
+}
+
+// RequeueFailed devolve para a fila os vídeos com falha que atendem ao filtro em um único UPDATE
+func (r *VideoRepositoryPostgres) RequeueFailed(ctx context.Context, filter domainRepository.RequeueFailedFilter) ([]domainRepository.BulkResult, error) {
+	var failedBefore *time.Time
+	if !filter.FailedBefore.IsZero() {
+		failedBefore = &filter.FailedBefore
+	}
+
+	// LIMIT NULL não limita
+	var limit *int
+	if filter.Limit > 0 && len(filter.VideoIDs) == 0 {
+		limit = &filter.Limit
+	}
+
 	query := `
 		UPDATE videos
-		SET deleted_at = $1
-		WHERE id = $2 AND deleted_at IS NULL
+		SET status = $1, error_message = '', progress = 0, processing_stage = '', estimated_completion_at = NULL,
+			lease_owner = NULL, lease_expires_at = NULL, updated_at = $2, version = version + 1
+		WHERE id IN (
+			SELECT id
+			FROM videos
+			WHERE deleted_at IS NULL
+				AND status = $3
+				AND ($4 = '' OR owner_id = $4)
+				AND ($5::boolean = false OR id = ANY($6::uuid[]))
+				AND ($7::timestamp IS NULL OR updated_at < $7)
+			ORDER BY updated_at ASC, id ASC
+			LIMIT $8
+			FOR UPDATE
+		)
+		RETURNING id
 	`
 
-	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	var results []domainRepository.BulkResult
+
+	err := runInTx(ctx, r.db, func(ctx context.Context) error {
+		requeued, err := r.queryIDs(
+			ctx,
+			query,
+			entity.StatusPending,
+			time.Now(),
+			entity.StatusError,
+			filter.OwnerID,
+			len(filter.VideoIDs) > 0,
+			pq.Array(validUUIDs(filter.VideoIDs)),
+			failedBefore,
+			limit,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao reenfileirar vídeos com falha: %w", err)
+		}
+
+		results, err = requeueResults(ctx, r, filter, requeued)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("erro ao excluir vídeo: %w", err)
+		return nil, err
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	return results, nil
+}
+
+// Restore desfaz a exclusão do vídeo, se a remoção definitiva ainda não começou
+func (r *VideoRepositoryPostgres) Restore(ctx context.Context, id string) error {
+	query := `
+		UPDATE videos
+		SET deleted_at = NULL, updated_at = $1, version = version + 1
+		WHERE id = $2 AND deleted_at IS NOT NULL AND purge_started_at IS NULL
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
-		return fmt.Errorf("erro ao obter linhas afetadas: %w", err)
+		return fmt.Errorf("erro ao restaurar vídeo: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return ErrVideoNotFound
+	return checkRowsAffected(result)
+}
+
+// purgeCondition retorna a condição dos candidatos à remoção definitiva com os parâmetros informados
+// (data limite dos excluídos, data limite dos com falha e instante atual); datas limite NULL desativam o critério
+// Originais com duplicatas e vídeos com concessão ativa ficam de fora mesmo com a remoção já iniciada
+func purgeCondition(deletedBefore, failedBefore, now string) string {
+	return `(purge_started_at IS NOT NULL
+			OR deleted_at < ` + deletedBefore + `
+			OR (status = '` + entity.StatusError + `' AND updated_at < ` + failedBefore + `))
+		AND NOT (status = '` + entity.StatusProcessing + `' AND lease_expires_at > ` + now + `)
+		AND NOT EXISTS (SELECT 1 FROM videos d WHERE d.duplicate_of = videos.id)`
+}
+
+// purgeCriteriaArgs retorna as datas limite dos critérios, com nil para os desativados
+func purgeCriteriaArgs(criteria domainRepository.PurgeCriteria) (deletedBefore, failedBefore *time.Time) {
+	if !criteria.DeletedBefore.IsZero() {
+		deletedBefore = &criteria.DeletedBefore
+	}
+	if !criteria.FailedBefore.IsZero() {
+		failedBefore = &criteria.FailedBefore
+	}
+	return deletedBefore, failedBefore
+}
+
+// newPurgeCandidate cria o candidato à remoção a partir do vídeo, da data de exclusão e das chaves do S3
+func newPurgeCandidate(video *entity.Video, deletedAt *time.Time, s3Keys ...string) domainRepository.PurgeCandidate {
+	candidate := domainRepository.PurgeCandidate{Video: video, Reason: domainRepository.PurgeReasonFailed, DeletedAt: deletedAt}
+	if deletedAt != nil && video.Status != entity.StatusError {
+		candidate.Reason = domainRepository.PurgeReasonDeleted
+	}
+	for _, key := range s3Keys {
+		if key != "" {
+			candidate.S3Keys = append(candidate.S3Keys, key)
+		}
+	}
+	return candidate
+}
+
+// ListPurgeCandidates lista os vídeos que passaram da retenção, do mais antigo para o mais recente
+func (r *VideoRepositoryPostgres) ListPurgeCandidates(ctx context.Context, criteria domainRepository.PurgeCriteria) ([]domainRepository.PurgeCandidate, error) {
+	deletedBefore, failedBefore := purgeCriteriaArgs(criteria)
+
+	// LIMIT NULL não limita
+	var limit *int
+	if criteria.Limit > 0 {
+		limit = &criteria.Limit
+	}
+
+	query := `SELECT ` + videoColumns + `, deleted_at, COALESCE(segment_key, ''), COALESCE(manifest_key, '')
+		FROM videos
+		WHERE ` + purgeCondition("$1::timestamp", "$2::timestamp", "$3") + `
+		ORDER BY COALESCE(deleted_at, updated_at) ASC, id ASC
+		LIMIT $4
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, deletedBefore, failedBefore, time.Now(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar vídeos para remoção definitiva: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []domainRepository.PurgeCandidate
+
+	for rows.Next() {
+		var deletedAt sql.NullTime
+		var segmentKey, manifestKey string
+		video, err := scanVideo(rows, &deletedAt, &segmentKey, &manifestKey)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear vídeo: %w", err)
+		}
+
+		var deletedAtPtr *time.Time
+		if deletedAt.Valid {
+			deletedAtPtr = &deletedAt.Time
+		}
+		candidates = append(candidates, newPurgeCandidate(video, deletedAtPtr, segmentKey, manifestKey))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os resultados: %w", err)
+	}
+
+	return candidates, nil
+}
+
+// BeginPurge marca o vídeo como em remoção, excluindo-o se ainda não estiver excluído
+func (r *VideoRepositoryPostgres) BeginPurge(ctx context.Context, id string, criteria domainRepository.PurgeCriteria) error {
+	deletedBefore, failedBefore := purgeCriteriaArgs(criteria)
+
+	query := `
+		UPDATE videos
+		SET deleted_at = COALESCE(deleted_at, $1), purge_started_at = COALESCE(purge_started_at, $1)
+		WHERE id = $2 AND ` + purgeCondition("$3::timestamp", "$4::timestamp", "$1") + `
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id, deletedBefore, failedBefore)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar remoção definitiva do vídeo: %w", err)
+	}
+
+	err = checkRowsAffected(result)
+	if errors.Is(err, ErrVideoNotFound) {
+		return domainRepository.ErrVideoNotPurgeable
+	}
+	return err
+}
+
+// Purge apaga o vídeo; as tentativas de processamento e os arquivos registrados são apagados em cascata
+func (r *VideoRepositoryPostgres) Purge(ctx context.Context, id string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM videos WHERE id = $1 AND purge_started_at IS NOT NULL`, id)
+	if err != nil {
+		return fmt.Errorf("erro ao remover vídeo definitivamente: %w", err)
+	}
+
+	err = checkRowsAffected(result)
+	if errors.Is(err, ErrVideoNotFound) {
+		return domainRepository.ErrVideoNotPurgeable
+	}
+	return err
+}
+
+// Stats calcula as estatísticas com consultas agregadas, atendidas por uma réplica quando houver
+// Os percentis do tempo de processamento são calculados pelo próprio banco, com percentile_cont
+func (r *VideoRepositoryPostgres) Stats(ctx context.Context, filter domainRepository.VideoStatsFilter) (*domainRepository.VideoStats, error) {
+	var args queryArgs
+	where := strings.Join(videoQueryConditions(filter.ListQuery(), &args), " AND ")
+
+	db := r.reader(ctx)
+	stats := domainRepository.NewVideoStats()
+
+	if err := countByStatus(ctx, db, where, args, stats); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT COUNT(*), COALESCE(AVG(d), 0),
+			COALESCE(percentile_cont(0.50) WITHIN GROUP (ORDER BY d), 0),
+			COALESCE(percentile_cont(0.90) WITHIN GROUP (ORDER BY d), 0),
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY d), 0),
+			COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY d), 0)
+		FROM (
+			SELECT (EXTRACT(EPOCH FROM completed_at - processing_started_at) * 1000000)::double precision AS d
+			FROM videos
+			WHERE ` + where + ` AND status = '` + entity.StatusCompleted + `'
+				AND processing_started_at IS NOT NULL AND completed_at IS NOT NULL
+		) durations
+	`
+
+	var micros [5]float64
+	processing := &stats.ProcessingTime
+	err := db.QueryRowContext(ctx, query, args...).Scan(&processing.Count, &micros[0], &micros[1], &micros[2], &micros[3], &micros[4])
+	if err != nil {
+		return nil, fmt.Errorf("erro ao calcular tempo de processamento: %w", err)
+	}
+	processing.Average = durationFromMicros(micros[0])
+	processing.P50 = durationFromMicros(micros[1])
+	processing.P90 = durationFromMicros(micros[2])
+	processing.P95 = durationFromMicros(micros[3])
+	processing.P99 = durationFromMicros(micros[4])
+
+	if err := countFailures(ctx, db, where, args, stats); err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT date_trunc('day', created_at), COUNT(*),
+			COUNT(*) FILTER (WHERE status = '` + entity.StatusCompleted + `'),
+			COUNT(*) FILTER (WHERE status = '` + entity.StatusError + `')
+		FROM videos
+		WHERE ` + where + `
+		GROUP BY 1
+		ORDER BY 1
+	`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao calcular totais diários: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day domainRepository.DailyVideoStats
+		if err := rows.Scan(&day.Date, &day.Created, &day.Completed, &day.Failed); err != nil {
+			return nil, fmt.Errorf("erro ao escanear totais diários: %w", err)
+		}
+		day.Date = time.Date(day.Date.Year(), day.Date.Month(), day.Date.Day(), 0, 0, 0, 0, time.UTC)
+		stats.Daily = append(stats.Daily, day)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar totais diários: %w", err)
+	}
+
+	return stats, nil
+}
+
+// countByStatus preenche o total e as contagens por status e status de upload dos vídeos que atendem a where
+// Comum às implementações PostgreSQL e SQLite
+func countByStatus(ctx context.Context, db DBTX, where string, args []any, stats *domainRepository.VideoStats) error {
+	query := `SELECT status, upload_status, COUNT(*) FROM videos WHERE ` + where + ` GROUP BY status, upload_status`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("erro ao contar vídeos por status: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status, uploadStatus string
+		var count int
+		if err := rows.Scan(&status, &uploadStatus, &count); err != nil {
+			return fmt.Errorf("erro ao escanear contagem de vídeos: %w", err)
+		}
+		stats.Total += count
+		stats.ByStatus[status] += count
+		stats.ByUploadStatus[uploadStatus] += count
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao iterar contagem de vídeos: %w", err)
 	}
 
 	return nil
 }
 
+// countFailures preenche as falhas, agrupando as mensagens de erro dos vídeos que atendem a where
+// Deve ser chamada depois de countByStatus, que fornece a quantidade de vídeos terminados
+func countFailures(ctx context.Context, db DBTX, where string, args []any, stats *domainRepository.VideoStats) error {
+	query := `SELECT COALESCE(error_message, ''), COUNT(*)
+		FROM videos
+		WHERE ` + where + ` AND status = '` + entity.StatusError + `'
+		GROUP BY 1`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("erro ao contar falhas: %w", err)
+	}
+	defer rows.Close()
+
+	failures := make(map[string]int)
+	for rows.Next() {
+		var message string
+		var count int
+		if err := rows.Scan(&message, &count); err != nil {
+			return fmt.Errorf("erro ao escanear contagem de falhas: %w", err)
+		}
+		failures[message] += count
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao iterar contagem de falhas: %w", err)
+	}
+
+	finished := stats.ByStatus[entity.StatusCompleted] + stats.ByStatus[entity.StatusError]
+	stats.Failures = domainRepository.NewFailureStats(finished, failures)
+
+	return nil
+}
+
+// durationFromMicros converte microssegundos fracionários em time.Duration
+func durationFromMicros(micros float64) time.Duration {
+	return time.Duration(math.Round(micros * float64(time.Microsecond)))
+}
+
+// Delete remove um vídeo do repositório (soft delete)
+func (r *VideoRepositoryPostgres) Delete(ctx context.Context, id string, expectedVersion int64) error {
+	query := `
+		UPDATE videos
+		SET deleted_at = $1, version = version + 1
+		WHERE id = $2 AND version = $3 AND deleted_at IS NULL
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("erro ao excluir vídeo: %w", err)
+	}
+
+	return r.checkVersionedWrite(ctx, result, id)
+}
+
 // Ensure VideoRepositoryPostgres implements VideoRepository
 var _ domainRepository.VideoRepository = (*VideoRepositoryPostgres)(nil)
@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+)
+
+// AuditedVideoRepository decora um VideoRepository registrando cada gravação no histórico de auditoria
+// As leituras são repassadas sem alteração; toda nova operação de escrita do VideoRepository
+// precisa ser sobrescrita aqui para ser auditada
+type AuditedVideoRepository struct {
+	domainRepository.VideoRepository
+	audit domainRepository.VideoAuditRepository
+	db    *sql.DB
+}
+
+// NewAuditedVideoRepository cria o decorador de auditoria
+// Com db, a gravação e a entrada do histórico são feitas na mesma transação (os dois repositórios
+// precisam usar esse banco); sem db (repositórios em memória), a entrada é gravada logo após a alteração
+func NewAuditedVideoRepository(videos domainRepository.VideoRepository, audit domainRepository.VideoAuditRepository, db *sql.DB) *AuditedVideoRepository {
+	return &AuditedVideoRepository{
+		VideoRepository: videos,
+		audit:           audit,
+		db:              db,
+	}
+}
+
+// inTx executa fn na transação do banco, quando houver
+func (r *AuditedVideoRepository) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.db == nil {
+		return fn(ctx)
+	}
+	return runInTx(ctx, r.db, fn)
+}
+
+// record grava uma entrada do histórico com o ator e o ID de correlação do contexto
+func (r *AuditedVideoRepository) record(ctx context.Context, videoID string, action domainRepository.AuditAction, changes map[string]domainRepository.FieldChange) error {
+	entry := &domainRepository.VideoAuditEntry{
+		VideoID:       videoID,
+		Action:        action,
+		Changes:       changes,
+		Actor:         domainRepository.ActorFromContext(ctx),
+		CorrelationID: domainRepository.CorrelationIDFromContext(ctx),
+	}
+
+	if err := r.audit.Append(ctx, entry); err != nil {
+		return fmt.Errorf("erro ao registrar auditoria do vídeo: %w", err)
+	}
+	return nil
+}
+
+// audited lê o vídeo antes e depois de write e registra os campos alterados
+func (r *AuditedVideoRepository) audited(ctx context.Context, id string, action domainRepository.AuditAction, write func(ctx context.Context) error) error {
+	return r.inTx(ctx, func(ctx context.Context) error {
+		before, err := r.VideoRepository.FindByID(ctx, id)
+		if err != nil && !errors.Is(err, domainRepository.ErrVideoNotFound) {
+			return err
+		}
+
+		if err := write(ctx); err != nil {
+			return err
+		}
+
+		var after *entity.Video
+		if action != domainRepository.AuditActionDelete {
+			if after, err = r.VideoRepository.FindByID(ctx, id); err != nil {
+				return err
+			}
+		}
+
+		return r.record(ctx, id, action, domainRepository.VideoChanges(before, after))
+	})
+}
+
+// Create persiste o vídeo e registra seus campos preenchidos
+func (r *AuditedVideoRepository) Create(ctx context.Context, video *entity.Video) error {
+	return r.inTx(ctx, func(ctx context.Context) error {
+		if err := r.VideoRepository.Create(ctx, video); err != nil {
+			return err
+		}
+		return r.record(ctx, video.ID, domainRepository.AuditActionCreate, domainRepository.VideoChanges(nil, video))
+	})
+}
+
+// Update grava o vídeo completo e registra os campos alterados
+func (r *AuditedVideoRepository) Update(ctx context.Context, video *entity.Video) error {
+	return r.audited(ctx, video.ID, domainRepository.AuditActionUpdate, func(ctx context.Context) error {
+		return r.VideoRepository.Update(ctx, video)
+	})
+}
+
+// UpdateStatus atualiza o status e registra os campos alterados
+func (r *AuditedVideoRepository) UpdateStatus(ctx context.Context, id string, expectedVersion int64, status string, errorMessage string) error {
+	return r.audited(ctx, id, domainRepository.AuditActionUpdateStatus, func(ctx context.Context) error {
+		return r.VideoRepository.UpdateStatus(ctx, id, expectedVersion, status, errorMessage)
+	})
+}
+
+// UpdateProgress atualiza o progresso e registra os novos valores
+// É chamado a cada sinal de progresso do FFmpeg, por isso o vídeo não é lido antes nem depois da gravação
+func (r *AuditedVideoRepository) UpdateProgress(ctx context.Context, id string, progress int, stage string, estimatedCompletionAt *time.Time) error {
+	return r.inTx(ctx, func(ctx context.Context) error {
+		if err := r.VideoRepository.UpdateProgress(ctx, id, progress, stage, estimatedCompletionAt); err != nil {
+			return err
+		}
+		return r.record(ctx, id, domainRepository.AuditActionUpdateProgress, map[string]domainRepository.FieldChange{
+			"progress":                domainRepository.NewFieldChange(nil, min(max(progress, 0), 100)),
+			"processing_stage":        domainRepository.NewFieldChange(nil, stage),
+			"estimated_completion_at": domainRepository.NewFieldChange(nil, estimatedCompletionAt),
+		})
+	})
+}
+
+// UpdateHLSPath atualiza os caminhos HLS e registra os campos alterados
+func (r *AuditedVideoRepository) UpdateHLSPath(ctx context.Context, id string, expectedVersion int64, hlsPath, manifestPath string) error {
+	return r.audited(ctx, id, domainRepository.AuditActionUpdateHLSPath, func(ctx context.Context) error {
+		return r.VideoRepository.UpdateHLSPath(ctx, id, expectedVersion, hlsPath, manifestPath)
+	})
+}
+
+// UpdateS3Status atualiza o status de upload e registra os campos alterados
+func (r *AuditedVideoRepository) UpdateS3Status(ctx context.Context, id string, expectedVersion int64, uploadStatus string) error {
+	return r.audited(ctx, id, domainRepository.AuditActionUpdateS3Status, func(ctx context.Context) error {
+		return r.VideoRepository.UpdateS3Status(ctx, id, expectedVersion, uploadStatus)
+	})
+}
+
+// UpdateS3URLs atualiza as URLs do S3 e registra os campos alterados
+func (r *AuditedVideoRepository) UpdateS3URLs(ctx context.Context, id string, expectedVersion int64, s3URL, s3ManifestURL string) error {
+	return r.audited(ctx, id, domainRepository.AuditActionUpdateS3URLs, func(ctx context.Context) error {
+		return r.VideoRepository.UpdateS3URLs(ctx, id, expectedVersion, s3URL, s3ManifestURL)
+	})
+}
+
+// UpdateS3Keys atualiza as chaves do S3 e registra os novos valores
+// As chaves não fazem parte da entidade, por isso os valores anteriores não são conhecidos
+func (r *AuditedVideoRepository) UpdateS3Keys(ctx context.Context, id string, expectedVersion int64, segmentKey string, manifestKey string) error {
+	return r.inTx(ctx, func(ctx context.Context) error {
+		if err := r.VideoRepository.UpdateS3Keys(ctx, id, expectedVersion, segmentKey, manifestKey); err != nil {
+			return err
+		}
+		return r.record(ctx, id, domainRepository.AuditActionUpdateS3Keys, map[string]domainRepository.FieldChange{
+			"segment_key":  domainRepository.NewFieldChange(nil, segmentKey),
+			"manifest_key": domainRepository.NewFieldChange(nil, manifestKey),
+		})
+	})
+}
+
+// ClaimNextPending reivindica o próximo vídeo e registra o novo status e a concessão
+// O vídeo só é conhecido depois da reivindicação, por isso os valores anteriores não são registrados
+func (r *AuditedVideoRepository) ClaimNextPending(ctx context.Context, workerID string, leaseDuration time.Duration) (*entity.Video, error) {
+	var claimed *entity.Video
+
+	err := r.inTx(ctx, func(ctx context.Context) error {
+		video, err := r.VideoRepository.ClaimNextPending(ctx, workerID, leaseDuration)
+		if err != nil {
+			return err
+		}
+
+		claimed = video
+		return r.record(ctx, video.ID, domainRepository.AuditActionClaim, map[string]domainRepository.FieldChange{
+			"status":           domainRepository.NewFieldChange(nil, video.Status),
+			"lease_owner":      domainRepository.NewFieldChange(nil, video.LeaseOwner),
+			"lease_expires_at": domainRepository.NewFieldChange(nil, video.LeaseExpiresAt),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// RenewLease prorroga a concessão e registra o novo vencimento
+// É chamado a cada sinal de vida do worker, por isso o vídeo não é lido antes nem depois da gravação;
+// o vencimento registrado é calculado a partir do instante da chamada
+func (r *AuditedVideoRepository) RenewLease(ctx context.Context, id, workerID string, leaseDuration time.Duration) error {
+	expiresAt := time.Now().Add(leaseDuration)
+
+	return r.inTx(ctx, func(ctx context.Context) error {
+		if err := r.VideoRepository.RenewLease(ctx, id, workerID, leaseDuration); err != nil {
+			return err
+		}
+		return r.record(ctx, id, domainRepository.AuditActionRenewLease, map[string]domainRepository.FieldChange{
+			"lease_expires_at": domainRepository.NewFieldChange(nil, expiresAt),
+		})
+	})
+}
+
+// ReleaseLease libera a concessão e registra os campos alterados
+func (r *AuditedVideoRepository) ReleaseLease(ctx context.Context, id, workerID string) error {
+	return r.audited(ctx, id, domainRepository.AuditActionReleaseLease, func(ctx context.Context) error {
+		return r.VideoRepository.ReleaseLease(ctx, id, workerID)
+	})
+}
+
+// CreateMany persiste os vídeos e registra a criação de cada vídeo gravado
+func (r *AuditedVideoRepository) CreateMany(ctx context.Context, videos []*entity.Video) ([]domainRepository.BulkResult, error) {
+	var results []domainRepository.BulkResult
+
+	err := r.inTx(ctx, func(ctx context.Context) error {
+		var err error
+		if results, err = r.VideoRepository.CreateMany(ctx, videos); err != nil {
+			return err
+		}
+
+		for i, result := range results {
+			if result.Err != nil {
+				continue
+			}
+			if err := r.record(ctx, result.ID, domainRepository.AuditActionCreate, domainRepository.VideoChanges(nil, videos[i])); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// UpdateStatusMany atualiza o status dos vídeos e registra os campos alterados de cada um
+// Os estados anteriores e posteriores são lidos com uma consulta cada
+func (r *AuditedVideoRepository) UpdateStatusMany(ctx context.Context, ids []string, status string) ([]domainRepository.BulkResult, error) {
+	var results []domainRepository.BulkResult
+
+	err := r.inTx(ctx, func(ctx context.Context) error {
+		before, err := r.VideoRepository.FindManyByID(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		if results, err = r.VideoRepository.UpdateStatusMany(ctx, ids, status); err != nil {
+			return err
+		}
+
+		after, err := r.VideoRepository.FindManyByID(ctx, domainRepository.BulkSucceeded(results))
+		if err != nil {
+			return err
+		}
+
+		beforeByID := make(map[string]*entity.Video, len(before))
+		for _, video := range before {
+			beforeByID[video.ID] = video
+		}
+
+		for _, video := range after {
+			changes := domainRepository.VideoChanges(beforeByID[video.ID], video)
+			if err := r.record(ctx, video.ID, domainRepository.AuditActionUpdateStatus, changes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// RequeueFailed devolve os vídeos para a fila e registra o novo estado de cada um
+// Os vídeos só são conhecidos depois da operação, por isso dos valores anteriores registra apenas o status
+func (r *AuditedVideoRepository) RequeueFailed(ctx context.Context, filter domainRepository.RequeueFailedFilter) ([]domainRepository.BulkResult, error) {
+	var results []domainRepository.BulkResult
+
+	err := r.inTx(ctx, func(ctx context.Context) error {
+		var err error
+		if results, err = r.VideoRepository.RequeueFailed(ctx, filter); err != nil {
+			return err
+		}
+
+		for _, id := range domainRepository.BulkSucceeded(results) {
+			err := r.record(ctx, id, domainRepository.AuditActionRequeue, map[string]domainRepository.FieldChange{
+				"status":        domainRepository.NewFieldChange(entity.StatusError, entity.StatusPending),
+				"error_message": domainRepository.NewFieldChange(nil, ""),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Restore restaura o vídeo e registra o estado restaurado
+func (r *AuditedVideoRepository) Restore(ctx context.Context, id string) error {
+	return r.audited(ctx, id, domainRepository.AuditActionRestore, func(ctx context.Context) error {
+		return r.VideoRepository.Restore(ctx, id)
+	})
+}
+
+// BeginPurge inicia a remoção definitiva do vídeo e a registra
+func (r *AuditedVideoRepository) BeginPurge(ctx context.Context, id string, criteria domainRepository.PurgeCriteria) error {
+	return r.inTx(ctx, func(ctx context.Context) error {
+		if err := r.VideoRepository.BeginPurge(ctx, id, criteria); err != nil {
+			return err
+		}
+		return r.record(ctx, id, domainRepository.AuditActionPurgeStart, map[string]domainRepository.FieldChange{})
+	})
+}
+
+// Purge remove o vídeo definitivamente; o registro no histórico sobrevive à remoção
+func (r *AuditedVideoRepository) Purge(ctx context.Context, id string) error {
+	return r.inTx(ctx, func(ctx context.Context) error {
+		if err := r.VideoRepository.Purge(ctx, id); err != nil {
+			return err
+		}
+		return r.record(ctx, id, domainRepository.AuditActionPurge, map[string]domainRepository.FieldChange{})
+	})
+}
+
+// Delete exclui o vídeo e registra a exclusão
+func (r *AuditedVideoRepository) Delete(ctx context.Context, id string, expectedVersion int64) error {
+	return r.audited(ctx, id, domainRepository.AuditActionDelete, func(ctx context.Context) error {
+		return r.VideoRepository.Delete(ctx, id, expectedVersion)
+	})
+}
+
+// Ensure AuditedVideoRepository implements VideoRepository
+var _ domainRepository.VideoRepository = (*AuditedVideoRepository)(nil)
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+	"github.com/devfullcycle/golangtechweek/internal/infra/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// auditedBackends retorna um repositório auditado e seu histórico para cada implementação sem dependências externas
+func auditedBackends(t *testing.T) map[string]func(t *testing.T) (domainRepository.VideoRepository, domainRepository.VideoAuditRepository) {
+	return map[string]func(t *testing.T) (domainRepository.VideoRepository, domainRepository.VideoAuditRepository){
+		"memory": func(t *testing.T) (domainRepository.VideoRepository, domainRepository.VideoAuditRepository) {
+			audit := NewVideoAuditRepositoryMemory()
+			return NewAuditedVideoRepository(NewVideoRepositoryMemory(), audit, nil), audit
+		},
+		"sqlite": func(t *testing.T) (domainRepository.VideoRepository, domainRepository.VideoAuditRepository) {
+			db, err := database.Open(database.Config{
+				Driver:      database.DriverSQLite,
+				SQLitePath:  filepath.Join(t.TempDir(), "videos.db"),
+				AutoMigrate: true,
+			})
+			require.NoError(t, err)
+			t.Cleanup(func() { db.Close() })
+
+			repo, err := NewVideoRepository(database.DriverSQLite, db)
+			require.NoError(t, err)
+			audit, err := NewVideoAuditRepository(database.DriverSQLite, db)
+			require.NoError(t, err)
+			return repo, audit
+		},
+	}
+}
+
+func rawString(t *testing.T, raw json.RawMessage) string {
+	t.Helper()
+
+	var value string
+	require.NoError(t, json.Unmarshal(raw, &value))
+	return value
+}
+
+func TestAuditedVideoRepositoryHistory(t *testing.T) {
+	for name, newBackend := range auditedBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			repo, audit := newBackend(t)
+
+			ctx := domainRepository.WithCorrelationID(domainRepository.WithActor(context.Background(), domainRepository.APIKeyActor("key-1")), "req-1")
+
+			video := entity.NewVideo("owner-1", "Vídeo", "", "/path/to/video.mp4")
+			require.NoError(t, repo.Create(ctx, video))
+
+			workerCtx := domainRepository.WithActor(context.Background(), domainRepository.WorkerActor("worker-1"))
+			require.NoError(t, repo.UpdateStatus(workerCtx, video.ID, video.Version, entity.StatusProcessing, ""))
+			require.NoError(t, repo.UpdateHLSPath(workerCtx, video.ID, video.Version+1, "/hls", "/hls/playlist.m3u8"))
+			require.NoError(t, repo.Delete(ctx, video.ID, video.Version+2))
+
+			// Gravações que falham não entram no histórico
+			assert.ErrorIs(t, repo.UpdateStatus(ctx, video.ID, video.Version+3, entity.StatusCompleted, ""), domainRepository.ErrVideoNotFound)
+
+			entries, err := audit.History(context.Background(), video.ID, 0, 0)
+			require.NoError(t, err)
+			require.Len(t, entries, 4)
+
+			deleted, hls, status, created := entries[0], entries[1], entries[2], entries[3]
+
+			assert.Equal(t, domainRepository.AuditActionDelete, deleted.Action)
+			assert.Equal(t, domainRepository.AuditActionCreate, created.Action)
+			assert.Equal(t, domainRepository.APIKeyActor("key-1"), created.Actor)
+			assert.Equal(t, "req-1", created.CorrelationID)
+			assert.Equal(t, "null", string(created.Changes["title"].Old))
+			assert.Equal(t, "Vídeo", rawString(t, created.Changes["title"].New))
+			assert.False(t, created.CreatedAt.IsZero())
+
+			assert.Equal(t, domainRepository.AuditActionUpdateStatus, status.Action)
+			assert.Equal(t, domainRepository.WorkerActor("worker-1"), status.Actor)
+			assert.Empty(t, status.CorrelationID)
+			assert.Equal(t, entity.StatusPending, rawString(t, status.Changes["status"].Old))
+			assert.Equal(t, entity.StatusProcessing, rawString(t, status.Changes["status"].New))
+
+			assert.Equal(t, domainRepository.AuditActionUpdateHLSPath, hls.Action)
+			assert.Len(t, hls.Changes, 2)
+			assert.Equal(t, "/hls/playlist.m3u8", rawString(t, hls.Changes["manifest_path"].New))
+
+			// Paginação pelo ID da última entrada
+			older, err := audit.History(context.Background(), video.ID, hls.ID, 1)
+			require.NoError(t, err)
+			require.Len(t, older, 1)
+			assert.Equal(t, status.ID, older[0].ID)
+		})
+	}
+}
+
+func TestAuditedVideoRepositoryClaimAndLease(t *testing.T) {
+	for name, newBackend := range auditedBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			repo, audit := newBackend(t)
+			ctx := context.Background()
+
+			video := entity.NewVideo("owner-1", "Vídeo", "", "/path/to/video.mp4")
+			require.NoError(t, repo.Create(ctx, video))
+
+			claimed, err := repo.ClaimNextPending(ctx, "worker-1", time.Minute)
+			require.NoError(t, err)
+			require.NoError(t, repo.UpdateProgress(ctx, claimed.ID, 150, entity.ProcessingStageTranscoding, nil))
+			require.NoError(t, repo.RenewLease(ctx, claimed.ID, "worker-1", time.Minute))
+			require.NoError(t, repo.ReleaseLease(ctx, claimed.ID, "worker-1"))
+
+			// Renovações que falham não entram no histórico
+			assert.ErrorIs(t, repo.RenewLease(ctx, claimed.ID, "worker-1", time.Minute), domainRepository.ErrLeaseNotHeld)
+
+			entries, err := audit.History(ctx, video.ID, 0, 0)
+			require.NoError(t, err)
+			require.Len(t, entries, 5)
+
+			released, renewed, progress, claim := entries[0], entries[1], entries[2], entries[3]
+
+			assert.Equal(t, domainRepository.AuditActionReleaseLease, released.Action)
+			assert.Equal(t, "worker-1", rawString(t, released.Changes["lease_owner"].Old))
+			assert.Equal(t, domainRepository.AuditActionRenewLease, renewed.Action)
+			assert.Equal(t, "null", string(renewed.Changes["lease_expires_at"].Old))
+			assert.NotEqual(t, "null", string(renewed.Changes["lease_expires_at"].New))
+			assert.Equal(t, domainRepository.AuditActionUpdateProgress, progress.Action)
+			assert.Equal(t, "100", string(progress.Changes["progress"].New))
+			assert.Equal(t, entity.ProcessingStageTranscoding, rawString(t, progress.Changes["processing_stage"].New))
+			assert.Equal(t, domainRepository.AuditActionClaim, claim.Action)
+			assert.Equal(t, entity.StatusProcessing, rawString(t, claim.Changes["status"].New))
+		})
+	}
+}
+
+func TestAuditedVideoRepositoryBulkOperations(t *testing.T) {
+	for name, newBackend := range auditedBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			repo, audit := newBackend(t)
+			ctx := domainRepository.WithActor(context.Background(), domainRepository.APIKeyActor("admin"))
+
+			first := entity.NewVideo("owner-1", "Primeiro", "", "/path/to/first.mp4")
+			second := entity.NewVideo("owner-1", "Segundo", "", "/path/to/second.mp4")
+			_, err := repo.CreateMany(ctx, []*entity.Video{first, second, first})
+			require.NoError(t, err)
+
+			results, err := repo.UpdateStatusMany(ctx, []string{first.ID, second.ID}, entity.StatusError)
+			require.NoError(t, err)
+			require.Len(t, domainRepository.BulkSucceeded(results), 2)
+
+			results, err = repo.RequeueFailed(ctx, domainRepository.RequeueFailedFilter{VideoIDs: []string{first.ID}})
+			require.NoError(t, err)
+			require.Len(t, domainRepository.BulkSucceeded(results), 1)
+
+			entries, err := audit.History(context.Background(), first.ID, 0, 0)
+			require.NoError(t, err)
+			require.Len(t, entries, 3)
+
+			requeued, status, created := entries[0], entries[1], entries[2]
+			assert.Equal(t, domainRepository.AuditActionCreate, created.Action)
+			assert.Equal(t, domainRepository.APIKeyActor("admin"), created.Actor)
+			assert.Equal(t, domainRepository.AuditActionUpdateStatus, status.Action)
+			assert.Equal(t, entity.StatusPending, rawString(t, status.Changes["status"].Old))
+			assert.Equal(t, entity.StatusError, rawString(t, status.Changes["status"].New))
+			assert.Equal(t, domainRepository.AuditActionRequeue, requeued.Action)
+			assert.Equal(t, entity.StatusPending, rawString(t, requeued.Changes["status"].New))
+
+			entries, err = audit.History(context.Background(), second.ID, 0, 0)
+			require.NoError(t, err)
+			assert.Len(t, entries, 2)
+		})
+	}
+}
+
+func TestAuditedVideoRepositoryRollsBackWithoutAudit(t *testing.T) {
+	db, err := database.Open(database.Config{
+		Driver:      database.DriverSQLite,
+		SQLitePath:  filepath.Join(t.TempDir(), "videos.db"),
+		AutoMigrate: true,
+	})
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo, err := NewVideoRepository(database.DriverSQLite, db)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	video := entity.NewVideo("owner-1", "Vídeo", "", "/path/to/video.mp4")
+	require.NoError(t, repo.Create(ctx, video))
+
+	// Sem a tabela de auditoria, a gravação do vídeo precisa ser desfeita
+	_, err = db.Exec(`DROP TABLE video_audit_log`)
+	require.NoError(t, err)
+
+	assert.Error(t, repo.UpdateStatus(ctx, video.ID, video.Version, entity.StatusProcessing, ""))
+
+	found, err := repo.FindByID(ctx, video.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.StatusPending, found.Status)
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+)
+
+// prepareBulkCreate valida os vídeos de CreateMany e descarta os IDs repetidos na própria lista
+// Retorna os resultados, na ordem dos vídeos, e os vídeos que podem ser inseridos
+func prepareBulkCreate(videos []*entity.Video) ([]domainRepository.BulkResult, []*entity.Video) {
+	results := make([]domainRepository.BulkResult, len(videos))
+	candidates := make([]*entity.Video, 0, len(videos))
+	seen := make(map[string]bool, len(videos))
+
+	for i, video := range videos {
+		results[i].ID = video.ID
+
+		if err := video.Validate(); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		if seen[video.ID] {
+			results[i].Err = domainRepository.ErrVideoAlreadyExists
+			continue
+		}
+
+		seen[video.ID] = true
+		candidates = append(candidates, video)
+	}
+
+	return results, candidates
+}
+
+// newBulkResults cria um resultado sem erro para cada ID
+func newBulkResults(ids []string) []domainRepository.BulkResult {
+	results := make([]domainRepository.BulkResult, len(ids))
+	for i, id := range ids {
+		results[i].ID = id
+	}
+	return results
+}
+
+// markBulkResults atribui missingErr aos resultados ainda sem erro cujos IDs não estão em applied
+func markBulkResults(results []domainRepository.BulkResult, applied map[string]bool, missingErr error) {
+	for i := range results {
+		if results[i].Err == nil && !applied[results[i].ID] {
+			results[i].Err = missingErr
+		}
+	}
+}
+
+// requeueResults monta os resultados de RequeueFailed a partir dos IDs devolvidos para a fila
+// Com filter.VideoIDs, os que não voltaram para a fila são conferidos para distinguir o vídeo
+// inexistente do vídeo que não estava com falha
+func requeueResults(ctx context.Context, finder domainRepository.VideoBulkOperations, filter domainRepository.RequeueFailedFilter, requeued []string) ([]domainRepository.BulkResult, error) {
+	if len(filter.VideoIDs) == 0 {
+		return newBulkResults(requeued), nil
+	}
+
+	applied := make(map[string]bool, len(requeued))
+	for _, id := range requeued {
+		applied[id] = true
+	}
+
+	var missing []string
+	for _, id := range filter.VideoIDs {
+		if !applied[id] {
+			missing = append(missing, id)
+		}
+	}
+
+	existing, err := finder.FindManyByID(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+
+	// Os que existem, mas não voltaram para a fila, não estavam com falha
+	notFailed := make(map[string]bool, len(existing))
+	for _, video := range existing {
+		notFailed[video.ID] = true
+	}
+
+	results := newBulkResults(filter.VideoIDs)
+	for i := range results {
+		switch {
+		case applied[results[i].ID]:
+		case notFailed[results[i].ID]:
+			results[i].Err = domainRepository.ErrVideoNotFailed
+		default:
+			results[i].Err = domainRepository.ErrVideoNotFound
+		}
+	}
+
+	return results, nil
+}
+
+// orderByIDs ordena os vídeos conforme a lista de IDs; IDs repetidos aparecem uma vez
+func orderByIDs(videos []*entity.Video, ids []string) []*entity.Video {
+	byID := make(map[string]*entity.Video, len(videos))
+	for _, video := range videos {
+		byID[video.ID] = video
+	}
+
+	ordered := make([]*entity.Video, 0, len(videos))
+	for _, id := range ids {
+		if video, ok := byID[id]; ok {
+			ordered = append(ordered, video)
+			delete(byID, id)
+		}
+	}
+	return ordered
+}
@@ -0,0 +1,362 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+)
+
+const (
+	defaultVideoCacheCapacity = 10_000
+	defaultVideoCacheTTL      = 5 * time.Second
+)
+
+// VideoCacheConfig contém as configurações do cache de FindByID
+type VideoCacheConfig struct {
+	Capacity int           // Máximo de vídeos em cache; os usados há mais tempo são descartados primeiro
+	TTL      time.Duration // Tempo máximo em que um vídeo é servido do cache sem ser relido
+}
+
+// VideoCacheStats são os contadores do cache desde a sua criação
+type VideoCacheStats struct {
+	Hits          uint64 // Leituras atendidas pelo cache
+	Misses        uint64 // Leituras repassadas ao repositório
+	Invalidations uint64 // Vídeos descartados por gravações ou mudanças notificadas
+	Evictions     uint64 // Vídeos descartados por falta de espaço
+	Size          int    // Vídeos em cache no momento
+}
+
+// cachedVideo é uma entrada do cache; video é somente leitura e é copiado a cada leitura
+type cachedVideo struct {
+	id        string
+	video     *entity.Video
+	expiresAt time.Time
+}
+
+// videoLoad é uma leitura de FindByID em andamento, compartilhada pelas leituras concorrentes do mesmo vídeo
+type videoLoad struct {
+	done  chan struct{}
+	video *entity.Video
+	err   error
+}
+
+// CachedVideoRepository decora um VideoRepository com um cache LRU de FindByID, cujas entradas expiram após TTL
+// Toda gravação feita por este decorador descarta os vídeos afetados; gravações feitas por fora (outras instâncias,
+// transações do UnitOfWork) só são vistas após TTL ou, com InvalidateOnChanges, assim que notificadas
+// O repositório decorado continua sendo a fonte da verdade: leituras dentro de transações não usam o cache, e
+// gravações em uma transação do chamador descartam o vídeo antes da confirmação, então uma leitura concorrente
+// pode guardar o valor anterior até o TTL
+type CachedVideoRepository struct {
+	domainRepository.VideoRepository
+	capacity int
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // Valores *cachedVideo
+	lru     *list.List               // Do usado mais recentemente para o menos recente
+	loads   map[string]*videoLoad
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	invalidations atomic.Uint64
+	evictions     atomic.Uint64
+}
+
+// NewCachedVideoRepository cria o decorador de cache; campos zerados da configuração usam os padrões
+// (10.000 vídeos, 5 segundos)
+func NewCachedVideoRepository(videos domainRepository.VideoRepository, config VideoCacheConfig) *CachedVideoRepository {
+	if config.Capacity <= 0 {
+		config.Capacity = defaultVideoCacheCapacity
+	}
+
+	if config.TTL <= 0 {
+		config.TTL = defaultVideoCacheTTL
+	}
+
+	return &CachedVideoRepository{
+		VideoRepository: videos,
+		capacity:        config.Capacity,
+		ttl:             config.TTL,
+		now:             time.Now,
+		entries:         make(map[string]*list.Element),
+		lru:             list.New(),
+		loads:           make(map[string]*videoLoad),
+	}
+}
+
+// FindByID retorna o vídeo do cache ou o lê do repositório decorado, guardando-o
+// Leituras concorrentes do mesmo vídeo ausente do cache aguardam uma única leitura do repositório
+func (r *CachedVideoRepository) FindByID(ctx context.Context, id string) (*entity.Video, error) {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return r.VideoRepository.FindByID(ctx, id)
+	}
+
+	r.mu.Lock()
+	if video, ok := r.lookup(id); ok {
+		r.mu.Unlock()
+		r.hits.Add(1)
+		return cloneVideo(video), nil
+	}
+
+	r.misses.Add(1)
+	load, loading := r.loads[id]
+	if !loading {
+		load = &videoLoad{done: make(chan struct{})}
+		r.loads[id] = load
+	}
+	r.mu.Unlock()
+
+	if loading {
+		select {
+		case <-load.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		switch {
+		case load.err == nil:
+			return cloneVideo(load.video), nil
+		case errors.Is(load.err, domainRepository.ErrVideoNotFound):
+			return nil, load.err
+		default:
+			// A falha pode ser do contexto de quem fez a leitura, então esta leitura tenta por conta própria
+			return r.VideoRepository.FindByID(ctx, id)
+		}
+	}
+
+	video, err := r.VideoRepository.FindByID(ctx, id)
+
+	r.mu.Lock()
+	if err == nil {
+		load.video = cloneVideo(video)
+	}
+	load.err = err
+
+	// Se o vídeo foi invalidado durante a leitura, ela pode ser anterior à gravação e não é guardada
+	if r.loads[id] == load {
+		delete(r.loads, id)
+		if err == nil {
+			r.store(id, load.video)
+		}
+	}
+	r.mu.Unlock()
+	close(load.done)
+
+	return video, err
+}
+
+// lookup retorna o vídeo em cache, se ainda válido, marcando-o como o usado mais recentemente
+// Deve ser chamado com r.mu travado
+func (r *CachedVideoRepository) lookup(id string) (*entity.Video, bool) {
+	element, ok := r.entries[id]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*cachedVideo)
+	if !r.now().Before(entry.expiresAt) {
+		r.lru.Remove(element)
+		delete(r.entries, id)
+		return nil, false
+	}
+
+	r.lru.MoveToFront(element)
+	return entry.video, true
+}
+
+// store guarda o vídeo, descartando os usados há mais tempo se a capacidade for excedida
+// Deve ser chamado com r.mu travado
+func (r *CachedVideoRepository) store(id string, video *entity.Video) {
+	entry := &cachedVideo{id: id, video: video, expiresAt: r.now().Add(r.ttl)}
+
+	if element, ok := r.entries[id]; ok {
+		element.Value = entry
+		r.lru.MoveToFront(element)
+		return
+	}
+
+	r.entries[id] = r.lru.PushFront(entry)
+
+	for r.lru.Len() > r.capacity {
+		oldest := r.lru.Back()
+		r.lru.Remove(oldest)
+		delete(r.entries, oldest.Value.(*cachedVideo).id)
+		r.evictions.Add(1)
+	}
+}
+
+// Invalidate descarta os vídeos do cache e as leituras em andamento deles, que não serão guardadas
+func (r *CachedVideoRepository) Invalidate(ids ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range ids {
+		delete(r.loads, id)
+
+		if element, ok := r.entries[id]; ok {
+			r.lru.Remove(element)
+			delete(r.entries, id)
+			r.invalidations.Add(1)
+		}
+	}
+}
+
+// InvalidateOnChanges descarta do cache os vídeos cujas mudanças forem notificadas pelo watcher,
+// até o contexto ser cancelado ou o watcher encerrar o canal
+// As notificações cobrem criação e mudanças de status; as demais gravações feitas por fora continuam
+// dependendo do TTL
+func (r *CachedVideoRepository) InvalidateOnChanges(ctx context.Context, watcher domainRepository.VideoWatcher) {
+	for change := range watcher.Watch(ctx, domainRepository.VideoWatchFilter{}) {
+		r.Invalidate(change.VideoID)
+	}
+}
+
+// CacheStats retorna os contadores do cache
+func (r *CachedVideoRepository) CacheStats() VideoCacheStats {
+	r.mu.Lock()
+	size := r.lru.Len()
+	r.mu.Unlock()
+
+	return VideoCacheStats{
+		Hits:          r.hits.Load(),
+		Misses:        r.misses.Load(),
+		Invalidations: r.invalidations.Load(),
+		Evictions:     r.evictions.Load(),
+		Size:          size,
+	}
+}
+
+// As gravações descartam os vídeos afetados mesmo quando falham: um ErrConcurrentModification, por exemplo,
+// indica que o vídeo em cache está desatualizado
+
+// Create persiste o vídeo e o descarta do cache
+func (r *CachedVideoRepository) Create(ctx context.Context, video *entity.Video) error {
+	defer r.Invalidate(video.ID)
+	return r.VideoRepository.Create(ctx, video)
+}
+
+// Update grava o vídeo e o descarta do cache
+func (r *CachedVideoRepository) Update(ctx context.Context, video *entity.Video) error {
+	defer r.Invalidate(video.ID)
+	return r.VideoRepository.Update(ctx, video)
+}
+
+// UpdateStatus atualiza o status e descarta o vídeo do cache
+func (r *CachedVideoRepository) UpdateStatus(ctx context.Context, id string, expectedVersion int64, status string, errorMessage string) error {
+	defer r.Invalidate(id)
+	return r.VideoRepository.UpdateStatus(ctx, id, expectedVersion, status, errorMessage)
+}
+
+// UpdateProgress atualiza o progresso e descarta o vídeo do cache
+func (r *CachedVideoRepository) UpdateProgress(ctx context.Context, id string, progress int, stage string, estimatedCompletionAt *time.Time) error {
+	defer r.Invalidate(id)
+	return r.VideoRepository.UpdateProgress(ctx, id, progress, stage, estimatedCompletionAt)
+}
+
+// UpdateHLSPath atualiza os caminhos HLS e descarta o vídeo do cache
+func (r *CachedVideoRepository) UpdateHLSPath(ctx context.Context, id string, expectedVersion int64, hlsPath, manifestPath string) error {
+	defer r.Invalidate(id)
+	return r.VideoRepository.UpdateHLSPath(ctx, id, expectedVersion, hlsPath, manifestPath)
+}
+
+// UpdateS3Status atualiza o status de upload e descarta o vídeo do cache
+func (r *CachedVideoRepository) UpdateS3Status(ctx context.Context, id string, expectedVersion int64, uploadStatus string) error {
+	defer r.Invalidate(id)
+	return r.VideoRepository.UpdateS3Status(ctx, id, expectedVersion, uploadStatus)
+}
+
+// UpdateS3URLs atualiza as URLs do S3 e descarta o vídeo do cache
+func (r *CachedVideoRepository) UpdateS3URLs(ctx context.Context, id string, expectedVersion int64, s3URL, s3ManifestURL string) error {
+	defer r.Invalidate(id)
+	return r.VideoRepository.UpdateS3URLs(ctx, id, expectedVersion, s3URL, s3ManifestURL)
+}
+
+// UpdateS3Keys atualiza as chaves do S3 e descarta o vídeo do cache
+func (r *CachedVideoRepository) UpdateS3Keys(ctx context.Context, id string, expectedVersion int64, segmentKey string, manifestKey string) error {
+	defer r.Invalidate(id)
+	return r.VideoRepository.UpdateS3Keys(ctx, id, expectedVersion, segmentKey, manifestKey)
+}
+
+// ClaimNextPending reivindica o próximo vídeo pendente e o descarta do cache
+func (r *CachedVideoRepository) ClaimNextPending(ctx context.Context, workerID string, leaseDuration time.Duration) (*entity.Video, error) {
+	video, err := r.VideoRepository.ClaimNextPending(ctx, workerID, leaseDuration)
+	if video != nil {
+		r.Invalidate(video.ID)
+	}
+	return video, err
+}
+
+// RenewLease estende a concessão e descarta o vídeo do cache
+func (r *CachedVideoRepository) RenewLease(ctx context.Context, id, workerID string, leaseDuration time.Duration) error {
+	defer r.Invalidate(id)
+	return r.VideoRepository.RenewLease(ctx, id, workerID, leaseDuration)
+}
+
+// ReleaseLease encerra a concessão e descarta o vídeo do cache
+func (r *CachedVideoRepository) ReleaseLease(ctx context.Context, id, workerID string) error {
+	defer r.Invalidate(id)
+	return r.VideoRepository.ReleaseLease(ctx, id, workerID)
+}
+
+// CreateMany persiste os vídeos e os descarta do cache
+func (r *CachedVideoRepository) CreateMany(ctx context.Context, videos []*entity.Video) ([]domainRepository.BulkResult, error) {
+	defer func() {
+		for _, video := range videos {
+			r.Invalidate(video.ID)
+		}
+	}()
+	return r.VideoRepository.CreateMany(ctx, videos)
+}
+
+// UpdateStatusMany atualiza o status dos vídeos e os descarta do cache
+func (r *CachedVideoRepository) UpdateStatusMany(ctx context.Context, ids []string, status string) ([]domainRepository.BulkResult, error) {
+	defer r.Invalidate(ids...)
+	return r.VideoRepository.UpdateStatusMany(ctx, ids, status)
+}
+
+// RequeueFailed devolve os vídeos com falha para a fila e os descarta do cache
+func (r *CachedVideoRepository) RequeueFailed(ctx context.Context, filter domainRepository.RequeueFailedFilter) ([]domainRepository.BulkResult, error) {
+	results, err := r.VideoRepository.RequeueFailed(ctx, filter)
+
+	r.Invalidate(filter.VideoIDs...)
+	for _, result := range results {
+		r.Invalidate(result.ID)
+	}
+
+	return results, err
+}
+
+// Restore restaura o vídeo e o descarta do cache
+func (r *CachedVideoRepository) Restore(ctx context.Context, id string) error {
+	defer r.Invalidate(id)
+	return r.VideoRepository.Restore(ctx, id)
+}
+
+// BeginPurge inicia a remoção definitiva do vídeo e o descarta do cache
+func (r *CachedVideoRepository) BeginPurge(ctx context.Context, id string, criteria domainRepository.PurgeCriteria) error {
+	defer r.Invalidate(id)
+	return r.VideoRepository.BeginPurge(ctx, id, criteria)
+}
+
+// Purge remove o vídeo definitivamente e o descarta do cache
+func (r *CachedVideoRepository) Purge(ctx context.Context, id string) error {
+	defer r.Invalidate(id)
+	return r.VideoRepository.Purge(ctx, id)
+}
+
+// Delete remove o vídeo e o descarta do cache
+func (r *CachedVideoRepository) Delete(ctx context.Context, id string, expectedVersion int64) error {
+	defer r.Invalidate(id)
+	return r.VideoRepository.Delete(ctx, id, expectedVersion)
+}
+
+// Ensure CachedVideoRepository implements VideoRepository
+var _ domainRepository.VideoRepository = (*CachedVideoRepository)(nil)
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// countingVideoRepository conta as leituras de FindByID que chegam ao repositório e pode segurá-las
+type countingVideoRepository struct {
+	domainRepository.VideoRepository
+	reads   atomic.Int64
+	started chan struct{} // Recebe um sinal a cada leitura iniciada, se definido
+	release chan struct{} // Segura as leituras até ser fechado, se definido
+}
+
+func (r *countingVideoRepository) FindByID(ctx context.Context, id string) (*entity.Video, error) {
+	r.reads.Add(1)
+	if r.started != nil {
+		r.started <- struct{}{}
+	}
+	if r.release != nil {
+		<-r.release
+	}
+	return r.VideoRepository.FindByID(ctx, id)
+}
+
+// fakeVideoWatcher entrega as mudanças enviadas ao canal changes
+type fakeVideoWatcher struct {
+	changes chan domainRepository.VideoChange
+}
+
+func (w *fakeVideoWatcher) Watch(ctx context.Context, filter domainRepository.VideoWatchFilter) <-chan domainRepository.VideoChange {
+	return w.changes
+}
+
+func newCachedTestRepository(t *testing.T, config VideoCacheConfig) (*CachedVideoRepository, *countingVideoRepository) {
+	t.Helper()
+
+	inner := &countingVideoRepository{VideoRepository: NewVideoRepositoryMemory()}
+	return NewCachedVideoRepository(inner, config), inner
+}
+
+func TestCachedVideoRepositoryFindByID(t *testing.T) {
+	repo, inner := newCachedTestRepository(t, VideoCacheConfig{})
+	ctx := context.Background()
+
+	video := entity.NewVideo("owner-1", "Vídeo", "", "/path/to/video.mp4", "a")
+	require.NoError(t, repo.Create(ctx, video))
+
+	first, err := repo.FindByID(ctx, video.ID)
+	require.NoError(t, err)
+
+	// Alterar o vídeo retornado não altera o cache
+	first.Title = "Alterado"
+	first.Tags[0] = "alterada"
+
+	second, err := repo.FindByID(ctx, video.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Vídeo", second.Title)
+	assert.Equal(t, []string{"a"}, second.Tags)
+
+	assert.EqualValues(t, 1, inner.reads.Load())
+	stats := repo.CacheStats()
+	assert.EqualValues(t, 1, stats.Hits)
+	assert.EqualValues(t, 1, stats.Misses)
+	assert.Equal(t, 1, stats.Size)
+
+	// Vídeos inexistentes não são guardados
+	_, err = repo.FindByID(ctx, "inexistente")
+	assert.ErrorIs(t, err, domainRepository.ErrVideoNotFound)
+	_, err = repo.FindByID(ctx, "inexistente")
+	assert.ErrorIs(t, err, domainRepository.ErrVideoNotFound)
+	assert.EqualValues(t, 3, inner.reads.Load())
+}
+
+func TestCachedVideoRepositoryInvalidatesOnWrites(t *testing.T) {
+	repo, inner := newCachedTestRepository(t, VideoCacheConfig{})
+	ctx := context.Background()
+
+	video := entity.NewVideo("owner-1", "Vídeo", "", "/path/to/video.mp4")
+	require.NoError(t, repo.Create(ctx, video))
+	_, err := repo.FindByID(ctx, video.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.UpdateProgress(ctx, video.ID, 40, "encoding", nil))
+
+	found, err := repo.FindByID(ctx, video.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 40, found.Progress)
+	assert.EqualValues(t, 2, inner.reads.Load())
+
+	// Um Update recusado também descarta o vídeo, que está desatualizado
+	stale := cloneVideo(found)
+	stale.Version--
+	assert.ErrorIs(t, repo.Update(ctx, stale), domainRepository.ErrConcurrentModification)
+	assert.Zero(t, repo.CacheStats().Size)
+
+	// Operações em lote descartam todos os vídeos afetados
+	_, err = repo.FindByID(ctx, video.ID)
+	require.NoError(t, err)
+	_, err = repo.UpdateStatusMany(ctx, []string{video.ID}, entity.StatusError)
+	require.NoError(t, err)
+
+	found, err = repo.FindByID(ctx, video.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.StatusError, found.Status)
+	assert.EqualValues(t, 3, repo.CacheStats().Invalidations)
+}
+
+func TestCachedVideoRepositoryExpiresAndEvicts(t *testing.T) {
+	repo, inner := newCachedTestRepository(t, VideoCacheConfig{Capacity: 2, TTL: time.Minute})
+	ctx := context.Background()
+
+	now := time.Now()
+	repo.now = func() time.Time { return now }
+
+	videos := make([]*entity.Video, 3)
+	for i := range videos {
+		videos[i] = entity.NewVideo("owner-1", "Vídeo", "", "/path/to/video.mp4")
+		require.NoError(t, repo.Create(ctx, videos[i]))
+	}
+
+	// Com capacidade 2, ler o terceiro descarta o usado há mais tempo (o segundo, pois o primeiro foi relido)
+	for _, id := range []string{videos[0].ID, videos[1].ID, videos[0].ID, videos[2].ID} {
+		_, err := repo.FindByID(ctx, id)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 1, repo.CacheStats().Evictions)
+
+	_, err := repo.FindByID(ctx, videos[0].ID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, inner.reads.Load())
+
+	// Após o TTL, o vídeo é relido
+	now = now.Add(time.Minute)
+	_, err = repo.FindByID(ctx, videos[0].ID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, inner.reads.Load())
+}
+
+func TestCachedVideoRepositoryCoalescesConcurrentReads(t *testing.T) {
+	repo, inner := newCachedTestRepository(t, VideoCacheConfig{})
+	ctx := context.Background()
+
+	video := entity.NewVideo("owner-1", "Vídeo", "", "/path/to/video.mp4")
+	require.NoError(t, repo.Create(ctx, video))
+
+	inner.started = make(chan struct{}, 1)
+	inner.release = make(chan struct{})
+
+	var wg sync.WaitGroup
+	results := make([]*entity.Video, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = repo.FindByID(ctx, video.ID)
+		}()
+	}
+
+	<-inner.started
+	// Espera as demais leituras chegarem ao cache antes de liberar a primeira
+	require.Eventually(t, func() bool { return repo.CacheStats().Misses == 10 }, time.Second, time.Millisecond)
+	close(inner.release)
+	wg.Wait()
+
+	assert.EqualValues(t, 1, inner.reads.Load())
+	for _, result := range results {
+		require.NotNil(t, result)
+		assert.Equal(t, video.ID, result.ID)
+	}
+}
+
+func TestCachedVideoRepositoryDiscardsLoadInvalidatedMidway(t *testing.T) {
+	repo, inner := newCachedTestRepository(t, VideoCacheConfig{})
+	ctx := context.Background()
+
+	video := entity.NewVideo("owner-1", "Vídeo", "", "/path/to/video.mp4")
+	require.NoError(t, repo.Create(ctx, video))
+
+	inner.started = make(chan struct{}, 1)
+	inner.release = make(chan struct{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		repo.FindByID(ctx, video.ID)
+	}()
+
+	// A gravação termina enquanto a leitura anterior a ela ainda está em andamento
+	<-inner.started
+	repo.Invalidate(video.ID)
+	close(inner.release)
+	<-done
+
+	assert.Zero(t, repo.CacheStats().Size)
+}
+
+func TestCachedVideoRepositoryInvalidateOnChanges(t *testing.T) {
+	repo, _ := newCachedTestRepository(t, VideoCacheConfig{})
+	ctx := context.Background()
+
+	video := entity.NewVideo("owner-1", "Vídeo", "", "/path/to/video.mp4")
+	require.NoError(t, repo.Create(ctx, video))
+	_, err := repo.FindByID(ctx, video.ID)
+	require.NoError(t, err)
+
+	watcher := &fakeVideoWatcher{changes: make(chan domainRepository.VideoChange, 1)}
+	watcher.changes <- domainRepository.VideoChange{VideoID: video.ID, Status: entity.StatusProcessing}
+	close(watcher.changes)
+
+	repo.InvalidateOnChanges(ctx, watcher)
+
+	assert.Zero(t, repo.CacheStats().Size)
+	assert.EqualValues(t, 1, repo.CacheStats().Invalidations)
+}
+
+func TestCachedVideoRepositoryConformance(t *testing.T) {
+	suite.Run(t, &VideoRepositoryConformanceSuite{
+		newRepository: func(t *testing.T) domainRepository.VideoRepository {
+			return NewCachedVideoRepository(NewVideoRepositoryMemory(), VideoCacheConfig{})
+		},
+	})
+}
@@ -0,0 +1,785 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+	"github.com/devfullcycle/golangtechweek/internal/infra/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// VideoRepositoryConformanceSuite descreve a semântica esperada de toda implementação de VideoRepository
+// newRepository deve retornar um repositório vazio a cada teste
+type VideoRepositoryConformanceSuite struct {
+	suite.Suite
+	newRepository func(t *testing.T) domainRepository.VideoRepository
+	repo          domainRepository.VideoRepository
+	ctx           context.Context
+}
+
+func (s *VideoRepositoryConformanceSuite) SetupTest() {
+	s.repo = s.newRepository(s.T())
+	s.ctx = context.Background()
+}
+
+// createVideo cria um vídeo com a data de criação informada, em precisão de microssegundos como no banco
+func (s *VideoRepositoryConformanceSuite) createVideo(ownerID, title string, createdAt time.Time, tags ...string) *entity.Video {
+	video := entity.NewVideo(ownerID, title, "", "/path/to/"+title+".mp4", tags...)
+	video.CreatedAt = createdAt.UTC().Truncate(time.Microsecond)
+	video.UpdatedAt = video.CreatedAt
+	require.NoError(s.T(), s.repo.Create(s.ctx, video))
+	return video
+}
+
+func (s *VideoRepositoryConformanceSuite) TestCreateAndFindByID() {
+	video := entity.NewVideo("owner-1", "Vídeo", "Descrição", "/path/to/video.mp4", "golang", " golang ", "backend")
+	video.SetContentHash("ABC123")
+	require.NoError(s.T(), s.repo.Create(s.ctx, video))
+
+	found, err := s.repo.FindByID(s.ctx, video.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "owner-1", found.OwnerID)
+	assert.Equal(s.T(), "Descrição", found.Description)
+	assert.Equal(s.T(), []string{"golang", "backend"}, found.Tags)
+	assert.Equal(s.T(), "abc123", found.ContentHash)
+	assert.Equal(s.T(), entity.StatusPending, found.Status)
+	assert.Equal(s.T(), int64(1), found.Version)
+	assert.Empty(s.T(), found.Events())
+
+	err = s.repo.Create(s.ctx, video)
+	assert.ErrorIs(s.T(), err, domainRepository.ErrVideoAlreadyExists)
+
+	assert.ErrorIs(s.T(), s.repo.Create(s.ctx, entity.NewVideo("", "Sem dono", "", "/path")), entity.ErrOwnerIDRequired)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestFindByIDNotFound() {
+	_, err := s.repo.FindByID(s.ctx, "00000000-0000-0000-0000-000000000000")
+	assert.ErrorIs(s.T(), err, domainRepository.ErrVideoNotFound)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestReturnedVideosAreCopies() {
+	video := s.createVideo("owner-1", "Original", time.Now(), "golang")
+
+	found, err := s.repo.FindByID(s.ctx, video.ID)
+	require.NoError(s.T(), err)
+	found.Title = "Alterado"
+	found.Tags[0] = "alterada"
+	video.Title = "Alterado também"
+
+	again, err := s.repo.FindByID(s.ctx, video.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "Original", again.Title)
+	assert.Equal(s.T(), []string{"golang"}, again.Tags)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestSoftDelete() {
+	video := s.createVideo("owner-1", "Excluído", time.Now())
+
+	require.NoError(s.T(), s.repo.Delete(s.ctx, video.ID, video.Version))
+
+	_, err := s.repo.FindByID(s.ctx, video.ID)
+	assert.ErrorIs(s.T(), err, domainRepository.ErrVideoNotFound)
+	assert.ErrorIs(s.T(), s.repo.Delete(s.ctx, video.ID, video.Version+1), domainRepository.ErrVideoNotFound)
+	assert.ErrorIs(s.T(), s.repo.UpdateStatus(s.ctx, video.ID, video.Version+1, entity.StatusProcessing, ""), domainRepository.ErrVideoNotFound)
+	assert.ErrorIs(s.T(), s.repo.UpdateHLSPath(s.ctx, video.ID, video.Version+1, "/hls", "/hls/playlist.m3u8"), domainRepository.ErrVideoNotFound)
+	assert.ErrorIs(s.T(), s.repo.Update(s.ctx, video), domainRepository.ErrVideoNotFound)
+
+	videos, err := s.repo.List(s.ctx, domainRepository.VideoFilter{}, 1, 10)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), videos)
+
+	_, err = s.repo.ClaimNextPending(s.ctx, "worker-1", time.Minute)
+	assert.ErrorIs(s.T(), err, domainRepository.ErrNoPendingVideo)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestListPaginationOrder() {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	var created []*entity.Video
+	for i := 0; i < 5; i++ {
+		created = append(created, s.createVideo("owner-1", fmt.Sprintf("Vídeo %d", i), base.Add(time.Duration(i)*time.Minute)))
+	}
+
+	page1, err := s.repo.List(s.ctx, domainRepository.VideoFilter{}, 1, 2)
+	require.NoError(s.T(), err)
+	page3, err := s.repo.List(s.ctx, domainRepository.VideoFilter{}, 3, 2)
+	require.NoError(s.T(), err)
+	page4, err := s.repo.List(s.ctx, domainRepository.VideoFilter{}, 4, 2)
+	require.NoError(s.T(), err)
+
+	// Mais recentes primeiro
+	if assert.Len(s.T(), page1, 2) {
+		assert.Equal(s.T(), created[4].ID, page1[0].ID)
+		assert.Equal(s.T(), created[3].ID, page1[1].ID)
+	}
+	if assert.Len(s.T(), page3, 1) {
+		assert.Equal(s.T(), created[0].ID, page3[0].ID)
+	}
+	assert.Empty(s.T(), page4)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestListFilter() {
+	now := time.Now()
+	s.createVideo("owner-1", "A", now, "golang", "backend")
+	s.createVideo("owner-1", "B", now.Add(time.Second), "golang")
+	s.createVideo("owner-2", "C", now.Add(2*time.Second), "golang", "backend")
+
+	videos, err := s.repo.List(s.ctx, domainRepository.VideoFilter{OwnerID: "owner-1"}, 1, 10)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), videos, 2)
+
+	videos, err = s.repo.List(s.ctx, domainRepository.VideoFilter{Tags: []string{"golang", "backend"}}, 1, 10)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), videos, 2)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestListVideosCursor() {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	var created []*entity.Video
+	for i := 0; i < 5; i++ {
+		created = append(created, s.createVideo("owner-1", fmt.Sprintf("Aula %d", i), base.Add(time.Duration(i)*time.Minute)))
+	}
+	s.createVideo("owner-2", "Outro dono", base)
+
+	query := domainRepository.ListVideosQuery{
+		OwnerID:      "owner-1",
+		SortBy:       domainRepository.SortByTitle,
+		Direction:    domainRepository.SortAsc,
+		Limit:        2,
+		IncludeTotal: true,
+	}
+
+	var ids []string
+	for pages := 0; pages < 5; pages++ {
+		page, err := s.repo.ListVideos(s.ctx, query)
+		require.NoError(s.T(), err)
+		if assert.NotNil(s.T(), page.TotalCount) {
+			assert.Equal(s.T(), int64(5), *page.TotalCount)
+		}
+		for _, video := range page.Videos {
+			ids = append(ids, video.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	assert.Equal(s.T(), []string{created[0].ID, created[1].ID, created[2].ID, created[3].ID, created[4].ID}, ids)
+
+	page, err := s.repo.ListVideos(s.ctx, domainRepository.ListVideosQuery{
+		TitleContains: "AULA 3",
+		CreatedFrom:   &base,
+	})
+	require.NoError(s.T(), err)
+	if assert.Len(s.T(), page.Videos, 1) {
+		assert.Equal(s.T(), created[3].ID, page.Videos[0].ID)
+	}
+	assert.Empty(s.T(), page.NextCursor)
+
+	_, err = s.repo.ListVideos(s.ctx, domainRepository.ListVideosQuery{Cursor: "inválido"})
+	assert.ErrorIs(s.T(), err, domainRepository.ErrInvalidCursor)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestListVideosTitleOrder() {
+	now := time.Now()
+	for i, title := range []string{"ábaco", "banana", "Zebra", "abacate"} {
+		s.createVideo("owner-1", title, now.Add(time.Duration(i)*time.Second))
+	}
+
+	// Os títulos são ordenados pela ordem dos bytes, independente da collation do banco
+	page, err := s.repo.ListVideos(s.ctx, domainRepository.ListVideosQuery{
+		SortBy:    domainRepository.SortByTitle,
+		Direction: domainRepository.SortAsc,
+		Limit:     2,
+	})
+	require.NoError(s.T(), err)
+	next, err := s.repo.ListVideos(s.ctx, domainRepository.ListVideosQuery{
+		SortBy:    domainRepository.SortByTitle,
+		Direction: domainRepository.SortAsc,
+		Limit:     2,
+		Cursor:    page.NextCursor,
+	})
+	require.NoError(s.T(), err)
+
+	var titles []string
+	for _, video := range append(page.Videos, next.Videos...) {
+		titles = append(titles, video.Title)
+	}
+	assert.Equal(s.T(), []string{"Zebra", "abacate", "banana", "ábaco"}, titles)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestSearch() {
+	inTitle := entity.NewVideo("owner-1", "Curso de Golang", "Aula introdutória", "/path/a.mp4", "curso")
+	inDescription := entity.NewVideo("owner-1", "Aula 2", "Concorrência em golang com goroutines e canais", "/path/b.mp4")
+	otherOwner := entity.NewVideo("owner-2", "Golang avançado", "", "/path/c.mp4")
+	unrelated := entity.NewVideo("owner-1", "Receita de bolo", "Sem relação", "/path/d.mp4")
+	for _, video := range []*entity.Video{inTitle, inDescription, otherOwner, unrelated} {
+		require.NoError(s.T(), s.repo.Create(s.ctx, video))
+	}
+
+	page, err := s.repo.Search(s.ctx, "golang", domainRepository.VideoSearchFilters{OwnerID: "owner-1"}, "")
+	require.NoError(s.T(), err)
+	require.Len(s.T(), page.Results, 2)
+	assert.Empty(s.T(), page.NextCursor)
+
+	// O título pesa mais que a descrição
+	assert.Equal(s.T(), inTitle.ID, page.Results[0].Video.ID)
+	assert.Equal(s.T(), inDescription.ID, page.Results[1].Video.ID)
+	assert.Greater(s.T(), page.Results[0].Rank, page.Results[1].Rank)
+	assert.Contains(s.T(), page.Results[0].TitleHighlight, "<mark>Golang</mark>")
+	assert.Contains(s.T(), page.Results[1].DescriptionSnippet, "<mark>golang</mark>")
+
+	// Todos os termos precisam aparecer
+	page, err = s.repo.Search(s.ctx, "golang canais", domainRepository.VideoSearchFilters{}, "")
+	require.NoError(s.T(), err)
+	require.Len(s.T(), page.Results, 1)
+	assert.Equal(s.T(), inDescription.ID, page.Results[0].Video.ID)
+
+	// Operadores e pontuação não têm significado especial: a busca usa apenas as palavras
+	page, err = s.repo.Search(s.ctx, `"golang" or -canais`, domainRepository.VideoSearchFilters{}, "")
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), page.Results)
+
+	page, err = s.repo.Search(s.ctx, "golang | canais!", domainRepository.VideoSearchFilters{}, "")
+	require.NoError(s.T(), err)
+	require.Len(s.T(), page.Results, 1)
+	assert.Equal(s.T(), inDescription.ID, page.Results[0].Video.ID)
+
+	page, err = s.repo.Search(s.ctx, "golang", domainRepository.VideoSearchFilters{Tags: []string{"curso"}}, "")
+	require.NoError(s.T(), err)
+	require.Len(s.T(), page.Results, 1)
+	assert.Equal(s.T(), inTitle.ID, page.Results[0].Video.ID)
+
+	_, err = s.repo.Search(s.ctx, "  !? ", domainRepository.VideoSearchFilters{}, "")
+	assert.ErrorIs(s.T(), err, domainRepository.ErrEmptySearchQuery)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestSearchPagination() {
+	for i := 0; i < 5; i++ {
+		s.createVideo("owner-1", fmt.Sprintf("Golang parte %d", i), time.Now())
+	}
+	deleted := s.createVideo("owner-1", "Golang excluído", time.Now())
+	require.NoError(s.T(), s.repo.Delete(s.ctx, deleted.ID, deleted.Version))
+
+	seen := map[string]bool{}
+	cursor := ""
+	for pages := 0; pages < 3; pages++ {
+		page, err := s.repo.Search(s.ctx, "golang", domainRepository.VideoSearchFilters{Limit: 2}, cursor)
+		require.NoError(s.T(), err)
+		for _, result := range page.Results {
+			assert.False(s.T(), seen[result.Video.ID], "vídeo repetido entre páginas")
+			seen[result.Video.ID] = true
+		}
+		cursor = page.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+	assert.Len(s.T(), seen, 5)
+	assert.Empty(s.T(), cursor)
+
+	_, err := s.repo.Search(s.ctx, "outra busca", domainRepository.VideoSearchFilters{}, domainRepository.NewVideoSearchCursor("golang", domainRepository.VideoSearchFilters{}, 2).Encode())
+	assert.ErrorIs(s.T(), err, domainRepository.ErrInvalidCursor)
+
+	// O deslocamento de uma busca não vale para a mesma busca com outros filtros
+	other := domainRepository.NewVideoSearchCursor("golang", domainRepository.VideoSearchFilters{OwnerID: "owner-2"}, 2).Encode()
+	_, err = s.repo.Search(s.ctx, "golang", domainRepository.VideoSearchFilters{OwnerID: "owner-1"}, other)
+	assert.ErrorIs(s.T(), err, domainRepository.ErrInvalidCursor)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestUpdateStatusTransitions() {
+	video := s.createVideo("owner-1", "Status", time.Now())
+
+	require.NoError(s.T(), s.repo.UpdateStatus(s.ctx, video.ID, video.Version, entity.StatusProcessing, ""))
+	processing, err := s.repo.FindByID(s.ctx, video.ID)
+	require.NoError(s.T(), err)
+
+	time.Sleep(2 * time.Millisecond)
+	eta := time.Now().Add(time.Minute)
+	require.NoError(s.T(), s.repo.UpdateProgress(s.ctx, video.ID, 150, entity.ProcessingStageTranscoding, &eta))
+
+	found, err := s.repo.FindByID(s.ctx, video.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 100, found.Progress)
+	assert.NotNil(s.T(), found.EstimatedCompletionAt)
+	assert.Equal(s.T(), int64(2), found.Version, "UpdateProgress não deve alterar a versão")
+	assert.True(s.T(), found.UpdatedAt.After(processing.UpdatedAt), "UpdateProgress deve atualizar updated_at")
+
+	require.NoError(s.T(), s.repo.UpdateStatus(s.ctx, video.ID, video.Version+1, entity.StatusError, "falhou"))
+
+	found, err = s.repo.FindByID(s.ctx, video.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), entity.StatusError, found.Status)
+	assert.Equal(s.T(), "falhou", found.ErrorMessage)
+	assert.Equal(s.T(), entity.ProcessingStageNone, found.ProcessingStage)
+	assert.Nil(s.T(), found.EstimatedCompletionAt)
+	assert.Equal(s.T(), int64(3), found.Version)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestUpdateVersionConflict() {
+	video := s.createVideo("owner-1", "Versão", time.Now())
+
+	stale, err := s.repo.FindByID(s.ctx, video.ID)
+	require.NoError(s.T(), err)
+
+	require.NoError(s.T(), s.repo.UpdateS3URLs(s.ctx, video.ID, video.Version, "s3://video", "s3://video/playlist.m3u8"))
+
+	stale.SetDescription("gravação atrasada")
+	assert.ErrorIs(s.T(), s.repo.Update(s.ctx, stale), domainRepository.ErrConcurrentModification)
+
+	fresh, err := s.repo.FindByID(s.ctx, video.ID)
+	require.NoError(s.T(), err)
+	fresh.SetDescription("gravação atual")
+	require.NoError(s.T(), s.repo.Update(s.ctx, fresh))
+	assert.Equal(s.T(), int64(3), fresh.Version)
+
+	found, err := s.repo.FindByID(s.ctx, video.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "gravação atual", found.Description)
+	assert.Equal(s.T(), "s3://video/playlist.m3u8", found.S3ManifestURL)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestTargetedWritesVersionConflict() {
+	video := s.createVideo("owner-1", "Versão", time.Now())
+	id, stale := video.ID, video.Version
+
+	require.NoError(s.T(), s.repo.UpdateStatus(s.ctx, id, stale, entity.StatusProcessing, ""))
+
+	// Gravações baseadas na versão antiga são recusadas e não alteram o vídeo
+	writes := map[string]func(version int64) error{
+		"UpdateStatus": func(version int64) error {
+			return s.repo.UpdateStatus(s.ctx, id, version, entity.StatusError, "atrasado")
+		},
+		"UpdateHLSPath": func(version int64) error {
+			return s.repo.UpdateHLSPath(s.ctx, id, version, "/hls", "/hls/playlist.m3u8")
+		},
+		"UpdateS3Status": func(version int64) error {
+			return s.repo.UpdateS3Status(s.ctx, id, version, entity.UploadStatusPendingS3)
+		},
+		"UpdateS3URLs": func(version int64) error {
+			return s.repo.UpdateS3URLs(s.ctx, id, version, "s3://video", "s3://video/playlist.m3u8")
+		},
+		"UpdateS3Keys": func(version int64) error {
+			return s.repo.UpdateS3Keys(s.ctx, id, version, "videos/segmentos", "videos/playlist.m3u8")
+		},
+		"Delete": func(version int64) error {
+			return s.repo.Delete(s.ctx, id, version)
+		},
+	}
+	for name, write := range writes {
+		assert.ErrorIs(s.T(), write(stale), domainRepository.ErrConcurrentModification, name)
+	}
+
+	found, err := s.repo.FindByID(s.ctx, id)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), stale+1, found.Version)
+	assert.Equal(s.T(), entity.StatusProcessing, found.Status)
+	assert.Empty(s.T(), found.HLSPath)
+
+	// Vídeos inexistentes não são confundidos com conflito
+	id = "inexistente"
+	for name, write := range writes {
+		assert.ErrorIs(s.T(), write(found.Version), domainRepository.ErrVideoNotFound, name)
+	}
+}
+
+func (s *VideoRepositoryConformanceSuite) TestFindCompletedByContentHash() {
+	original := s.createVideo("owner-1", "Original", time.Now().Add(-time.Minute))
+	original.SetContentHash("hash-1")
+	original.MarkAsCompleted("/hls/original", "/hls/original/playlist.m3u8")
+	require.NoError(s.T(), s.repo.Update(s.ctx, original))
+
+	_, err := s.repo.FindCompletedByContentHash(s.ctx, "owner-2", "hash-1")
+	assert.ErrorIs(s.T(), err, domainRepository.ErrVideoNotFound)
+
+	found, err := s.repo.FindCompletedByContentHash(s.ctx, "owner-1", "hash-1")
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), original.ID, found.ID)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestClaimAndLeases() {
+	older := s.createVideo("owner-1", "Mais antigo", time.Now().Add(-time.Hour))
+	newer := s.createVideo("owner-1", "Mais novo", time.Now())
+
+	claimed, err := s.repo.ClaimNextPending(s.ctx, "worker-1", time.Minute)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), older.ID, claimed.ID)
+	assert.Equal(s.T(), entity.StatusProcessing, claimed.Status)
+	assert.Equal(s.T(), "worker-1", claimed.LeaseOwner)
+
+	claimed, err = s.repo.ClaimNextPending(s.ctx, "worker-2", time.Minute)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), newer.ID, claimed.ID)
+
+	_, err = s.repo.ClaimNextPending(s.ctx, "worker-3", time.Minute)
+	assert.ErrorIs(s.T(), err, domainRepository.ErrNoPendingVideo)
+
+	assert.ErrorIs(s.T(), s.repo.RenewLease(s.ctx, older.ID, "worker-2", time.Minute), domainRepository.ErrLeaseNotHeld)
+	require.NoError(s.T(), s.repo.RenewLease(s.ctx, older.ID, "worker-1", -time.Minute))
+
+	// Concessão expirada pode ser reivindicada novamente
+	claimed, err = s.repo.ClaimNextPending(s.ctx, "worker-3", time.Minute)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), older.ID, claimed.ID)
+	assert.Equal(s.T(), "worker-3", claimed.LeaseOwner)
+
+	require.NoError(s.T(), s.repo.ReleaseLease(s.ctx, older.ID, "worker-3"))
+	found, err := s.repo.FindByID(s.ctx, older.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), entity.StatusPending, found.Status)
+	assert.Empty(s.T(), found.LeaseOwner)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestCreateLeasedVideo() {
+	expiresAt := time.Now().Add(time.Minute).UTC().Truncate(time.Microsecond)
+	video := entity.NewVideo("owner-1", "Em processamento", "", "/path/to/leased.mp4")
+	video.MarkAsProcessing()
+	video.AcquireLease("worker-1", expiresAt)
+	require.NoError(s.T(), s.repo.Create(s.ctx, video))
+
+	found, err := s.repo.FindByID(s.ctx, video.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), entity.StatusProcessing, found.Status)
+	assert.Equal(s.T(), "worker-1", found.LeaseOwner)
+	if assert.NotNil(s.T(), found.LeaseExpiresAt) {
+		assert.True(s.T(), expiresAt.Equal(*found.LeaseExpiresAt))
+	}
+	assert.True(s.T(), found.HasActiveLease(time.Now()))
+
+	// O worker dono da concessão criada junto com o vídeo pode renová-la e liberá-la
+	require.NoError(s.T(), s.repo.RenewLease(s.ctx, video.ID, "worker-1", time.Minute))
+	require.NoError(s.T(), s.repo.ReleaseLease(s.ctx, video.ID, "worker-1"))
+}
+
+func (s *VideoRepositoryConformanceSuite) TestListStaleProcessing() {
+	oldest := s.createVideo("owner-1", "Expirado há mais tempo", time.Now().Add(-3*time.Hour))
+	expired := s.createVideo("owner-1", "Expirado", time.Now().Add(-2*time.Hour))
+	s.createVideo("owner-1", "Ativo", time.Now().Add(-time.Hour))
+	s.createVideo("owner-1", "Pendente", time.Now())
+
+	// Os vídeos são reivindicados do mais antigo para o mais novo
+	for _, workerID := range []string{"worker-1", "worker-2", "worker-3"} {
+		_, err := s.repo.ClaimNextPending(s.ctx, workerID, time.Hour)
+		require.NoError(s.T(), err)
+	}
+	require.NoError(s.T(), s.repo.RenewLease(s.ctx, oldest.ID, "worker-1", -2*time.Minute))
+	require.NoError(s.T(), s.repo.RenewLease(s.ctx, expired.ID, "worker-2", -time.Minute))
+
+	stale, err := s.repo.ListStaleProcessing(s.ctx, time.Now(), 10)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), stale, 2)
+	assert.Equal(s.T(), oldest.ID, stale[0].ID)
+	assert.Equal(s.T(), "worker-1", stale[0].LeaseOwner)
+	assert.Equal(s.T(), expired.ID, stale[1].ID)
+
+	stale, err = s.repo.ListStaleProcessing(s.ctx, time.Now(), 1)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), stale, 1)
+
+	stale, err = s.repo.ListStaleProcessing(s.ctx, time.Now().Add(-time.Hour), 10)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), stale)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestCreateMany() {
+	existing := s.createVideo("owner-1", "Existente", time.Now())
+
+	first := entity.NewVideo("owner-1", "Primeiro", "", "/path/to/first.mp4", "golang")
+	second := entity.NewVideo("owner-1", "Segundo", "", "/path/to/second.mp4")
+	invalid := entity.NewVideo("", "Sem dono", "", "/path/to/invalid.mp4")
+	repeated := *first
+
+	results, err := s.repo.CreateMany(s.ctx, []*entity.Video{first, existing, invalid, second, &repeated})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), results, 5)
+
+	assert.NoError(s.T(), results[0].Err)
+	assert.ErrorIs(s.T(), results[1].Err, domainRepository.ErrVideoAlreadyExists)
+	assert.ErrorIs(s.T(), results[2].Err, entity.ErrOwnerIDRequired)
+	assert.NoError(s.T(), results[3].Err)
+	assert.ErrorIs(s.T(), results[4].Err, domainRepository.ErrVideoAlreadyExists)
+	assert.Equal(s.T(), []string{first.ID, second.ID}, domainRepository.BulkSucceeded(results))
+
+	found, err := s.repo.FindManyByID(s.ctx, []string{second.ID, "00000000-0000-0000-0000-000000000000", first.ID, existing.ID})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), found, 3)
+	assert.Equal(s.T(), second.ID, found[0].ID)
+	assert.Equal(s.T(), []string{"golang"}, found[1].Tags)
+	assert.Equal(s.T(), "Existente", found[2].Title)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestCreateManyLargeImport() {
+	videos := make([]*entity.Video, 2500)
+	for i := range videos {
+		videos[i] = entity.NewVideo("owner-1", fmt.Sprintf("Vídeo %d", i), "", fmt.Sprintf("/import/%d.mp4", i))
+	}
+
+	results, err := s.repo.CreateMany(s.ctx, videos)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), domainRepository.BulkSucceeded(results), len(videos))
+
+	// Mais de um lote de INSERT
+	last, err := s.repo.FindByID(s.ctx, videos[len(videos)-1].ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "Vídeo 2499", last.Title)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestUpdateStatusMany() {
+	first := s.createVideo("owner-1", "Primeiro", time.Now())
+	second := s.createVideo("owner-1", "Segundo", time.Now())
+	missing := "00000000-0000-0000-0000-000000000000"
+
+	results, err := s.repo.UpdateStatusMany(s.ctx, []string{first.ID, missing, second.ID}, entity.StatusCompleted)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), results, 3)
+	assert.NoError(s.T(), results[0].Err)
+	assert.ErrorIs(s.T(), results[1].Err, domainRepository.ErrVideoNotFound)
+	assert.NoError(s.T(), results[2].Err)
+
+	found, err := s.repo.FindByID(s.ctx, second.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), entity.StatusCompleted, found.Status)
+	assert.Equal(s.T(), 100, found.Progress)
+	assert.Equal(s.T(), int64(2), found.Version)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestRequeueFailed() {
+	oldFailure := s.createVideo("owner-1", "Falha antiga", time.Now())
+	recentFailure := s.createVideo("owner-1", "Falha recente", time.Now())
+	otherOwner := s.createVideo("owner-2", "Outro dono", time.Now())
+	pending := s.createVideo("owner-1", "Pendente", time.Now())
+
+	require.NoError(s.T(), s.repo.UpdateStatus(s.ctx, oldFailure.ID, oldFailure.Version, entity.StatusError, "moov atom not found"))
+	time.Sleep(2 * time.Millisecond)
+	cutoff := time.Now()
+	time.Sleep(2 * time.Millisecond)
+	require.NoError(s.T(), s.repo.UpdateStatus(s.ctx, recentFailure.ID, recentFailure.Version, entity.StatusError, "timeout"))
+	require.NoError(s.T(), s.repo.UpdateStatus(s.ctx, otherOwner.ID, otherOwner.Version, entity.StatusError, "timeout"))
+
+	// Só as falhas do dono anteriores ao corte
+	results, err := s.repo.RequeueFailed(s.ctx, domainRepository.RequeueFailedFilter{OwnerID: "owner-1", FailedBefore: cutoff})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{oldFailure.ID}, domainRepository.BulkSucceeded(results))
+
+	found, err := s.repo.FindByID(s.ctx, oldFailure.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), entity.StatusPending, found.Status)
+	assert.Empty(s.T(), found.ErrorMessage)
+
+	// IDs informados recebem um resultado cada
+	missing := "00000000-0000-0000-0000-000000000000"
+	results, err = s.repo.RequeueFailed(s.ctx, domainRepository.RequeueFailedFilter{
+		VideoIDs: []string{recentFailure.ID, pending.ID, missing},
+		Limit:    1,
+	})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), results, 3)
+	assert.NoError(s.T(), results[0].Err)
+	assert.ErrorIs(s.T(), results[1].Err, domainRepository.ErrVideoNotFailed)
+	assert.ErrorIs(s.T(), results[2].Err, domainRepository.ErrVideoNotFound)
+
+	// Limite sem IDs informados
+	results, err = s.repo.RequeueFailed(s.ctx, domainRepository.RequeueFailedFilter{Limit: 5})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{otherOwner.ID}, domainRepository.BulkSucceeded(results))
+}
+
+func (s *VideoRepositoryConformanceSuite) TestRestore() {
+	video := s.createVideo("owner-1", "Vídeo", time.Now())
+	require.NoError(s.T(), s.repo.Delete(s.ctx, video.ID, video.Version))
+
+	require.NoError(s.T(), s.repo.Restore(s.ctx, video.ID))
+
+	// A exclusão e a restauração incrementam a versão
+	found, err := s.repo.FindByID(s.ctx, video.ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), video.Version+2, found.Version)
+
+	// Apenas vídeos excluídos podem ser restaurados
+	assert.ErrorIs(s.T(), s.repo.Restore(s.ctx, video.ID), domainRepository.ErrVideoNotFound)
+	assert.ErrorIs(s.T(), s.repo.Restore(s.ctx, "00000000-0000-0000-0000-000000000000"), domainRepository.ErrVideoNotFound)
+}
+
+func (s *VideoRepositoryConformanceSuite) TestPurge() {
+	// Em processamento com concessão ativa: reivindicado primeiro por ser o mais antigo
+	processing := s.createVideo("owner-1", "Em processamento", time.Now().Add(-time.Hour))
+	_, err := s.repo.ClaimNextPending(s.ctx, "worker-1", time.Hour)
+	require.NoError(s.T(), err)
+
+	deleted := s.createVideo("owner-1", "Excluído", time.Now())
+	active := s.createVideo("owner-1", "Ativo", time.Now())
+	failed := s.createVideo("owner-1", "Com falha", time.Now())
+	original := s.createVideo("owner-1", "Original", time.Now())
+
+	duplicate := entity.NewVideo("owner-1", "Duplicata", "", "/path/to/duplicate.mp4")
+	duplicate.DuplicateOfID = original.ID
+	require.NoError(s.T(), s.repo.Create(s.ctx, duplicate))
+
+	require.NoError(s.T(), s.repo.Delete(s.ctx, processing.ID, processing.Version+1))
+	require.NoError(s.T(), s.repo.Delete(s.ctx, deleted.ID, deleted.Version))
+	require.NoError(s.T(), s.repo.Delete(s.ctx, original.ID, original.Version))
+	require.NoError(s.T(), s.repo.UpdateS3Keys(s.ctx, failed.ID, failed.Version, "videos/com-falha/segmentos", ""))
+	require.NoError(s.T(), s.repo.UpdateStatus(s.ctx, failed.ID, failed.Version+1, entity.StatusError, "falha"))
+
+	criteria := domainRepository.PurgeCriteria{
+		DeletedBefore: time.Now().Add(time.Minute),
+		FailedBefore:  time.Now().Add(time.Minute),
+	}
+
+	candidates, err := s.repo.ListPurgeCandidates(s.ctx, criteria)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), candidates, 2)
+	assert.Equal(s.T(), deleted.ID, candidates[0].Video.ID)
+	assert.Equal(s.T(), domainRepository.PurgeReasonDeleted, candidates[0].Reason)
+	assert.NotNil(s.T(), candidates[0].DeletedAt)
+	assert.Empty(s.T(), candidates[0].S3Keys)
+	assert.Equal(s.T(), failed.ID, candidates[1].Video.ID)
+	assert.Equal(s.T(), domainRepository.PurgeReasonFailed, candidates[1].Reason)
+	assert.Nil(s.T(), candidates[1].DeletedAt)
+	// As chaves do S3 gravadas no vídeo acompanham o candidato, sem as vazias
+	assert.Equal(s.T(), []string{"videos/com-falha/segmentos"}, candidates[1].S3Keys)
+
+	// Critérios zerados não selecionam nada; o limite é respeitado
+	candidates, err = s.repo.ListPurgeCandidates(s.ctx, domainRepository.PurgeCriteria{})
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), candidates)
+
+	limited := criteria
+	limited.Limit = 1
+	candidates, err = s.repo.ListPurgeCandidates(s.ctx, limited)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), candidates, 1)
+
+	// Fora dos critérios: ativo, original com duplicata e em processamento
+	for _, id := range []string{active.ID, original.ID, processing.ID} {
+		assert.ErrorIs(s.T(), s.repo.BeginPurge(s.ctx, id, criteria), domainRepository.ErrVideoNotPurgeable)
+	}
+	assert.ErrorIs(s.T(), s.repo.Purge(s.ctx, deleted.ID), domainRepository.ErrVideoNotPurgeable)
+
+	require.NoError(s.T(), s.repo.BeginPurge(s.ctx, deleted.ID, criteria))
+	require.NoError(s.T(), s.repo.BeginPurge(s.ctx, failed.ID, criteria))
+
+	// Com a remoção iniciada, o vídeo não pode ser restaurado e o vídeo com falha deixa de ser visível
+	assert.ErrorIs(s.T(), s.repo.Restore(s.ctx, deleted.ID), domainRepository.ErrVideoNotFound)
+	_, err = s.repo.FindByID(s.ctx, failed.ID)
+	assert.ErrorIs(s.T(), err, domainRepository.ErrVideoNotFound)
+
+	// Remoções iniciadas e não concluídas continuam candidatas mesmo com os critérios desativados
+	candidates, err = s.repo.ListPurgeCandidates(s.ctx, domainRepository.PurgeCriteria{})
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), candidates, 2)
+
+	require.NoError(s.T(), s.repo.Purge(s.ctx, deleted.ID))
+	assert.ErrorIs(s.T(), s.repo.Purge(s.ctx, deleted.ID), domainRepository.ErrVideoNotPurgeable)
+	assert.ErrorIs(s.T(), s.repo.Restore(s.ctx, deleted.ID), domainRepository.ErrVideoNotFound)
+
+	// Sem a duplicata, o original passa a ser candidato
+	require.NoError(s.T(), s.repo.Delete(s.ctx, duplicate.ID, duplicate.Version))
+	require.NoError(s.T(), s.repo.BeginPurge(s.ctx, duplicate.ID, criteria))
+	require.NoError(s.T(), s.repo.Purge(s.ctx, duplicate.ID))
+	require.NoError(s.T(), s.repo.BeginPurge(s.ctx, original.ID, criteria))
+}
+
+func (s *VideoRepositoryConformanceSuite) TestStats() {
+	day1 := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	day2 := time.Date(2024, 3, 11, 15, 0, 0, 0, time.UTC)
+
+	// Concluído após ser reivindicado, o único com tempo de processamento
+	claimed := s.createVideo("owner-1", "Concluído", day1)
+	_, err := s.repo.ClaimNextPending(s.ctx, "worker-1", time.Hour)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), s.repo.UpdateStatus(s.ctx, claimed.ID, claimed.Version+1, entity.StatusCompleted, ""))
+
+	// Com falha, gravado pela entidade
+	ffmpegFailure := s.createVideo("owner-1", "Falha FFmpeg", day1.Add(time.Hour))
+	require.NoError(s.T(), s.repo.UpdateStatus(s.ctx, ffmpegFailure.ID, ffmpegFailure.Version, entity.StatusProcessing, ""))
+	found, err := s.repo.FindByID(s.ctx, ffmpegFailure.ID)
+	require.NoError(s.T(), err)
+	found.MarkAsFailed("erro na conversão FFmpeg: exit status 1")
+	require.NoError(s.T(), s.repo.Update(s.ctx, found))
+
+	// Concluído sem passar pelo processamento, como uma duplicata
+	linked := s.createVideo("owner-1", "Sem processamento", day1.Add(2*time.Hour))
+	require.NoError(s.T(), s.repo.UpdateStatus(s.ctx, linked.ID, linked.Version, entity.StatusCompleted, ""))
+
+	s.createVideo("owner-1", "Pendente", day2)
+	abandoned := s.createVideo("owner-1", "Abandonado", day2.Add(time.Hour))
+	require.NoError(s.T(), s.repo.UpdateStatus(s.ctx, abandoned.ID, abandoned.Version, entity.StatusError, "conversão abandonada após 3 tentativas"))
+
+	// Fora do filtro: excluído e de outra conta
+	deleted := s.createVideo("owner-1", "Excluído", day2)
+	require.NoError(s.T(), s.repo.Delete(s.ctx, deleted.ID, deleted.Version))
+	s.createVideo("owner-2", "Outra conta", day2)
+
+	stats, err := s.repo.Stats(s.ctx, domainRepository.VideoStatsFilter{OwnerID: "owner-1"})
+	require.NoError(s.T(), err)
+
+	assert.Equal(s.T(), 5, stats.Total)
+	assert.Equal(s.T(), map[string]int{entity.StatusCompleted: 2, entity.StatusError: 2, entity.StatusPending: 1}, stats.ByStatus)
+	assert.Equal(s.T(), map[string]int{entity.UploadStatusNone: 5}, stats.ByUploadStatus)
+
+	assert.Equal(s.T(), 1, stats.ProcessingTime.Count)
+	assert.GreaterOrEqual(s.T(), stats.ProcessingTime.P99, stats.ProcessingTime.P50)
+	assert.Equal(s.T(), stats.ProcessingTime.Average, stats.ProcessingTime.P50)
+
+	assert.Equal(s.T(), 4, stats.Failures.Finished)
+	assert.Equal(s.T(), 2, stats.Failures.Failed)
+	assert.Equal(s.T(), 0.5, stats.Failures.Rate)
+	assert.Equal(s.T(), []domainRepository.ErrorClassStats{
+		{Class: entity.ErrorClassAbandoned, Count: 1, Rate: 0.25},
+		{Class: entity.ErrorClassFFmpeg, Count: 1, Rate: 0.25},
+	}, stats.Failures.ByClass)
+
+	assert.Equal(s.T(), []domainRepository.DailyVideoStats{
+		{Date: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), Created: 3, Completed: 2, Failed: 1},
+		{Date: time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), Created: 2, Failed: 1},
+	}, stats.Daily)
+
+	// A janela considera a data de criação
+	from := day2.Truncate(24 * time.Hour)
+	stats, err = s.repo.Stats(s.ctx, domainRepository.VideoStatsFilter{OwnerID: "owner-1", CreatedFrom: &from})
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 2, stats.Total)
+	assert.Zero(s.T(), stats.ProcessingTime.Count)
+	assert.Equal(s.T(), 1.0, stats.Failures.Rate)
+
+	// Sem vídeos, as estatísticas são zeradas
+	stats, err = s.repo.Stats(s.ctx, domainRepository.VideoStatsFilter{OwnerID: "owner-3"})
+	require.NoError(s.T(), err)
+	assert.Zero(s.T(), stats.Total)
+	assert.Empty(s.T(), stats.Daily)
+	assert.Empty(s.T(), stats.Failures.ByClass)
+}
+
+func TestVideoRepositoryMemoryConformance(t *testing.T) {
+	suite.Run(t, &VideoRepositoryConformanceSuite{
+		newRepository: func(t *testing.T) domainRepository.VideoRepository {
+			return NewVideoRepositoryMemory()
+		},
+	})
+}
+
+func TestVideoRepositorySQLiteConformance(t *testing.T) {
+	suite.Run(t, &VideoRepositoryConformanceSuite{
+		newRepository: func(t *testing.T) domainRepository.VideoRepository {
+			db, err := database.Open(database.Config{
+				Driver:      database.DriverSQLite,
+				SQLitePath:  filepath.Join(t.TempDir(), "videos.db"),
+				AutoMigrate: true,
+			})
+			require.NoError(t, err)
+			t.Cleanup(func() { db.Close() })
+
+			repo, err := NewVideoRepository(database.DriverSQLite, db)
+			require.NoError(t, err)
+			return repo
+		},
+	})
+}
@@ -0,0 +1,1016 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+)
+
+// memoryVideo guarda um vídeo e as colunas que não fazem parte da entidade
+type memoryVideo struct {
+	video          entity.Video
+	segmentKey     string
+	manifestKey    string
+	deletedAt      *time.Time
+	purgeStartedAt *time.Time
+
+	processingStartedAt *time.Time
+	completedAt         *time.Time
+}
+
+// trackStatus registra o início do processamento e a conclusão na transição de previous para o status atual,
+// como statusTimestampsSet nas implementações SQL
+func (record *memoryVideo) trackStatus(previous string, now time.Time) {
+	switch status := record.video.Status; {
+	case status == entity.StatusProcessing && previous != entity.StatusProcessing:
+		record.processingStartedAt = &now
+		record.completedAt = nil
+	case status == entity.StatusProcessing:
+		record.completedAt = nil
+	case status == entity.StatusCompleted && previous != entity.StatusCompleted:
+		record.completedAt = &now
+	}
+}
+
+// VideoRepositoryMemory implementa a interface VideoRepository em memória, com a mesma semântica da
+// implementação PostgreSQL (erros, soft delete, ordenação e versões). Indicado para testes e execução local.
+// É seguro para uso concorrente; os vídeos são copiados na entrada e na saída, como aconteceria com o banco.
+type VideoRepositoryMemory struct {
+	mu     sync.RWMutex
+	videos map[string]*memoryVideo
+}
+
+// NewVideoRepositoryMemory cria uma nova instância de VideoRepositoryMemory vazia
+func NewVideoRepositoryMemory() *VideoRepositoryMemory {
+	return &VideoRepositoryMemory{
+		videos: make(map[string]*memoryVideo),
+	}
+}
+
+// cloneVideo copia o vídeo sem compartilhar slices, ponteiros ou eventos pendentes com o original
+func cloneVideo(src *entity.Video) *entity.Video {
+	video := *src
+	video.PullEvents()
+	video.Tags = append([]string{}, src.Tags...)
+	if src.EstimatedCompletionAt != nil {
+		t := *src.EstimatedCompletionAt
+		video.EstimatedCompletionAt = &t
+	}
+	if src.LeaseExpiresAt != nil {
+		t := *src.LeaseExpiresAt
+		video.LeaseExpiresAt = &t
+	}
+	return &video
+}
+
+// active retorna o registro do vídeo se ele existir e não tiver sido excluído
+// Deve ser chamada com o mutex adquirido
+func (r *VideoRepositoryMemory) active(id string) (*memoryVideo, bool) {
+	record, ok := r.videos[id]
+	if !ok || record.deletedAt != nil {
+		return nil, false
+	}
+	return record, true
+}
+
+// update aplica fn ao vídeo ativo se a versão ainda for expectedVersion
+// Retorna ErrConcurrentModification se o vídeo foi alterado desde a leitura
+func (r *VideoRepositoryMemory) update(id string, expectedVersion int64, fn func(record *memoryVideo)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.active(id)
+	if !ok {
+		return ErrVideoNotFound
+	}
+
+	if record.video.Version != expectedVersion {
+		return domainRepository.ErrConcurrentModification
+	}
+
+	record.apply(fn)
+
+	return nil
+}
+
+// apply aplica fn ao registro, incrementando a versão e a data de atualização
+// Deve ser chamada com o mutex adquirido
+func (record *memoryVideo) apply(fn func(record *memoryVideo)) {
+	previous := record.video.Status
+	now := time.Now()
+
+	fn(record)
+	record.video.Version++
+	record.video.UpdatedAt = now
+	record.trackStatus(previous, now)
+}
+
+// Create persiste um novo vídeo
+func (r *VideoRepositoryMemory) Create(ctx context.Context, video *entity.Video) error {
+	if err := video.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.videos[video.ID]; ok {
+		return fmt.Errorf("erro ao criar vídeo: %w", domainRepository.ErrVideoAlreadyExists)
+	}
+
+	stored := cloneVideo(video)
+	stored.Tags = entity.NormalizeTags(stored.Tags)
+	stored.Version = max(stored.Version, 1)
+	r.videos[video.ID] = &memoryVideo{video: *stored}
+
+	return nil
+}
+
+// FindByID busca um vídeo pelo seu ID
+func (r *VideoRepositoryMemory) FindByID(ctx context.Context, id string) (*entity.Video, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	record, ok := r.active(id)
+	if !ok {
+		return nil, ErrVideoNotFound
+	}
+
+	return cloneVideo(&record.video), nil
+}
+
+// snapshot retorna cópias dos vídeos ativos que satisfazem match
+func (r *VideoRepositoryMemory) snapshot(match func(video *entity.Video) bool) []*entity.Video {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var videos []*entity.Video
+	for _, record := range r.videos {
+		if record.deletedAt == nil && match(&record.video) {
+			videos = append(videos, cloneVideo(&record.video))
+		}
+	}
+	return videos
+}
+
+// hasAllTags verifica se o vídeo possui todas as tags informadas
+func hasAllTags(video *entity.Video, tags []string) bool {
+	for _, tag := range tags {
+		if !video.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// List retorna uma lista de vídeos com paginação, ordenada do mais recente para o mais antigo
+func (r *VideoRepositoryMemory) List(ctx context.Context, filter domainRepository.VideoFilter, page, pageSize int) ([]*entity.Video, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	tags := entity.NormalizeTags(filter.Tags)
+	videos := r.snapshot(func(video *entity.Video) bool {
+		return (filter.OwnerID == "" || video.OwnerID == filter.OwnerID) && hasAllTags(video, tags)
+	})
+
+	sort.Slice(videos, func(i, j int) bool {
+		return compareVideos(videos[i], videos[j], domainRepository.SortByCreatedAt) > 0
+	})
+
+	offset := (page - 1) * pageSize
+	if offset >= len(videos) {
+		return nil, nil
+	}
+
+	return videos[offset:min(offset+pageSize, len(videos))], nil
+}
+
+// compareVideos compara dois vídeos pela coluna de ordenação, desempatando pelo ID
+// Títulos são comparados pela ordem dos bytes, como a collation "C" do PostgreSQL e BINARY do SQLite
+func compareVideos(a, b *entity.Video, sortBy domainRepository.VideoSortField) int {
+	var c int
+	switch sortBy {
+	case domainRepository.SortByUpdatedAt:
+		c = a.UpdatedAt.Compare(b.UpdatedAt)
+	case domainRepository.SortByTitle:
+		c = strings.Compare(a.Title, b.Title)
+	default:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID, b.ID)
+}
+
+// cursorPosition monta um vídeo na posição do cursor, para ser comparado com compareVideos
+func cursorPosition(cursor domainRepository.VideoCursor) (*entity.Video, error) {
+	position := &entity.Video{ID: cursor.ID, Title: cursor.Value}
+	if cursor.SortBy == domainRepository.SortByTitle {
+		return position, nil
+	}
+
+	value, err := cursor.TimeValue()
+	if err != nil {
+		return nil, err
+	}
+	position.CreatedAt, position.UpdatedAt = value, value
+	return position, nil
+}
+
+// matchesQuery verifica se o vídeo atende aos filtros da consulta
+func matchesQuery(video *entity.Video, query domainRepository.ListVideosQuery) bool {
+	if query.OwnerID != "" && video.OwnerID != query.OwnerID {
+		return false
+	}
+	if len(query.Statuses) > 0 && !containsString(query.Statuses, video.Status) {
+		return false
+	}
+	if len(query.UploadStatuses) > 0 && !containsString(query.UploadStatuses, video.UploadStatus) {
+		return false
+	}
+	if !hasAllTags(video, query.Tags) {
+		return false
+	}
+	if title := strings.TrimSpace(query.TitleContains); title != "" &&
+		!strings.Contains(strings.ToLower(video.Title), strings.ToLower(title)) {
+		return false
+	}
+	if query.CreatedFrom != nil && video.CreatedAt.Before(*query.CreatedFrom) {
+		return false
+	}
+	if query.CreatedUntil != nil && !video.CreatedAt.Before(*query.CreatedUntil) {
+		return false
+	}
+	if query.UpdatedFrom != nil && video.UpdatedAt.Before(*query.UpdatedFrom) {
+		return false
+	}
+	if query.UpdatedUntil != nil && !video.UpdatedAt.Before(*query.UpdatedUntil) {
+		return false
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ListVideos retorna uma página de vídeos filtrada, ordenada e paginada por cursor
+func (r *VideoRepositoryMemory) ListVideos(ctx context.Context, query domainRepository.ListVideosQuery) (*domainRepository.VideoPage, error) {
+	query, err := query.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	var position *entity.Video
+	if query.Cursor != "" {
+		cursor, err := domainRepository.DecodeVideoCursor(query.Cursor, query.SortBy, query.Direction)
+		if err != nil {
+			return nil, err
+		}
+		if position, err = cursorPosition(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	// sign inverte as comparações na ordem decrescente
+	sign := -1
+	if query.Direction == domainRepository.SortAsc {
+		sign = 1
+	}
+
+	videos := r.snapshot(func(video *entity.Video) bool {
+		return matchesQuery(video, query)
+	})
+
+	page := &domainRepository.VideoPage{}
+	if query.IncludeTotal {
+		total := int64(len(videos))
+		page.TotalCount = &total
+	}
+
+	sort.Slice(videos, func(i, j int) bool {
+		return sign*compareVideos(videos[i], videos[j], query.SortBy) < 0
+	})
+
+	for _, video := range videos {
+		if position != nil && sign*compareVideos(video, position, query.SortBy) <= 0 {
+			continue
+		}
+		if len(page.Videos) == query.Limit {
+			last := page.Videos[len(page.Videos)-1]
+			page.NextCursor = domainRepository.NewVideoCursor(last, query.SortBy, query.Direction).Encode()
+			break
+		}
+		page.Videos = append(page.Videos, video)
+	}
+
+	return page, nil
+}
+
+// Search busca vídeos que contêm todas as palavras da busca no título ou na descrição
+// A relevância soma as ocorrências dos termos, com peso maior no título, como os pesos A e B do PostgreSQL
+func (r *VideoRepositoryMemory) Search(ctx context.Context, query string, filters domainRepository.VideoSearchFilters, cursor string) (*domainRepository.VideoSearchPage, error) {
+	terms := domainRepository.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, domainRepository.ErrEmptySearchQuery
+	}
+
+	searchCursor, err := domainRepository.DecodeVideoSearchCursor(cursor, query, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	filters = filters.Normalize()
+	listQuery := filters.ListQuery()
+
+	var results []domainRepository.VideoSearchResult
+	for _, video := range r.snapshot(func(video *entity.Video) bool { return matchesQuery(video, listQuery) }) {
+		titleWords := textWords(video.Title)
+		descriptionWords := textWords(video.Description)
+
+		var rank float64
+		matchesAll := true
+		for _, term := range terms {
+			titleCount, descriptionCount := countWord(titleWords, term), countWord(descriptionWords, term)
+			if titleCount == 0 && descriptionCount == 0 {
+				matchesAll = false
+				break
+			}
+			rank += float64(titleCount) + 0.4*float64(descriptionCount)
+		}
+		if !matchesAll {
+			continue
+		}
+
+		results = append(results, domainRepository.VideoSearchResult{
+			Video:              video,
+			Rank:               rank,
+			TitleHighlight:     highlightWords(video.Title, terms),
+			DescriptionSnippet: snippetAround(video.Description, terms, 35),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Video.ID < results[j].Video.ID
+	})
+
+	page := &domainRepository.VideoSearchPage{}
+	if searchCursor.Offset >= len(results) {
+		return page, nil
+	}
+
+	results = results[searchCursor.Offset:]
+	if len(results) > filters.Limit {
+		results = results[:filters.Limit]
+		page.NextCursor = domainRepository.NewVideoSearchCursor(query, filters, searchCursor.Offset+filters.Limit).Encode()
+	}
+	page.Results = results
+
+	return page, nil
+}
+
+// textWords divide o texto em palavras minúsculas, na mesma regra de SearchTerms, mantendo repetições
+func textWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), isWordSeparator)
+}
+
+func isWordSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func countWord(words []string, word string) int {
+	count := 0
+	for _, w := range words {
+		if w == word {
+			count++
+		}
+	}
+	return count
+}
+
+// highlightWords envolve com os marcadores de destaque as palavras do texto que estão em terms
+func highlightWords(text string, terms []string) string {
+	var b strings.Builder
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); {
+		if isWordSeparator(runes[i]) {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(runes) && !isWordSeparator(runes[j]) {
+			j++
+		}
+
+		word := string(runes[i:j])
+		if containsString(terms, strings.ToLower(word)) {
+			b.WriteString(domainRepository.HighlightStart + word + domainRepository.HighlightEnd)
+		} else {
+			b.WriteString(word)
+		}
+		i = j
+	}
+
+	return b.String()
+}
+
+// snippetAround retorna até maxWords palavras do texto a partir de pouco antes do primeiro termo encontrado, com destaque
+func snippetAround(text string, terms []string, maxWords int) string {
+	fields := strings.Fields(text)
+
+	start := 0
+	for i, field := range fields {
+		if slices.ContainsFunc(textWords(field), func(word string) bool { return containsString(terms, word) }) {
+			start = max(0, i-5)
+			break
+		}
+	}
+	end := min(len(fields), start+maxWords)
+
+	return highlightWords(strings.Join(fields[start:end], " "), terms)
+}
+
+// FindCompletedByContentHash busca um vídeo concluído da conta de cliente com a mesma impressão digital
+// Vídeos originais têm preferência sobre duplicatas e, entre eles, o mais antigo é retornado
+func (r *VideoRepositoryMemory) FindCompletedByContentHash(ctx context.Context, ownerID, contentHash string) (*entity.Video, error) {
+	videos := r.snapshot(func(video *entity.Video) bool {
+		return video.OwnerID == ownerID && video.ContentHash == contentHash && video.Status == entity.StatusCompleted
+	})
+	if len(videos) == 0 {
+		return nil, ErrVideoNotFound
+	}
+
+	sort.Slice(videos, func(i, j int) bool {
+		if videos[i].IsDuplicate() != videos[j].IsDuplicate() {
+			return !videos[i].IsDuplicate()
+		}
+		return videos[i].CreatedAt.Before(videos[j].CreatedAt)
+	})
+
+	return videos[0], nil
+}
+
+// Update grava todos os campos editáveis do vídeo se a versão armazenada ainda for video.Version
+func (r *VideoRepositoryMemory) Update(ctx context.Context, video *entity.Video) error {
+	if err := video.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.active(video.ID)
+	if !ok {
+		return ErrVideoNotFound
+	}
+	if record.video.Version != video.Version {
+		return domainRepository.ErrConcurrentModification
+	}
+
+	stored := cloneVideo(video)
+	stored.OwnerID = record.video.OwnerID
+	stored.CreatedAt = record.video.CreatedAt
+	stored.Tags = entity.NormalizeTags(stored.Tags)
+	stored.Progress = min(max(stored.Progress, 0), 100)
+	stored.Version++
+	stored.UpdatedAt = time.Now()
+	previous := record.video.Status
+	record.video = *stored
+	record.trackStatus(previous, stored.UpdatedAt)
+
+	video.Version = stored.Version
+	video.UpdatedAt = stored.UpdatedAt
+
+	return nil
+}
+
+// UpdateStatus atualiza o status de um vídeo e a mensagem de erro (quando aplicável)
+// O progresso e a concessão acompanham a transição de status, como na implementação PostgreSQL
+func (r *VideoRepositoryMemory) UpdateStatus(ctx context.Context, id string, expectedVersion int64, status string, errorMessage string) error {
+	return r.update(id, expectedVersion, setStatus(status, errorMessage))
+}
+
+// setStatus retorna a alteração de status usada por UpdateStatus e UpdateStatusMany
+func setStatus(status, errorMessage string) func(record *memoryVideo) {
+	return func(record *memoryVideo) {
+		video := &record.video
+		video.Status = status
+		video.ErrorMessage = errorMessage
+		video.EstimatedCompletionAt = nil
+		video.ProcessingStage = entity.ProcessingStageNone
+
+		switch status {
+		case entity.StatusProcessing:
+			video.Progress = 0
+			video.ProcessingStage = entity.ProcessingStageTranscoding
+		case entity.StatusCompleted:
+			video.Progress = 100
+		}
+
+		if status != entity.StatusProcessing {
+			video.LeaseOwner = ""
+			video.LeaseExpiresAt = nil
+		}
+	}
+}
+
+// UpdateProgress atualiza o progresso, a etapa e a estimativa de término da conversão
+// Assim como na implementação PostgreSQL, atualiza a data de atualização sem alterar a versão
+func (r *VideoRepositoryMemory) UpdateProgress(ctx context.Context, id string, progress int, stage string, estimatedCompletionAt *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.active(id)
+	if !ok {
+		return ErrVideoNotFound
+	}
+
+	record.video.Progress = min(max(progress, 0), 100)
+	record.video.ProcessingStage = stage
+	record.video.EstimatedCompletionAt = nil
+	if estimatedCompletionAt != nil {
+		t := *estimatedCompletionAt
+		record.video.EstimatedCompletionAt = &t
+	}
+	record.video.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// UpdateHLSPath atualiza os caminhos HLS de um vídeo
+func (r *VideoRepositoryMemory) UpdateHLSPath(ctx context.Context, id string, expectedVersion int64, hlsPath, manifestPath string) error {
+	return r.update(id, expectedVersion, func(record *memoryVideo) {
+		record.video.HLSPath = hlsPath
+		record.video.ManifestPath = manifestPath
+	})
+}
+
+// UpdateS3Status atualiza o status de upload para S3 de um vídeo
+func (r *VideoRepositoryMemory) UpdateS3Status(ctx context.Context, id string, expectedVersion int64, uploadStatus string) error {
+	return r.update(id, expectedVersion, func(record *memoryVideo) {
+		record.video.UploadStatus = uploadStatus
+	})
+}
+
+// UpdateS3URLs atualiza as URLs do S3 de um vídeo
+func (r *VideoRepositoryMemory) UpdateS3URLs(ctx context.Context, id string, expectedVersion int64, s3URL, s3ManifestURL string) error {
+	return r.update(id, expectedVersion, func(record *memoryVideo) {
+		record.video.S3URL = s3URL
+		record.video.S3ManifestURL = s3ManifestURL
+	})
+}
+
+// UpdateS3Keys atualiza as chaves do S3 de um vídeo
+func (r *VideoRepositoryMemory) UpdateS3Keys(ctx context.Context, id string, expectedVersion int64, segmentKey string, manifestKey string) error {
+	return r.update(id, expectedVersion, func(record *memoryVideo) {
+		record.segmentKey = segmentKey
+		record.manifestKey = manifestKey
+	})
+}
+
+// ClaimNextPending reivindica o vídeo pendente mais antigo, ou um vídeo com concessão expirada
+func (r *VideoRepositoryMemory) ClaimNextPending(ctx context.Context, workerID string, leaseDuration time.Duration) (*entity.Video, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+
+	var next *memoryVideo
+	for _, record := range r.videos {
+		video := &record.video
+		if record.deletedAt != nil {
+			continue
+		}
+
+		expired := video.Status == entity.StatusProcessing && video.LeaseExpiresAt != nil && video.LeaseExpiresAt.Before(now)
+		if video.Status != entity.StatusPending && !expired {
+			continue
+		}
+
+		if next == nil || compareVideos(video, &next.video, domainRepository.SortByCreatedAt) < 0 {
+			next = record
+		}
+	}
+
+	if next == nil {
+		return nil, domainRepository.ErrNoPendingVideo
+	}
+
+	expiresAt := now.Add(leaseDuration)
+	video := &next.video
+	video.Status = entity.StatusProcessing
+	video.LeaseOwner = workerID
+	video.LeaseExpiresAt = &expiresAt
+	video.Progress = 0
+	video.ProcessingStage = entity.ProcessingStageTranscoding
+	video.EstimatedCompletionAt = nil
+	video.ErrorMessage = ""
+	video.UpdatedAt = now
+	video.Version++
+	next.processingStartedAt = &now
+	next.completedAt = nil
+
+	return cloneVideo(video), nil
+}
+
+// RenewLease estende a concessão do worker sobre um vídeo em processamento
+func (r *VideoRepositoryMemory) RenewLease(ctx context.Context, id, workerID string, leaseDuration time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.active(id)
+	if !ok || record.video.LeaseOwner != workerID || record.video.Status != entity.StatusProcessing {
+		return domainRepository.ErrLeaseNotHeld
+	}
+
+	expiresAt := time.Now().Add(leaseDuration)
+	record.video.LeaseExpiresAt = &expiresAt
+
+	return nil
+}
+
+// ReleaseLease encerra a concessão do worker, devolvendo à fila um vídeo que ainda não terminou
+func (r *VideoRepositoryMemory) ReleaseLease(ctx context.Context, id, workerID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.active(id)
+	if !ok || record.video.LeaseOwner == "" || record.video.LeaseOwner != workerID {
+		return domainRepository.ErrLeaseNotHeld
+	}
+
+	video := &record.video
+	video.LeaseOwner = ""
+	video.LeaseExpiresAt = nil
+	video.EstimatedCompletionAt = nil
+	if video.Status == entity.StatusProcessing {
+		video.Status = entity.StatusPending
+		video.ProcessingStage = entity.ProcessingStageNone
+	}
+	video.UpdatedAt = time.Now()
+	video.Version++
+
+	return nil
+}
+
+// ListStaleProcessing lista os vídeos em processamento considerados abandonados em staleBefore
+func (r *VideoRepositoryMemory) ListStaleProcessing(ctx context.Context, staleBefore time.Time, limit int) ([]*entity.Video, error) {
+	if limit < 1 {
+		limit = 10
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var stale []*entity.Video
+	for _, record := range r.videos {
+		if record.deletedAt == nil && record.video.IsStale(staleBefore) {
+			stale = append(stale, cloneVideo(&record.video))
+		}
+	}
+
+	sort.Slice(stale, func(i, j int) bool {
+		a, b := staleSince(stale[i]), staleSince(stale[j])
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return stale[i].ID < stale[j].ID
+	})
+
+	if len(stale) > limit {
+		stale = stale[:limit]
+	}
+
+	return stale, nil
+}
+
+// staleSince retorna o instante a partir do qual o vídeo em processamento deixou de dar sinal de vida
+func staleSince(video *entity.Video) time.Time {
+	if video.LeaseExpiresAt != nil {
+		return *video.LeaseExpiresAt
+	}
+	return video.UpdatedAt
+}
+
+// FindManyByID busca os vídeos pelos IDs, na ordem informada
+func (r *VideoRepositoryMemory) FindManyByID(ctx context.Context, ids []string) ([]*entity.Video, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var videos []*entity.Video
+	for _, id := range ids {
+		if record, ok := r.active(id); ok {
+			videos = append(videos, cloneVideo(&record.video))
+		}
+	}
+
+	return orderByIDs(videos, ids), nil
+}
+
+// CreateMany persiste os vídeos válidos com IDs ainda não usados, sob um único lock
+func (r *VideoRepositoryMemory) CreateMany(ctx context.Context, videos []*entity.Video) ([]domainRepository.BulkResult, error) {
+	results, candidates := prepareBulkCreate(videos)
+	inserted := make(map[string]bool, len(candidates))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, video := range candidates {
+		if _, ok := r.videos[video.ID]; ok {
+			continue
+		}
+
+		stored := cloneVideo(video)
+		stored.Tags = entity.NormalizeTags(stored.Tags)
+		stored.Version = max(stored.Version, 1)
+		r.videos[video.ID] = &memoryVideo{video: *stored}
+		inserted[video.ID] = true
+	}
+
+	markBulkResults(results, inserted, domainRepository.ErrVideoAlreadyExists)
+	return results, nil
+}
+
+// UpdateStatusMany aplica a alteração de UpdateStatus a cada vídeo existente, sem conferir a versão
+func (r *VideoRepositoryMemory) UpdateStatusMany(ctx context.Context, ids []string, status string) ([]domainRepository.BulkResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := newBulkResults(ids)
+	for i, id := range ids {
+		record, ok := r.active(id)
+		if !ok {
+			results[i].Err = ErrVideoNotFound
+			continue
+		}
+		record.apply(setStatus(status, ""))
+	}
+	return results, nil
+}
+
+// RequeueFailed devolve para a fila os vídeos com falha que atendem ao filtro, sob um único lock
+func (r *VideoRepositoryMemory) RequeueFailed(ctx context.Context, filter domainRepository.RequeueFailedFilter) ([]domainRepository.BulkResult, error) {
+	r.mu.Lock()
+
+	var failed []*memoryVideo
+	for _, record := range r.videos {
+		video := &record.video
+		if record.deletedAt != nil || video.Status != entity.StatusError {
+			continue
+		}
+		if filter.OwnerID != "" && video.OwnerID != filter.OwnerID {
+			continue
+		}
+		if len(filter.VideoIDs) > 0 && !slices.Contains(filter.VideoIDs, video.ID) {
+			continue
+		}
+		if !filter.FailedBefore.IsZero() && !video.UpdatedAt.Before(filter.FailedBefore) {
+			continue
+		}
+		failed = append(failed, record)
+	}
+
+	sort.Slice(failed, func(i, j int) bool {
+		a, b := &failed[i].video, &failed[j].video
+		if !a.UpdatedAt.Equal(b.UpdatedAt) {
+			return a.UpdatedAt.Before(b.UpdatedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	if filter.Limit > 0 && len(filter.VideoIDs) == 0 && len(failed) > filter.Limit {
+		failed = failed[:filter.Limit]
+	}
+
+	now := time.Now()
+	requeued := make([]string, len(failed))
+	for i, record := range failed {
+		record.video.Requeue()
+		record.video.UpdatedAt = now
+		record.video.Version++
+		requeued[i] = record.video.ID
+	}
+
+	r.mu.Unlock()
+
+	return requeueResults(ctx, r, filter, requeued)
+}
+
+// Restore desfaz a exclusão do vídeo, se a remoção definitiva ainda não começou
+func (r *VideoRepositoryMemory) Restore(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.videos[id]
+	if !ok || record.deletedAt == nil || record.purgeStartedAt != nil {
+		return ErrVideoNotFound
+	}
+
+	record.deletedAt = nil
+	record.video.Version++
+	record.video.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// purgeable verifica se o vídeo atende aos critérios de remoção definitiva, como purgeCondition
+// Deve ser chamada com o mutex adquirido
+func (r *VideoRepositoryMemory) purgeable(record *memoryVideo, criteria domainRepository.PurgeCriteria, now time.Time) bool {
+	video := &record.video
+
+	eligible := record.purgeStartedAt != nil ||
+		(record.deletedAt != nil && !criteria.DeletedBefore.IsZero() && record.deletedAt.Before(criteria.DeletedBefore)) ||
+		(video.Status == entity.StatusError && !criteria.FailedBefore.IsZero() && video.UpdatedAt.Before(criteria.FailedBefore))
+	if !eligible || (video.Status == entity.StatusProcessing && video.HasActiveLease(now)) {
+		return false
+	}
+
+	for _, other := range r.videos {
+		if other.video.DuplicateOfID == video.ID {
+			return false
+		}
+	}
+	return true
+}
+
+// ListPurgeCandidates lista os vídeos que passaram da retenção, do mais antigo para o mais recente
+func (r *VideoRepositoryMemory) ListPurgeCandidates(ctx context.Context, criteria domainRepository.PurgeCriteria) ([]domainRepository.PurgeCandidate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	since := func(record *memoryVideo) time.Time {
+		if record.deletedAt != nil {
+			return *record.deletedAt
+		}
+		return record.video.UpdatedAt
+	}
+
+	var records []*memoryVideo
+	for _, record := range r.videos {
+		if r.purgeable(record, criteria, now) {
+			records = append(records, record)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		a, b := since(records[i]), since(records[j])
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return records[i].video.ID < records[j].video.ID
+	})
+
+	if criteria.Limit > 0 && len(records) > criteria.Limit {
+		records = records[:criteria.Limit]
+	}
+
+	candidates := make([]domainRepository.PurgeCandidate, 0, len(records))
+	for _, record := range records {
+		var deletedAt *time.Time
+		if record.deletedAt != nil {
+			t := *record.deletedAt
+			deletedAt = &t
+		}
+		candidates = append(candidates, newPurgeCandidate(cloneVideo(&record.video), deletedAt, record.segmentKey, record.manifestKey))
+	}
+
+	return candidates, nil
+}
+
+// BeginPurge marca o vídeo como em remoção, excluindo-o se ainda não estiver excluído
+func (r *VideoRepositoryMemory) BeginPurge(ctx context.Context, id string, criteria domainRepository.PurgeCriteria) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+
+	record, ok := r.videos[id]
+	if !ok || !r.purgeable(record, criteria, now) {
+		return domainRepository.ErrVideoNotPurgeable
+	}
+
+	if record.deletedAt == nil {
+		record.deletedAt = &now
+	}
+	if record.purgeStartedAt == nil {
+		record.purgeStartedAt = &now
+	}
+
+	return nil
+}
+
+// Purge apaga o vídeo
+func (r *VideoRepositoryMemory) Purge(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.videos[id]
+	if !ok || record.purgeStartedAt == nil {
+		return domainRepository.ErrVideoNotPurgeable
+	}
+
+	delete(r.videos, id)
+	return nil
+}
+
+// Stats calcula as estatísticas percorrendo os vídeos que atendem ao filtro
+func (r *VideoRepositoryMemory) Stats(ctx context.Context, filter domainRepository.VideoStatsFilter) (*domainRepository.VideoStats, error) {
+	query := filter.ListQuery()
+	stats := domainRepository.NewVideoStats()
+
+	var durations []time.Duration
+	failures := make(map[string]int)
+	daily := make(map[time.Time]*domainRepository.DailyVideoStats)
+
+	r.mu.RLock()
+	for _, record := range r.videos {
+		video := &record.video
+		if record.deletedAt != nil || !matchesQuery(video, query) {
+			continue
+		}
+
+		stats.Total++
+		stats.ByStatus[video.Status]++
+		stats.ByUploadStatus[video.UploadStatus]++
+
+		day := video.CreatedAt.UTC().Truncate(24 * time.Hour)
+		if daily[day] == nil {
+			daily[day] = &domainRepository.DailyVideoStats{Date: day}
+		}
+		daily[day].Created++
+
+		switch video.Status {
+		case entity.StatusCompleted:
+			daily[day].Completed++
+			if record.processingStartedAt != nil && record.completedAt != nil {
+				durations = append(durations, record.completedAt.Sub(*record.processingStartedAt))
+			}
+		case entity.StatusError:
+			daily[day].Failed++
+			failures[video.ErrorMessage]++
+		}
+	}
+	r.mu.RUnlock()
+
+	stats.ProcessingTime = domainRepository.NewProcessingTimeStats(durations)
+	stats.Failures = domainRepository.NewFailureStats(
+		stats.ByStatus[entity.StatusCompleted]+stats.ByStatus[entity.StatusError], failures)
+
+	for _, totals := range daily {
+		stats.Daily = append(stats.Daily, *totals)
+	}
+	sort.Slice(stats.Daily, func(i, j int) bool {
+		return stats.Daily[i].Date.Before(stats.Daily[j].Date)
+	})
+
+	return stats, nil
+}
+
+// Delete remove um vídeo do repositório (soft delete)
+func (r *VideoRepositoryMemory) Delete(ctx context.Context, id string, expectedVersion int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.active(id)
+	if !ok {
+		return ErrVideoNotFound
+	}
+
+	if record.video.Version != expectedVersion {
+		return domainRepository.ErrConcurrentModification
+	}
+
+	now := time.Now()
+	record.deletedAt = &now
+	record.video.Version++
+
+	return nil
+}
+
+// Ensure VideoRepositoryMemory implements VideoRepository
+var _ domainRepository.VideoRepository = (*VideoRepositoryMemory)(nil)
@@ -0,0 +1,1130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// VideoRepositorySQLite implementa a interface VideoRepository usando SQLite embarcado
+// As datas são gravadas em microssegundos UTC e as tags como array JSON (ver migrations/sqlite)
+type VideoRepositorySQLite struct {
+	db *sql.DB
+}
+
+// NewVideoRepositorySQLite cria uma nova instância de VideoRepositorySQLite
+// O banco deve estar migrado (database.Open com AutoMigrate ou database.Migrator)
+func NewVideoRepositorySQLite(db *sql.DB) *VideoRepositorySQLite {
+	return &VideoRepositorySQLite{
+		db: db,
+	}
+}
+
+// sqliteVideoColumns lista as colunas lidas em todas as consultas de vídeos, na ordem esperada por scanSQLiteVideo
+const sqliteVideoColumns = `
+	id, owner_id, title, description, tags, file_path, content_hash, duplicate_of, status, upload_status,
+	hls_path, manifest_path, s3_url, s3_manifest_url, error_message,
+	progress, processing_stage, estimated_completion_at, lease_owner, lease_expires_at,
+	created_at, updated_at, version
+`
+
+// sqliteTime converte uma data para o formato gravado no SQLite
+func sqliteTime(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
+// sqliteNullTime converte uma data opcional para o formato gravado no SQLite
+func sqliteNullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UnixMicro()
+}
+
+// timeFromSQLite converte o valor gravado no SQLite em data
+func timeFromSQLite(micros int64) time.Time {
+	return time.UnixMicro(micros).UTC()
+}
+
+// sqliteTags serializa as tags normalizadas como array JSON
+func sqliteTags(tags []string) string {
+	data, _ := json.Marshal(entity.NormalizeTags(tags))
+	return string(data)
+}
+
+// scanSQLiteVideo converte uma linha do banco de dados em uma entidade Video
+// extra recebe as colunas selecionadas após sqliteVideoColumns, quando houver
+func scanSQLiteVideo(row rowScanner, extra ...any) (*entity.Video, error) {
+	var video entity.Video
+	var tags string
+	var createdAt, updatedAt int64
+	var estimatedCompletionAt, leaseExpiresAt sql.NullInt64
+
+	dest := []any{
+		&video.ID,
+		&video.OwnerID,
+		&video.Title,
+		&video.Description,
+		&tags,
+		&video.FilePath,
+		&video.ContentHash,
+		&video.DuplicateOfID,
+		&video.Status,
+		&video.UploadStatus,
+		&video.HLSPath,
+		&video.ManifestPath,
+		&video.S3URL,
+		&video.S3ManifestURL,
+		&video.ErrorMessage,
+		&video.Progress,
+		&video.ProcessingStage,
+		&estimatedCompletionAt,
+		&video.LeaseOwner,
+		&leaseExpiresAt,
+		&createdAt,
+		&updatedAt,
+		&video.Version,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(tags), &video.Tags); err != nil {
+		return nil, fmt.Errorf("erro ao ler tags do vídeo: %w", err)
+	}
+	if estimatedCompletionAt.Valid {
+		t := timeFromSQLite(estimatedCompletionAt.Int64)
+		video.EstimatedCompletionAt = &t
+	}
+	if leaseExpiresAt.Valid {
+		t := timeFromSQLite(leaseExpiresAt.Int64)
+		video.LeaseExpiresAt = &t
+	}
+	video.CreatedAt = timeFromSQLite(createdAt)
+	video.UpdatedAt = timeFromSQLite(updatedAt)
+
+	return &video, nil
+}
+
+// checkRowsAffected retorna ErrVideoNotFound quando a atualização não encontrou o vídeo
+func checkRowsAffected(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao obter linhas afetadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrVideoNotFound
+	}
+
+	return nil
+}
+
+// sqliteVideoInsertColumns são as colunas gravadas na criação de vídeos, na ordem de sqliteVideoInsertArgs
+const sqliteVideoInsertColumns = `id, owner_id, title, description, tags, file_path, content_hash, duplicate_of, status, upload_status,
+	hls_path, manifest_path, s3_url, s3_manifest_url, error_message, progress, processing_stage,
+	estimated_completion_at, lease_owner, lease_expires_at, created_at, updated_at, version`
+
+// sqliteVideoInsertColumnCount é o número de colunas (e de parâmetros) por vídeo inserido
+const sqliteVideoInsertColumnCount = 23
+
+// sqliteVideoInsertRows é o máximo de vídeos por INSERT em CreateMany, abaixo do limite de 32766 parâmetros
+const sqliteVideoInsertRows = 1000
+
+// sqliteVideoInsertRow é a tupla VALUES de um vídeo
+// Usa parâmetros anônimos: com ?N, o driver procura o nome de cada parâmetro em uma lista linear,
+// o que torna a ligação dos parâmetros quadrática nos INSERTs de CreateMany
+var sqliteVideoInsertRow = "(" + strings.Repeat("?, ", sqliteVideoInsertColumnCount-1) + "?)"
+
+// sqliteVideoInsertArgs retorna os parâmetros de um vídeo na ordem de sqliteVideoInsertColumns
+func sqliteVideoInsertArgs(video *entity.Video) []any {
+	return []any{
+		video.ID,
+		video.OwnerID,
+		video.Title,
+		video.Description,
+		sqliteTags(video.Tags),
+		video.FilePath,
+		video.ContentHash,
+		video.DuplicateOfID,
+		video.Status,
+		video.UploadStatus,
+		video.HLSPath,
+		video.ManifestPath,
+		video.S3URL,
+		video.S3ManifestURL,
+		video.ErrorMessage,
+		min(max(video.Progress, 0), 100),
+		video.ProcessingStage,
+		sqliteNullTime(video.EstimatedCompletionAt),
+		video.LeaseOwner,
+		sqliteNullTime(video.LeaseExpiresAt),
+		sqliteTime(video.CreatedAt),
+		sqliteTime(video.UpdatedAt),
+		max(video.Version, 1),
+	}
+}
+
+// Create persiste um novo vídeo no banco de dados
+func (r *VideoRepositorySQLite) Create(ctx context.Context, video *entity.Video) error {
+	if err := video.Validate(); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO videos (` + sqliteVideoInsertColumns + `) VALUES ` + sqliteVideoInsertRow
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, sqliteVideoInsertArgs(video)...)
+	if err != nil {
+		var sqliteErr *sqlite.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY {
+			return fmt.Errorf("erro ao criar vídeo: %w", domainRepository.ErrVideoAlreadyExists)
+		}
+		return fmt.Errorf("erro ao criar vídeo: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID busca um vídeo pelo seu ID
+func (r *VideoRepositorySQLite) FindByID(ctx context.Context, id string) (*entity.Video, error) {
+	query := `SELECT ` + sqliteVideoColumns + `
+		FROM videos
+		WHERE id = ?1 AND deleted_at IS NULL
+	`
+
+	video, err := scanSQLiteVideo(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrVideoNotFound
+		}
+		return nil, fmt.Errorf("erro ao buscar vídeo: %w", err)
+	}
+
+	return video, nil
+}
+
+// queryVideos executa uma consulta de vídeos e converte todas as linhas
+func (r *VideoRepositorySQLite) queryVideos(ctx context.Context, query string, args ...any) ([]*entity.Video, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar vídeos: %w", err)
+	}
+	defer rows.Close()
+
+	var videos []*entity.Video
+
+	for rows.Next() {
+		video, err := scanSQLiteVideo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear vídeo: %w", err)
+		}
+
+		videos = append(videos, video)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os resultados: %w", err)
+	}
+
+	return videos, nil
+}
+
+// sqliteArgs acumula os parâmetros de uma consulta SQLite montada dinamicamente
+type sqliteArgs []any
+
+// add registra um parâmetro e retorna seu placeholder (?1, ?2, ...)
+func (a *sqliteArgs) add(value any) string {
+	*a = append(*a, value)
+	return fmt.Sprintf("?%d", len(*a))
+}
+
+// addList registra vários parâmetros e retorna seus placeholders separados por vírgula
+func (a *sqliteArgs) addList(values []string) string {
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		placeholders[i] = a.add(value)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// sqliteTagsCondition exige que o vídeo possua todas as tags do array JSON informado
+const sqliteTagsCondition = `NOT EXISTS (
+	SELECT 1 FROM json_each(%s) AS wanted
+	WHERE wanted.value NOT IN (SELECT value FROM json_each(videos.tags))
+)`
+
+// List retorna uma lista de vídeos com paginação, filtrada por dono e tags
+func (r *VideoRepositorySQLite) List(ctx context.Context, filter domainRepository.VideoFilter, page, pageSize int) ([]*entity.Video, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	offset := (page - 1) * pageSize
+
+	query := `SELECT ` + sqliteVideoColumns + `
+		FROM videos
+		WHERE deleted_at IS NULL
+			AND (?1 = '' OR owner_id = ?1)
+			AND ` + fmt.Sprintf(sqliteTagsCondition, "?2") + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?3 OFFSET ?4
+	`
+
+	return r.queryVideos(ctx, query, filter.OwnerID, sqliteTags(filter.Tags), pageSize, offset)
+}
+
+// ListVideos retorna uma página de vídeos filtrada, ordenada e paginada por cursor (keyset pagination)
+// Segue a mesma lógica de VideoRepositoryPostgres.ListVideos; o trecho do título é comparado com LIKE,
+// que no SQLite ignora maiúsculas e minúsculas apenas em caracteres ASCII
+func (r *VideoRepositorySQLite) ListVideos(ctx context.Context, query domainRepository.ListVideosQuery) (*domainRepository.VideoPage, error) {
+	query, err := query.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	var args sqliteArgs
+	where := sqliteVideoQueryConditions(query, &args)
+	filterArgs := len(args)
+
+	sortColumn := string(query.SortBy)
+	comparison, order := "<", "DESC"
+	if query.Direction == domainRepository.SortAsc {
+		comparison, order = ">", "ASC"
+	}
+
+	conditions := where
+	if query.Cursor != "" {
+		cursor, err := domainRepository.DecodeVideoCursor(query.Cursor, query.SortBy, query.Direction)
+		if err != nil {
+			return nil, err
+		}
+
+		var value any = cursor.Value
+		if query.SortBy != domainRepository.SortByTitle {
+			t, err := cursor.TimeValue()
+			if err != nil {
+				return nil, err
+			}
+			value = sqliteTime(t)
+		}
+
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s, %s)", sortColumn, comparison, args.add(value), args.add(cursor.ID)))
+	}
+
+	// Um item a mais indica se existe uma próxima página
+	sqlQuery := `SELECT ` + sqliteVideoColumns + `
+		FROM videos
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + sortColumn + ` ` + order + `, id ` + order + `
+		LIMIT ` + args.add(query.Limit+1)
+
+	videos, err := r.queryVideos(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domainRepository.VideoPage{Videos: videos}
+
+	if len(page.Videos) > query.Limit {
+		page.Videos = page.Videos[:query.Limit]
+		last := page.Videos[len(page.Videos)-1]
+		page.NextCursor = domainRepository.NewVideoCursor(last, query.SortBy, query.Direction).Encode()
+	}
+
+	if query.IncludeTotal {
+		countQuery := `SELECT COUNT(*) FROM videos WHERE ` + strings.Join(where, " AND ")
+
+		var total int64
+		if err := conn(ctx, r.db).QueryRowContext(ctx, countQuery, args[:filterArgs]...).Scan(&total); err != nil {
+			return nil, fmt.Errorf("erro ao contar vídeos: %w", err)
+		}
+		page.TotalCount = &total
+	}
+
+	return page, nil
+}
+
+// sqliteVideoQueryConditions monta as condições do WHERE correspondentes aos filtros da consulta
+func sqliteVideoQueryConditions(query domainRepository.ListVideosQuery, args *sqliteArgs) []string {
+	conditions := []string{"deleted_at IS NULL"}
+
+	if query.OwnerID != "" {
+		conditions = append(conditions, "owner_id = "+args.add(query.OwnerID))
+	}
+	if len(query.Statuses) > 0 {
+		conditions = append(conditions, "status IN ("+args.addList(query.Statuses)+")")
+	}
+	if len(query.UploadStatuses) > 0 {
+		conditions = append(conditions, "upload_status IN ("+args.addList(query.UploadStatuses)+")")
+	}
+	if len(query.Tags) > 0 {
+		conditions = append(conditions, fmt.Sprintf(sqliteTagsCondition, args.add(sqliteTags(query.Tags))))
+	}
+	if title := strings.TrimSpace(query.TitleContains); title != "" {
+		conditions = append(conditions, "title LIKE "+args.add("%"+escapeLike(title)+"%")+` ESCAPE '\'`)
+	}
+	if query.CreatedFrom != nil {
+		conditions = append(conditions, "created_at >= "+args.add(sqliteTime(*query.CreatedFrom)))
+	}
+	if query.CreatedUntil != nil {
+		conditions = append(conditions, "created_at < "+args.add(sqliteTime(*query.CreatedUntil)))
+	}
+	if query.UpdatedFrom != nil {
+		conditions = append(conditions, "updated_at >= "+args.add(sqliteTime(*query.UpdatedFrom)))
+	}
+	if query.UpdatedUntil != nil {
+		conditions = append(conditions, "updated_at < "+args.add(sqliteTime(*query.UpdatedUntil)))
+	}
+
+	return conditions
+}
+
+// Search busca vídeos pelo índice FTS5 videos_fts, que ignora maiúsculas e acentos
+// Todos os termos de SearchTerms precisam aparecer; a relevância é o bm25 com peso maior para o título
+func (r *VideoRepositorySQLite) Search(ctx context.Context, query string, filters domainRepository.VideoSearchFilters, cursor string) (*domainRepository.VideoSearchPage, error) {
+	terms := domainRepository.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, domainRepository.ErrEmptySearchQuery
+	}
+
+	searchCursor, err := domainRepository.DecodeVideoSearchCursor(cursor, query, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	filters = filters.Normalize()
+
+	// Cada termo entre aspas é tratado literalmente pelo FTS5; termos separados por espaço são combinados com AND
+	match := `"` + strings.Join(terms, `" "`) + `"`
+
+	var args sqliteArgs
+	matchArg := args.add(match)
+	start, end := args.add(domainRepository.HighlightStart), args.add(domainRepository.HighlightEnd)
+	conditions := sqliteVideoQueryConditions(filters.ListQuery(), &args)
+
+	// Um item a mais indica se existe uma próxima página
+	sqlQuery := `WITH matches AS (
+			SELECT rowid AS fts_rowid, bm25(videos_fts, 10.0, 4.0) AS score,
+				highlight(videos_fts, 0, ` + start + `, ` + end + `) AS title_highlight,
+				snippet(videos_fts, 1, ` + start + `, ` + end + `, '…', 35) AS description_snippet
+			FROM videos_fts
+			WHERE videos_fts MATCH ` + matchArg + `
+		)
+		SELECT ` + sqliteVideoColumns + `, -matches.score, matches.title_highlight, matches.description_snippet
+		FROM videos
+		JOIN matches ON matches.fts_rowid = videos.rowid
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY matches.score, id
+		LIMIT ` + args.add(filters.Limit+1) + ` OFFSET ` + args.add(searchCursor.Offset)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar vídeos: %w", err)
+	}
+	defer rows.Close()
+
+	page := &domainRepository.VideoSearchPage{}
+
+	for rows.Next() {
+		var result domainRepository.VideoSearchResult
+
+		result.Video, err = scanSQLiteVideo(rows, &result.Rank, &result.TitleHighlight, &result.DescriptionSnippet)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear vídeo: %w", err)
+		}
+
+		page.Results = append(page.Results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os resultados: %w", err)
+	}
+
+	if len(page.Results) > filters.Limit {
+		page.Results = page.Results[:filters.Limit]
+		page.NextCursor = domainRepository.NewVideoSearchCursor(query, filters, searchCursor.Offset+filters.Limit).Encode()
+	}
+
+	return page, nil
+}
+
+// FindCompletedByContentHash busca um vídeo concluído da conta de cliente com a mesma impressão digital
+// Vídeos originais têm preferência sobre duplicatas e, entre eles, o mais antigo é retornado
+func (r *VideoRepositorySQLite) FindCompletedByContentHash(ctx context.Context, ownerID, contentHash string) (*entity.Video, error) {
+	query := `SELECT ` + sqliteVideoColumns + `
+		FROM videos
+		WHERE owner_id = ?1 AND content_hash = ?2 AND status = ?3 AND deleted_at IS NULL
+		ORDER BY (duplicate_of = '') DESC, created_at ASC
+		LIMIT 1
+	`
+
+	video, err := scanSQLiteVideo(conn(ctx, r.db).QueryRowContext(ctx, query, ownerID, contentHash, entity.StatusCompleted))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrVideoNotFound
+		}
+		return nil, fmt.Errorf("erro ao buscar vídeo pela impressão digital: %w", err)
+	}
+
+	return video, nil
+}
+
+// Update grava todos os campos editáveis do vídeo se a versão no banco ainda for video.Version
+func (r *VideoRepositorySQLite) Update(ctx context.Context, video *entity.Video) error {
+	if err := video.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE videos
+		SET title = ?1, description = ?2, tags = ?3, file_path = ?4, content_hash = ?5, duplicate_of = ?6,
+			status = ?7, upload_status = ?8, hls_path = ?9, manifest_path = ?10, s3_url = ?11, s3_manifest_url = ?12,
+			error_message = ?13, progress = ?14, processing_stage = ?15, estimated_completion_at = ?16,
+			lease_owner = ?17, lease_expires_at = ?18, updated_at = ?19, version = version + 1,
+			` + statusTimestampsSet("?7", "?19") + `
+		WHERE id = ?20 AND version = ?21 AND deleted_at IS NULL
+	`
+
+	now := time.Now()
+
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		video.Title,
+		video.Description,
+		sqliteTags(video.Tags),
+		video.FilePath,
+		video.ContentHash,
+		video.DuplicateOfID,
+		video.Status,
+		video.UploadStatus,
+		video.HLSPath,
+		video.ManifestPath,
+		video.S3URL,
+		video.S3ManifestURL,
+		video.ErrorMessage,
+		min(max(video.Progress, 0), 100),
+		video.ProcessingStage,
+		sqliteNullTime(video.EstimatedCompletionAt),
+		video.LeaseOwner,
+		sqliteNullTime(video.LeaseExpiresAt),
+		sqliteTime(now),
+		video.ID,
+		video.Version,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar vídeo: %w", err)
+	}
+
+	if err := r.checkVersionedWrite(ctx, result, video.ID); err != nil {
+		return err
+	}
+
+	video.Version++
+	video.UpdatedAt = now
+
+	return nil
+}
+
+// checkVersionedWrite confere o resultado de uma gravação condicionada à versão do vídeo
+// Quando nenhuma linha é afetada, distingue vídeo inexistente de conflito de versão
+func (r *VideoRepositorySQLite) checkVersionedWrite(ctx context.Context, result sql.Result, id string) error {
+	err := checkRowsAffected(result)
+	if !errors.Is(err, ErrVideoNotFound) {
+		return err
+	}
+
+	var exists bool
+	err = conn(ctx, r.db).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM videos WHERE id = ?1 AND deleted_at IS NULL)`, id).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("erro ao verificar existência do vídeo: %w", err)
+	}
+	if !exists {
+		return ErrVideoNotFound
+	}
+	return domainRepository.ErrConcurrentModification
+}
+
+// sqliteUpdateStatusSet é a cláusula SET de UpdateStatus e UpdateStatusMany: ?1 é o status, ?2 a mensagem de erro
+// e ?3 a data de atualização
+var sqliteUpdateStatusSet = `
+	SET status = ?1, error_message = ?2, updated_at = ?3, version = version + 1,
+		progress = CASE ?1 WHEN 'processing' THEN 0 WHEN 'completed' THEN 100 ELSE progress END,
+		processing_stage = CASE ?1 WHEN 'processing' THEN 'transcoding' ELSE '' END,
+		estimated_completion_at = NULL,
+		lease_owner = CASE ?1 WHEN 'processing' THEN lease_owner ELSE '' END,
+		lease_expires_at = CASE ?1 WHEN 'processing' THEN lease_expires_at END,
+		` + statusTimestampsSet("?1", "?3")
+
+// UpdateStatus atualiza o status de um vídeo e a mensagem de erro (quando aplicável)
+// O progresso e a concessão acompanham a transição de status, como na implementação PostgreSQL
+func (r *VideoRepositorySQLite) UpdateStatus(ctx context.Context, id string, expectedVersion int64, status string, errorMessage string) error {
+	query := `UPDATE videos ` + sqliteUpdateStatusSet + ` WHERE id = ?4 AND version = ?5 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, status, errorMessage, sqliteTime(time.Now()), id, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar status do vídeo: %w", err)
+	}
+
+	return r.checkVersionedWrite(ctx, result, id)
+}
+
+// UpdateProgress atualiza o progresso, a etapa e a estimativa de término da conversão
+// Assim como na implementação PostgreSQL, atualiza updated_at sem alterar a versão
+func (r *VideoRepositorySQLite) UpdateProgress(ctx context.Context, id string, progress int, stage string, estimatedCompletionAt *time.Time) error {
+	query := `
+		UPDATE videos
+		SET progress = ?1, processing_stage = ?2, estimated_completion_at = ?3, updated_at = ?4
+		WHERE id = ?5 AND deleted_at IS NULL
+	`
+
+	progress = min(max(progress, 0), 100)
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, progress, stage, sqliteNullTime(estimatedCompletionAt), sqliteTime(time.Now()), id)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar progresso do vídeo: %w", err)
+	}
+
+	return checkRowsAffected(result)
+}
+
+// UpdateHLSPath atualiza os caminhos HLS de um vídeo
+func (r *VideoRepositorySQLite) UpdateHLSPath(ctx context.Context, id string, expectedVersion int64, hlsPath, manifestPath string) error {
+	query := `
+		UPDATE videos
+		SET hls_path = ?1, manifest_path = ?2, updated_at = ?3, version = version + 1
+		WHERE id = ?4 AND version = ?5 AND deleted_at IS NULL
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, hlsPath, manifestPath, sqliteTime(time.Now()), id, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar caminhos HLS do vídeo: %w", err)
+	}
+
+	return r.checkVersionedWrite(ctx, result, id)
+}
+
+// UpdateS3Status atualiza o status de upload para S3 de um vídeo
+func (r *VideoRepositorySQLite) UpdateS3Status(ctx context.Context, id string, expectedVersion int64, uploadStatus string) error {
+	query := `
+		UPDATE videos
+		SET upload_status = ?1, updated_at = ?2, version = version + 1
+		WHERE id = ?3 AND version = ?4 AND deleted_at IS NULL
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, uploadStatus, sqliteTime(time.Now()), id, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar status de upload do vídeo: %w", err)
+	}
+
+	return r.checkVersionedWrite(ctx, result, id)
+}
+
+// UpdateS3URLs atualiza as URLs do S3 de um vídeo
+func (r *VideoRepositorySQLite) UpdateS3URLs(ctx context.Context, id string, expectedVersion int64, s3URL, s3ManifestURL string) error {
+	query := `
+		UPDATE videos
+		SET s3_url = ?1, s3_manifest_url = ?2, updated_at = ?3, version = version + 1
+		WHERE id = ?4 AND version = ?5 AND deleted_at IS NULL
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, s3URL, s3ManifestURL, sqliteTime(time.Now()), id, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar URLs do S3 do vídeo: %w", err)
+	}
+
+	return r.checkVersionedWrite(ctx, result, id)
+}
+
+// UpdateS3Keys atualiza as chaves do S3 de um vídeo
+func (r *VideoRepositorySQLite) UpdateS3Keys(ctx context.Context, id string, expectedVersion int64, segmentKey string, manifestKey string) error {
+	query := `
+		UPDATE videos
+		SET segment_key = ?1, manifest_key = ?2, updated_at = ?3, version = version + 1
+		WHERE id = ?4 AND version = ?5 AND deleted_at IS NULL
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, segmentKey, manifestKey, sqliteTime(time.Now()), id, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar chaves do S3 do vídeo: %w", err)
+	}
+
+	return r.checkVersionedWrite(ctx, result, id)
+}
+
+// ClaimNextPending reivindica o vídeo disponível mais antigo para o worker
+// O SQLite serializa as escritas, então o UPDATE com subconsulta já é atômico sem FOR UPDATE SKIP LOCKED
+func (r *VideoRepositorySQLite) ClaimNextPending(ctx context.Context, workerID string, leaseDuration time.Duration) (*entity.Video, error) {
+	query := `
+		UPDATE videos
+		SET status = ?1, lease_owner = ?2, lease_expires_at = ?3, progress = 0, processing_stage = ?4,
+			estimated_completion_at = NULL, error_message = '', updated_at = ?5, version = version + 1,
+			processing_started_at = ?5, completed_at = NULL
+		WHERE id = (
+			SELECT id
+			FROM videos
+			WHERE deleted_at IS NULL
+				AND (status = ?6 OR (status = ?1 AND lease_expires_at < ?5))
+			ORDER BY created_at ASC, id ASC
+			LIMIT 1
+		)
+		RETURNING ` + sqliteVideoColumns
+
+	now := time.Now()
+
+	video, err := scanSQLiteVideo(conn(ctx, r.db).QueryRowContext(
+		ctx,
+		query,
+		entity.StatusProcessing,
+		workerID,
+		sqliteTime(now.Add(leaseDuration)),
+		entity.ProcessingStageTranscoding,
+		sqliteTime(now),
+		entity.StatusPending,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domainRepository.ErrNoPendingVideo
+		}
+		return nil, fmt.Errorf("erro ao reivindicar vídeo pendente: %w", err)
+	}
+
+	return video, nil
+}
+
+// RenewLease estende a concessão do worker sobre um vídeo em processamento
+func (r *VideoRepositorySQLite) RenewLease(ctx context.Context, id, workerID string, leaseDuration time.Duration) error {
+	query := `
+		UPDATE videos
+		SET lease_expires_at = ?1
+		WHERE id = ?2 AND lease_owner = ?3 AND status = ?4 AND deleted_at IS NULL
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, sqliteTime(time.Now().Add(leaseDuration)), id, workerID, entity.StatusProcessing)
+	if err != nil {
+		return fmt.Errorf("erro ao renovar concessão do vídeo: %w", err)
+	}
+
+	if err := checkRowsAffected(result); err != nil {
+		if errors.Is(err, ErrVideoNotFound) {
+			return domainRepository.ErrLeaseNotHeld
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ReleaseLease encerra a concessão do worker, devolvendo à fila um vídeo que ainda não terminou
+func (r *VideoRepositorySQLite) ReleaseLease(ctx context.Context, id, workerID string) error {
+	query := `
+		UPDATE videos
+		SET lease_owner = '', lease_expires_at = NULL,
+			status = CASE WHEN status = ?1 THEN ?2 ELSE status END,
+			processing_stage = CASE WHEN status = ?1 THEN '' ELSE processing_stage END,
+			estimated_completion_at = NULL,
+			updated_at = ?3, version = version + 1
+		WHERE id = ?4 AND lease_owner = ?5 AND lease_owner <> '' AND deleted_at IS NULL
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, entity.StatusProcessing, entity.StatusPending, sqliteTime(time.Now()), id, workerID)
+	if err != nil {
+		return fmt.Errorf("erro ao liberar concessão do vídeo: %w", err)
+	}
+
+	if err := checkRowsAffected(result); err != nil {
+		if errors.Is(err, ErrVideoNotFound) {
+			return domainRepository.ErrLeaseNotHeld
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ListStaleProcessing lista os vídeos em processamento cuja concessão (ou, sem ela, a última atualização)
+// é anterior a staleBefore
+func (r *VideoRepositorySQLite) ListStaleProcessing(ctx context.Context, staleBefore time.Time, limit int) ([]*entity.Video, error) {
+	if limit < 1 {
+		limit = 10
+	}
+
+	query := `SELECT ` + sqliteVideoColumns + `
+		FROM videos
+		WHERE deleted_at IS NULL
+			AND status = ?1
+			AND COALESCE(lease_expires_at, updated_at) < ?2
+		ORDER BY COALESCE(lease_expires_at, updated_at) ASC, id ASC
+		LIMIT ?3
+	`
+
+	return r.queryVideos(ctx, query, entity.StatusProcessing, sqliteTime(staleBefore), limit)
+}
+
+// sqliteIDs converte a lista de IDs no array JSON lido com json_each
+func sqliteIDs(ids []string) string {
+	data, _ := json.Marshal(append([]string{}, ids...))
+	return string(data)
+}
+
+// queryIDs executa uma consulta que retorna uma coluna de IDs
+func (r *VideoRepositorySQLite) queryIDs(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// FindManyByID busca os vídeos pelos IDs em uma única consulta
+func (r *VideoRepositorySQLite) FindManyByID(ctx context.Context, ids []string) ([]*entity.Video, error) {
+	query := `SELECT ` + sqliteVideoColumns + `
+		FROM videos
+		WHERE id IN (SELECT value FROM json_each(?1)) AND deleted_at IS NULL
+	`
+
+	videos, err := r.queryVideos(ctx, query, sqliteIDs(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	return orderByIDs(videos, ids), nil
+}
+
+// CreateMany insere os vídeos com INSERT de várias linhas, em lotes de sqliteVideoInsertRows
+// Assim como na implementação PostgreSQL, os IDs ausentes do RETURNING são os já existentes
+func (r *VideoRepositorySQLite) CreateMany(ctx context.Context, videos []*entity.Video) ([]domainRepository.BulkResult, error) {
+	results, candidates := prepareBulkCreate(videos)
+	inserted := make(map[string]bool, len(candidates))
+
+	err := runInTx(ctx, r.db, func(ctx context.Context) error {
+		for batch := range slices.Chunk(candidates, sqliteVideoInsertRows) {
+			rows := make([]string, len(batch))
+			args := make([]any, 0, len(batch)*sqliteVideoInsertColumnCount)
+			for i, video := range batch {
+				rows[i] = sqliteVideoInsertRow
+				args = append(args, sqliteVideoInsertArgs(video)...)
+			}
+
+			query := `INSERT INTO videos (` + sqliteVideoInsertColumns + `) VALUES ` + strings.Join(rows, ", ") + `
+				ON CONFLICT (id) DO NOTHING
+				RETURNING id`
+
+			ids, err := r.queryIDs(ctx, query, args...)
+			if err != nil {
+				return fmt.Errorf("erro ao criar vídeos: %w", err)
+			}
+			for _, id := range ids {
+				inserted[id] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	markBulkResults(results, inserted, domainRepository.ErrVideoAlreadyExists)
+	return results, nil
+}
+
+// UpdateStatusMany atualiza o status de todos os vídeos em um único UPDATE
+func (r *VideoRepositorySQLite) UpdateStatusMany(ctx context.Context, ids []string, status string) ([]domainRepository.BulkResult, error) {
+	query := `UPDATE videos ` + sqliteUpdateStatusSet + `
+		WHERE id IN (SELECT value FROM json_each(?4)) AND deleted_at IS NULL
+		RETURNING id`
+
+	updated, err := r.queryIDs(ctx, query, status, "", sqliteTime(time.Now()), sqliteIDs(ids))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao atualizar status dos vídeos: %w", err)
+	}
+
+	applied := make(map[string]bool, len(updated))
+	for _, id := range updated {
+		applied[id] = true
+	}
+
+	results := newBulkResults(ids)
+	markBulkResults(results, applied, domainRepository.ErrVideoNotFound)
+	return results, nil
+}
+
+// RequeueFailed devolve para a fila os vídeos com falha que atendem ao filtro em um único UPDATE
+func (r *VideoRepositorySQLite) RequeueFailed(ctx context.Context, filter domainRepository.RequeueFailedFilter) ([]domainRepository.BulkResult, error) {
+	var failedBefore any
+	if !filter.FailedBefore.IsZero() {
+		failedBefore = sqliteTime(filter.FailedBefore)
+	}
+
+	// LIMIT -1 não limita
+	limit := -1
+	if filter.Limit > 0 && len(filter.VideoIDs) == 0 {
+		limit = filter.Limit
+	}
+
+	query := `
+		UPDATE videos
+		SET status = ?1, error_message = '', progress = 0, processing_stage = '', estimated_completion_at = NULL,
+			lease_owner = '', lease_expires_at = NULL, updated_at = ?2, version = version + 1
+		WHERE id IN (
+			SELECT id
+			FROM videos
+			WHERE deleted_at IS NULL
+				AND status = ?3
+				AND (?4 = '' OR owner_id = ?4)
+				AND (?5 = 0 OR id IN (SELECT value FROM json_each(?6)))
+				AND (?7 IS NULL OR updated_at < ?7)
+			ORDER BY updated_at ASC, id ASC
+			LIMIT ?8
+		)
+		RETURNING id
+	`
+
+	var results []domainRepository.BulkResult
+
+	err := runInTx(ctx, r.db, func(ctx context.Context) error {
+		requeued, err := r.queryIDs(
+			ctx,
+			query,
+			entity.StatusPending,
+			sqliteTime(time.Now()),
+			entity.StatusError,
+			filter.OwnerID,
+			len(filter.VideoIDs) > 0,
+			sqliteIDs(filter.VideoIDs),
+			failedBefore,
+			limit,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao reenfileirar vídeos com falha: %w", err)
+		}
+
+		results, err = requeueResults(ctx, r, filter, requeued)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Restore desfaz a exclusão do vídeo, se a remoção definitiva ainda não começou
+func (r *VideoRepositorySQLite) Restore(ctx context.Context, id string) error {
+	query := `
+		UPDATE videos
+		SET deleted_at = NULL, updated_at = ?1, version = version + 1
+		WHERE id = ?2 AND deleted_at IS NOT NULL AND purge_started_at IS NULL
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, sqliteTime(time.Now()), id)
+	if err != nil {
+		return fmt.Errorf("erro ao restaurar vídeo: %w", err)
+	}
+
+	return checkRowsAffected(result)
+}
+
+// ListPurgeCandidates lista os vídeos que passaram da retenção, do mais antigo para o mais recente
+func (r *VideoRepositorySQLite) ListPurgeCandidates(ctx context.Context, criteria domainRepository.PurgeCriteria) ([]domainRepository.PurgeCandidate, error) {
+	deletedBefore, failedBefore := purgeCriteriaArgs(criteria)
+
+	// LIMIT -1 não limita
+	limit := -1
+	if criteria.Limit > 0 {
+		limit = criteria.Limit
+	}
+
+	query := `SELECT ` + sqliteVideoColumns + `, deleted_at, segment_key, manifest_key
+		FROM videos
+		WHERE ` + purgeCondition("?1", "?2", "?3") + `
+		ORDER BY COALESCE(deleted_at, updated_at) ASC, id ASC
+		LIMIT ?4
+	`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, sqliteNullTime(deletedBefore), sqliteNullTime(failedBefore), sqliteTime(time.Now()), limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar vídeos para remoção definitiva: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []domainRepository.PurgeCandidate
+
+	for rows.Next() {
+		var deletedAt sql.NullInt64
+		var segmentKey, manifestKey string
+		video, err := scanSQLiteVideo(rows, &deletedAt, &segmentKey, &manifestKey)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear vídeo: %w", err)
+		}
+
+		var deletedAtPtr *time.Time
+		if deletedAt.Valid {
+			t := timeFromSQLite(deletedAt.Int64)
+			deletedAtPtr = &t
+		}
+		candidates = append(candidates, newPurgeCandidate(video, deletedAtPtr, segmentKey, manifestKey))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os resultados: %w", err)
+	}
+
+	return candidates, nil
+}
+
+// BeginPurge marca o vídeo como em remoção, excluindo-o se ainda não estiver excluído
+func (r *VideoRepositorySQLite) BeginPurge(ctx context.Context, id string, criteria domainRepository.PurgeCriteria) error {
+	deletedBefore, failedBefore := purgeCriteriaArgs(criteria)
+
+	query := `
+		UPDATE videos
+		SET deleted_at = COALESCE(deleted_at, ?1), purge_started_at = COALESCE(purge_started_at, ?1)
+		WHERE id = ?2 AND ` + purgeCondition("?3", "?4", "?1") + `
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, sqliteTime(time.Now()), id, sqliteNullTime(deletedBefore), sqliteNullTime(failedBefore))
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar remoção definitiva do vídeo: %w", err)
+	}
+
+	err = checkRowsAffected(result)
+	if errors.Is(err, ErrVideoNotFound) {
+		return domainRepository.ErrVideoNotPurgeable
+	}
+	return err
+}
+
+// Purge apaga o vídeo
+func (r *VideoRepositorySQLite) Purge(ctx context.Context, id string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM videos WHERE id = ?1 AND purge_started_at IS NOT NULL`, id)
+	if err != nil {
+		return fmt.Errorf("erro ao remover vídeo definitivamente: %w", err)
+	}
+
+	err = checkRowsAffected(result)
+	if errors.Is(err, ErrVideoNotFound) {
+		return domainRepository.ErrVideoNotPurgeable
+	}
+	return err
+}
+
+// sqliteMicrosPerDay é a duração de um dia na unidade das datas gravadas no SQLite
+const sqliteMicrosPerDay = int64(24 * time.Hour / time.Microsecond)
+
+// Stats calcula as estatísticas com consultas agregadas
+// O SQLite não tem percentile_cont, então as durações são lidas e os percentis calculados em Go
+func (r *VideoRepositorySQLite) Stats(ctx context.Context, filter domainRepository.VideoStatsFilter) (*domainRepository.VideoStats, error) {
+	var args sqliteArgs
+	where := strings.Join(sqliteVideoQueryConditions(filter.ListQuery(), &args), " AND ")
+
+	db := conn(ctx, r.db)
+	stats := domainRepository.NewVideoStats()
+
+	if err := countByStatus(ctx, db, where, args, stats); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT completed_at - processing_started_at
+		FROM videos
+		WHERE ` + where + ` AND status = '` + entity.StatusCompleted + `'
+			AND processing_started_at IS NOT NULL AND completed_at IS NOT NULL`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao calcular tempo de processamento: %w", err)
+	}
+	defer rows.Close()
+
+	var durations []time.Duration
+	for rows.Next() {
+		var micros int64
+		if err := rows.Scan(&micros); err != nil {
+			return nil, fmt.Errorf("erro ao escanear tempo de processamento: %w", err)
+		}
+		durations = append(durations, time.Duration(micros)*time.Microsecond)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar tempo de processamento: %w", err)
+	}
+	stats.ProcessingTime = domainRepository.NewProcessingTimeStats(durations)
+
+	if err := countFailures(ctx, db, where, args, stats); err != nil {
+		return nil, err
+	}
+
+	query = `SELECT created_at / ` + strconv.FormatInt(sqliteMicrosPerDay, 10) + ` AS day, COUNT(*),
+			SUM(status = '` + entity.StatusCompleted + `'), SUM(status = '` + entity.StatusError + `')
+		FROM videos
+		WHERE ` + where + `
+		GROUP BY day
+		ORDER BY day`
+
+	daily, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao calcular totais diários: %w", err)
+	}
+	defer daily.Close()
+
+	for daily.Next() {
+		var day int64
+		var totals domainRepository.DailyVideoStats
+		if err := daily.Scan(&day, &totals.Created, &totals.Completed, &totals.Failed); err != nil {
+			return nil, fmt.Errorf("erro ao escanear totais diários: %w", err)
+		}
+		totals.Date = timeFromSQLite(day * sqliteMicrosPerDay)
+		stats.Daily = append(stats.Daily, totals)
+	}
+
+	if err := daily.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar totais diários: %w", err)
+	}
+
+	return stats, nil
+}
+
+// Delete remove um vídeo do repositório (soft delete)
+func (r *VideoRepositorySQLite) Delete(ctx context.Context, id string, expectedVersion int64) error {
+	query := `
+		UPDATE videos
+		SET deleted_at = ?1, version = version + 1
+		WHERE id = ?2 AND version = ?3 AND deleted_at IS NULL
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, sqliteTime(time.Now()), id, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("erro ao excluir vídeo: %w", err)
+	}
+
+	return r.checkVersionedWrite(ctx, result, id)
+}
+
+// Ensure VideoRepositorySQLite implements VideoRepository
+var _ domainRepository.VideoRepository = (*VideoRepositorySQLite)(nil)
@@ -12,9 +12,13 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
 	"github.com/devfullcycle/golangtechweek/internal/infra/database"
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
@@ -22,6 +26,9 @@ import (
 	"github.com/stretchr/testify/suite"
 )
 
+// testOwnerID identifica a conta de cliente usada nos vídeos de teste
+const testOwnerID = "owner-test"
+
 type VideoRepositoryTestSuite struct {
 	suite.Suite
 	db         *sql.DB
@@ -30,18 +37,8 @@ type VideoRepositoryTestSuite struct {
 }
 
 func (suite *VideoRepositoryTestSuite) SetupSuite() {
-	// Configuração do banco de dados de teste
-	dbConfig := database.Config{
-		Host:     getEnv("DB_HOST", "postgres"),
-		Port:     5432,
-		User:     getEnv("DB_USER", "postgres"),
-		Password: getEnv("DB_PASSWORD", "postgres"),
-		DBName:   getEnv("DB_NAME", "conversorgo"),
-		SSLMode:  getEnv("DB_SSL_MODE", "disable"),
-	}
-
 	var err error
-	suite.db, err = database.NewConnection(dbConfig)
+	suite.db, err = database.NewConnection(testDBConfig())
 	if err != nil {
 		suite.T().Fatalf("Erro ao conectar ao banco de dados: %v", err)
 	}
@@ -69,7 +66,7 @@ func (suite *VideoRepositoryTestSuite) TearDownSuite() {
 }
 
 func (suite *VideoRepositoryTestSuite) TestCreate() {
-	video := entity.NewVideo("Teste de Vídeo", "/path/to/video.mp4")
+	video := entity.NewVideo(testOwnerID, "Teste de Vídeo", "", "/path/to/video.mp4")
 	err := suite.repository.Create(suite.ctx, video)
 	assert.NoError(suite.T(), err)
 
@@ -82,7 +79,7 @@ func (suite *VideoRepositoryTestSuite) TestCreate() {
 
 func (suite *VideoRepositoryTestSuite) TestFindByID() {
 	// Criar um vídeo para o teste
-	video := entity.NewVideo("Teste de Busca", "/path/to/search.mp4")
+	video := entity.NewVideo(testOwnerID, "Teste de Busca", "", "/path/to/search.mp4")
 	err := suite.repository.Create(suite.ctx, video)
 	assert.NoError(suite.T(), err)
 
@@ -94,6 +91,7 @@ func (suite *VideoRepositoryTestSuite) TestFindByID() {
 	assert.Equal(suite.T(), video.Title, foundVideo.Title)
 	assert.Equal(suite.T(), video.FilePath, foundVideo.FilePath)
 	assert.Equal(suite.T(), video.Status, foundVideo.Status)
+	assert.Equal(suite.T(), video.OwnerID, foundVideo.OwnerID)
 }
 
 func (suite *VideoRepositoryTestSuite) TestFindByIDNotFound() {
@@ -110,30 +108,267 @@ func (suite *VideoRepositoryTestSuite) TestList() {
 
 	// Criar vários vídeos para o teste
 	for i := 1; i <= 15; i++ {
-		video := entity.NewVideo(fmt.Sprintf("Vídeo %d", i), fmt.Sprintf("/path/to/video%d.mp4", i))
+		video := entity.NewVideo(testOwnerID, fmt.Sprintf("Vídeo %d", i), "", fmt.Sprintf("/path/to/video%d.mp4", i))
 		err := suite.repository.Create(suite.ctx, video)
 		assert.NoError(suite.T(), err)
 	}
 
 	// Testar a primeira página
-	videos, err := suite.repository.List(suite.ctx, 1, 10)
+	videos, err := suite.repository.List(suite.ctx, domainRepository.VideoFilter{}, 1, 10)
 	assert.NoError(suite.T(), err)
 	assert.Len(suite.T(), videos, 10)
 
 	// Testar a segunda página
-	videos, err = suite.repository.List(suite.ctx, 2, 10)
+	videos, err = suite.repository.List(suite.ctx, domainRepository.VideoFilter{}, 2, 10)
 	assert.NoError(suite.T(), err)
 	assert.Len(suite.T(), videos, 5)
 }
 
+func (suite *VideoRepositoryTestSuite) TestListWithFilter() {
+	// Limpar a tabela para garantir um estado conhecido
+	_, err := suite.db.Exec("DELETE FROM videos")
+	assert.NoError(suite.T(), err)
+
+	// Criar vídeos de donos e tags diferentes
+	videoA := entity.NewVideo("owner-a", "Vídeo A", "Descrição A", "/path/to/a.mp4", "golang", "backend")
+	videoB := entity.NewVideo("owner-a", "Vídeo B", "", "/path/to/b.mp4", "golang")
+	videoC := entity.NewVideo("owner-b", "Vídeo C", "", "/path/to/c.mp4", "golang", "backend")
+	for _, video := range []*entity.Video{videoA, videoB, videoC} {
+		err := suite.repository.Create(suite.ctx, video)
+		assert.NoError(suite.T(), err)
+	}
+
+	// Filtrar por dono
+	videos, err := suite.repository.List(suite.ctx, domainRepository.VideoFilter{OwnerID: "owner-a"}, 1, 10)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), videos, 2)
+
+	// Filtrar por dono e tags
+	videos, err = suite.repository.List(suite.ctx, domainRepository.VideoFilter{OwnerID: "owner-a", Tags: []string{"backend"}}, 1, 10)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), videos, 1)
+	assert.Equal(suite.T(), videoA.ID, videos[0].ID)
+	assert.Equal(suite.T(), "Descrição A", videos[0].Description)
+	assert.Equal(suite.T(), []string{"golang", "backend"}, videos[0].Tags)
+
+	// Filtrar apenas por tags
+	videos, err = suite.repository.List(suite.ctx, domainRepository.VideoFilter{Tags: []string{"golang", "backend"}}, 1, 10)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), videos, 2)
+}
+
+func (suite *VideoRepositoryTestSuite) TestListVideosCursorPagination() {
+	// Conta de cliente exclusiva para isolar os vídeos deste teste
+	ownerID := "owner-cursor-" + uuid.NewString()
+	base := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+
+	var created []*entity.Video
+	for i := 0; i < 5; i++ {
+		video := entity.NewVideo(ownerID, fmt.Sprintf("Aula %d_%%", i), "", fmt.Sprintf("/path/to/cursor-%d.mp4", i))
+		video.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		if i == 4 {
+			video.Status = entity.StatusCompleted
+		}
+		err := suite.repository.Create(suite.ctx, video)
+		assert.NoError(suite.T(), err)
+		created = append(created, video)
+	}
+
+	query := domainRepository.ListVideosQuery{OwnerID: ownerID, Limit: 2, IncludeTotal: true}
+
+	// Percorrer todas as páginas, do mais recente para o mais antigo
+	var ids []string
+	for pages := 0; pages < 5; pages++ {
+		page, err := suite.repository.ListVideos(suite.ctx, query)
+		assert.NoError(suite.T(), err)
+		if assert.NotNil(suite.T(), page.TotalCount) {
+			assert.Equal(suite.T(), int64(5), *page.TotalCount)
+		}
+
+		for _, video := range page.Videos {
+			ids = append(ids, video.ID)
+		}
+
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	assert.Equal(suite.T(), []string{created[4].ID, created[3].ID, created[2].ID, created[1].ID, created[0].ID}, ids)
+
+	// Filtros por status e por trecho do título (curingas são tratados literalmente)
+	page, err := suite.repository.ListVideos(suite.ctx, domainRepository.ListVideosQuery{
+		OwnerID:   ownerID,
+		Statuses:  []string{entity.StatusPending},
+		SortBy:    domainRepository.SortByTitle,
+		Direction: domainRepository.SortAsc,
+	})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), page.Videos, 4)
+	assert.Equal(suite.T(), created[0].ID, page.Videos[0].ID)
+	assert.Nil(suite.T(), page.TotalCount)
+
+	page, err = suite.repository.ListVideos(suite.ctx, domainRepository.ListVideosQuery{OwnerID: ownerID, TitleContains: "aula 3_%"})
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), page.Videos, 1) {
+		assert.Equal(suite.T(), created[3].ID, page.Videos[0].ID)
+	}
+
+	// Um cursor gerado para outra ordenação é recusado
+	_, err = suite.repository.ListVideos(suite.ctx, domainRepository.ListVideosQuery{
+		OwnerID: ownerID,
+		SortBy:  domainRepository.SortByTitle,
+		Cursor:  domainRepository.NewVideoCursor(created[0], domainRepository.SortByCreatedAt, domainRepository.SortDesc).Encode(),
+	})
+	assert.ErrorIs(suite.T(), err, domainRepository.ErrInvalidCursor)
+}
+
+func (suite *VideoRepositoryTestSuite) TestCreateWithoutOwner() {
+	video := entity.NewVideo("", "Sem Dono", "", "/path/to/no-owner.mp4")
+	err := suite.repository.Create(suite.ctx, video)
+	assert.ErrorIs(suite.T(), err, entity.ErrOwnerIDRequired)
+}
+
+func (suite *VideoRepositoryTestSuite) TestUpdateOptimisticConcurrency() {
+	video := entity.NewVideo(testOwnerID, "Teste de Versão", "", "/path/to/version.mp4")
+	err := suite.repository.Create(suite.ctx, video)
+	assert.NoError(suite.T(), err)
+
+	// Dois processos leem a mesma versão
+	first, err := suite.repository.FindByID(suite.ctx, video.ID)
+	assert.NoError(suite.T(), err)
+	second, err := suite.repository.FindByID(suite.ctx, video.ID)
+	assert.NoError(suite.T(), err)
+
+	first.SetDescription("primeira gravação")
+	err = suite.repository.Update(suite.ctx, first)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), first.Version)
+
+	// A segunda gravação, baseada na leitura antiga, é recusada
+	second.SetDescription("segunda gravação")
+	err = suite.repository.Update(suite.ctx, second)
+	assert.ErrorIs(suite.T(), err, domainRepository.ErrConcurrentModification)
+
+	// As atualizações específicas também invalidam leituras anteriores
+	err = suite.repository.UpdateS3Status(suite.ctx, video.ID, first.Version, entity.UploadStatusPendingS3)
+	assert.NoError(suite.T(), err)
+	first.SetDescription("depois do upload")
+	err = suite.repository.Update(suite.ctx, first)
+	assert.ErrorIs(suite.T(), err, domainRepository.ErrConcurrentModification)
+
+	// RetryOnConflict relê o vídeo e reaplica a alteração
+	updated, err := domainRepository.RetryOnConflict(suite.ctx, suite.repository, video.ID, 0, func(v *entity.Video) error {
+		v.SetDescription("com nova leitura")
+		return nil
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(4), updated.Version)
+
+	found, err := suite.repository.FindByID(suite.ctx, video.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "com nova leitura", found.Description)
+	assert.Equal(suite.T(), entity.UploadStatusPendingS3, found.UploadStatus)
+
+	// Vídeos excluídos não são confundidos com conflito
+	err = suite.repository.Delete(suite.ctx, video.ID, found.Version)
+	assert.NoError(suite.T(), err)
+	err = suite.repository.Update(suite.ctx, found)
+	assert.ErrorIs(suite.T(), err, ErrVideoNotFound)
+}
+
+func (suite *VideoRepositoryTestSuite) TestClaimNextPendingWithLease() {
+	// Vídeo mais antigo que os demais, para ser o primeiro da fila
+	video := entity.NewVideo(testOwnerID, "Teste de Concessão", "", "/path/to/lease.mp4")
+	video.CreatedAt = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	err := suite.repository.Create(suite.ctx, video)
+	assert.NoError(suite.T(), err)
+
+	claimed, err := suite.repository.ClaimNextPending(suite.ctx, "worker-a", time.Minute)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), video.ID, claimed.ID)
+	assert.Equal(suite.T(), entity.StatusProcessing, claimed.Status)
+	assert.Equal(suite.T(), "worker-a", claimed.LeaseOwner)
+	assert.True(suite.T(), claimed.HasActiveLease(time.Now()))
+
+	// Apenas o dono da concessão pode renová-la ou liberá-la
+	assert.NoError(suite.T(), suite.repository.RenewLease(suite.ctx, video.ID, "worker-a", time.Minute))
+	assert.ErrorIs(suite.T(), suite.repository.RenewLease(suite.ctx, video.ID, "worker-b", time.Minute), domainRepository.ErrLeaseNotHeld)
+	assert.ErrorIs(suite.T(), suite.repository.ReleaseLease(suite.ctx, video.ID, "worker-b"), domainRepository.ErrLeaseNotHeld)
+
+	// Uma concessão expirada pode ser reivindicada por outro worker
+	_, err = suite.db.Exec("UPDATE videos SET lease_expires_at = $1 WHERE id = $2", time.Now().Add(-time.Minute), video.ID)
+	assert.NoError(suite.T(), err)
+
+	reclaimed, err := suite.repository.ClaimNextPending(suite.ctx, "worker-b", time.Minute)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), video.ID, reclaimed.ID)
+	assert.Equal(suite.T(), "worker-b", reclaimed.LeaseOwner)
+	assert.ErrorIs(suite.T(), suite.repository.RenewLease(suite.ctx, video.ID, "worker-a", time.Minute), domainRepository.ErrLeaseNotHeld)
+
+	// Liberar a concessão de um vídeo inacabado o devolve para a fila
+	assert.NoError(suite.T(), suite.repository.ReleaseLease(suite.ctx, video.ID, "worker-b"))
+	found, err := suite.repository.FindByID(suite.ctx, video.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), entity.StatusPending, found.Status)
+	assert.Empty(suite.T(), found.LeaseOwner)
+	assert.Nil(suite.T(), found.LeaseExpiresAt)
+
+	// Concluir o vídeo encerra a concessão
+	claimed, err = suite.repository.ClaimNextPending(suite.ctx, "worker-c", time.Minute)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.repository.UpdateStatus(suite.ctx, video.ID, claimed.Version, entity.StatusCompleted, ""))
+	found, err = suite.repository.FindByID(suite.ctx, video.ID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), found.LeaseOwner)
+}
+
+func (suite *VideoRepositoryTestSuite) TestClaimNextPendingConcurrent() {
+	for i := 0; i < 10; i++ {
+		video := entity.NewVideo(testOwnerID, fmt.Sprintf("Fila %d", i), "", fmt.Sprintf("/path/to/queue-%d.mp4", i))
+		err := suite.repository.Create(suite.ctx, video)
+		assert.NoError(suite.T(), err)
+	}
+
+	// Workers concorrentes nunca recebem o mesmo vídeo
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	seen := make(map[string]string)
+
+	for w := 0; w < 5; w++ {
+		wg.Add(1)
+		go func(workerID string) {
+			defer wg.Done()
+			for i := 0; i < 2; i++ {
+				video, err := suite.repository.ClaimNextPending(suite.ctx, workerID, time.Minute)
+				if err != nil {
+					assert.ErrorIs(suite.T(), err, domainRepository.ErrNoPendingVideo)
+					return
+				}
+
+				mu.Lock()
+				previous, duplicated := seen[video.ID]
+				seen[video.ID] = workerID
+				mu.Unlock()
+
+				assert.False(suite.T(), duplicated, "vídeo %s reivindicado por %s e %s", video.ID, previous, workerID)
+			}
+		}(fmt.Sprintf("worker-%d", w))
+	}
+
+	wg.Wait()
+	assert.Len(suite.T(), seen, 10)
+}
+
 func (suite *VideoRepositoryTestSuite) TestUpdateStatus() {
 	// Criar um vídeo para o teste
-	video := entity.NewVideo("Teste de Atualização de Status", "/path/to/status.mp4")
+	video := entity.NewVideo(testOwnerID, "Teste de Atualização de Status", "", "/path/to/status.mp4")
 	err := suite.repository.Create(suite.ctx, video)
 	assert.NoError(suite.T(), err)
 
 	// Atualizar o status
-	err = suite.repository.UpdateStatus(suite.ctx, video.ID, entity.StatusProcessing, "")
+	err = suite.repository.UpdateStatus(suite.ctx, video.ID, video.Version, entity.StatusProcessing, "")
 	assert.NoError(suite.T(), err)
 
 	// Verificar se o status foi atualizado
@@ -142,16 +377,86 @@ func (suite *VideoRepositoryTestSuite) TestUpdateStatus() {
 	assert.Equal(suite.T(), entity.StatusProcessing, foundVideo.Status)
 }
 
+func (suite *VideoRepositoryTestSuite) TestUpdateProgress() {
+	// Criar um vídeo para o teste
+	video := entity.NewVideo(testOwnerID, "Teste de Progresso", "", "/path/to/progress.mp4")
+	err := suite.repository.Create(suite.ctx, video)
+	assert.NoError(suite.T(), err)
+
+	// Iniciar o processamento zera o progresso
+	err = suite.repository.UpdateStatus(suite.ctx, video.ID, video.Version, entity.StatusProcessing, "")
+	assert.NoError(suite.T(), err)
+
+	// Atualizar o progresso
+	eta := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+	err = suite.repository.UpdateProgress(suite.ctx, video.ID, 42, entity.ProcessingStageTranscoding, &eta)
+	assert.NoError(suite.T(), err)
+
+	// Verificar se o progresso foi atualizado
+	foundVideo, err := suite.repository.FindByID(suite.ctx, video.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 42, foundVideo.Progress)
+	assert.Equal(suite.T(), entity.ProcessingStageTranscoding, foundVideo.ProcessingStage)
+	assert.NotNil(suite.T(), foundVideo.EstimatedCompletionAt)
+
+	// Concluir o vídeo completa o progresso e limpa a estimativa
+	err = suite.repository.UpdateStatus(suite.ctx, video.ID, video.Version+1, entity.StatusCompleted, "")
+	assert.NoError(suite.T(), err)
+
+	foundVideo, err = suite.repository.FindByID(suite.ctx, video.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 100, foundVideo.Progress)
+	assert.Nil(suite.T(), foundVideo.EstimatedCompletionAt)
+}
+
+func (suite *VideoRepositoryTestSuite) TestFindCompletedByContentHash() {
+	contentHash := strings.Repeat("ab", 32)
+
+	// Um vídeo pendente com o mesmo conteúdo ainda não pode ser reaproveitado
+	original := entity.NewVideo(testOwnerID, "Original", "", "/path/to/original.mp4")
+	original.SetContentHash(contentHash)
+	err := suite.repository.Create(suite.ctx, original)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.repository.FindCompletedByContentHash(suite.ctx, testOwnerID, contentHash)
+	assert.ErrorIs(suite.T(), err, domainRepository.ErrVideoNotFound)
+
+	err = suite.repository.UpdateStatus(suite.ctx, original.ID, original.Version, entity.StatusCompleted, "")
+	assert.NoError(suite.T(), err)
+
+	// A duplicata vinculada persiste a referência ao original
+	duplicate := entity.NewVideo(testOwnerID, "Duplicata", "", "/path/to/duplicate.mp4")
+	duplicate.SetContentHash(contentHash)
+	original.Status = entity.StatusCompleted
+	err = duplicate.LinkToOriginal(original)
+	assert.NoError(suite.T(), err)
+	err = suite.repository.Create(suite.ctx, duplicate)
+	assert.NoError(suite.T(), err)
+
+	found, err := suite.repository.FindCompletedByContentHash(suite.ctx, testOwnerID, contentHash)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), original.ID, found.ID)
+	assert.Equal(suite.T(), contentHash, found.ContentHash)
+
+	foundDuplicate, err := suite.repository.FindByID(suite.ctx, duplicate.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), original.ID, foundDuplicate.DuplicateOfID)
+
+	// A busca é restrita à conta de cliente
+	_, err = suite.repository.FindCompletedByContentHash(suite.ctx, "outro-owner", contentHash)
+	assert.ErrorIs(suite.T(), err, domainRepository.ErrVideoNotFound)
+}
+
 func (suite *VideoRepositoryTestSuite) TestUpdateHLSPath() {
 	// Criar um vídeo para o teste
-	video := entity.NewVideo("Teste de Atualização de HLS", "/path/to/hls.mp4")
+	video := entity.NewVideo(testOwnerID, "Teste de Atualização de HLS", "", "/path/to/hls.mp4")
 	err := suite.repository.Create(suite.ctx, video)
 	assert.NoError(suite.T(), err)
 
 	// Atualizar os caminhos HLS
 	hlsPath := "/path/to/hls"
 	manifestPath := "/path/to/manifest.m3u8"
-	err = suite.repository.UpdateHLSPath(suite.ctx, video.ID, hlsPath, manifestPath)
+	err = suite.repository.UpdateHLSPath(suite.ctx, video.ID, video.Version, hlsPath, manifestPath)
 	assert.NoError(suite.T(), err)
 
 	// Verificar se os caminhos foram atualizados
@@ -163,12 +468,12 @@ func (suite *VideoRepositoryTestSuite) TestUpdateHLSPath() {
 
 func (suite *VideoRepositoryTestSuite) TestUpdateS3Status() {
 	// Criar um vídeo para o teste
-	video := entity.NewVideo("Teste de Atualização de S3 Status", "/path/to/s3status.mp4")
+	video := entity.NewVideo(testOwnerID, "Teste de Atualização de S3 Status", "", "/path/to/s3status.mp4")
 	err := suite.repository.Create(suite.ctx, video)
 	assert.NoError(suite.T(), err)
 
 	// Atualizar o status de upload
-	err = suite.repository.UpdateS3Status(suite.ctx, video.ID, entity.UploadStatusPendingS3)
+	err = suite.repository.UpdateS3Status(suite.ctx, video.ID, video.Version, entity.UploadStatusPendingS3)
 	assert.NoError(suite.T(), err)
 
 	// Verificar se o status foi atualizado
@@ -179,14 +484,14 @@ func (suite *VideoRepositoryTestSuite) TestUpdateS3Status() {
 
 func (suite *VideoRepositoryTestSuite) TestUpdateS3URLs() {
 	// Criar um vídeo para o teste
-	video := entity.NewVideo("Teste de Atualização de S3 URLs", "/path/to/s3urls.mp4")
+	video := entity.NewVideo(testOwnerID, "Teste de Atualização de S3 URLs", "", "/path/to/s3urls.mp4")
 	err := suite.repository.Create(suite.ctx, video)
 	assert.NoError(suite.T(), err)
 
 	// Atualizar as URLs do S3
 	s3URL := "https://bucket.s3.amazonaws.com/videos/123"
 	s3ManifestURL := "https://bucket.s3.amazonaws.com/manifests/123.m3u8"
-	err = suite.repository.UpdateS3URLs(suite.ctx, video.ID, s3URL, s3ManifestURL)
+	err = suite.repository.UpdateS3URLs(suite.ctx, video.ID, video.Version, s3URL, s3ManifestURL)
 	assert.NoError(suite.T(), err)
 
 	// Verificar se as URLs foram atualizadas
@@ -198,14 +503,14 @@ func (suite *VideoRepositoryTestSuite) TestUpdateS3URLs() {
 
 func (suite *VideoRepositoryTestSuite) TestUpdateS3Keys() {
 	// Criar um vídeo para o teste
-	video := entity.NewVideo("Teste de Atualização de S3 Keys", "/path/to/s3keys.mp4")
+	video := entity.NewVideo(testOwnerID, "Teste de Atualização de S3 Keys", "", "/path/to/s3keys.mp4")
 	err := suite.repository.Create(suite.ctx, video)
 	assert.NoError(suite.T(), err)
 
 	// Atualizar as chaves do S3
 	segmentKey := "videos/123"
 	manifestKey := "manifests/123.m3u8"
-	err = suite.repository.UpdateS3Keys(suite.ctx, video.ID, segmentKey, manifestKey)
+	err = suite.repository.UpdateS3Keys(suite.ctx, video.ID, video.Version, segmentKey, manifestKey)
 	assert.NoError(suite.T(), err)
 
 	// Verificar se as chaves foram atualizadas
@@ -218,12 +523,12 @@ func (suite *VideoRepositoryTestSuite) TestUpdateS3Keys() {
 
 func (suite *VideoRepositoryTestSuite) TestDelete() {
 	// Criar um vídeo para o teste
-	video := entity.NewVideo("Teste de Exclusão", "/path/to/delete.mp4")
+	video := entity.NewVideo(testOwnerID, "Teste de Exclusão", "", "/path/to/delete.mp4")
 	err := suite.repository.Create(suite.ctx, video)
 	assert.NoError(suite.T(), err)
 
 	// Excluir o vídeo
-	err = suite.repository.Delete(suite.ctx, video.ID)
+	err = suite.repository.Delete(suite.ctx, video.ID, video.Version)
 	assert.NoError(suite.T(), err)
 
 	// Verificar se o vídeo foi marcado como excluído
@@ -242,6 +547,18 @@ func TestVideoRepositoryTestSuite(t *testing.T) {
 	suite.Run(t, new(VideoRepositoryTestSuite))
 }
 
+// testDBConfig retorna a configuração do banco de dados de teste
+func testDBConfig() database.Config {
+	return database.Config{
+		Host:     getEnv("DB_HOST", "postgres"),
+		Port:     5432,
+		User:     getEnv("DB_USER", "postgres"),
+		Password: getEnv("DB_PASSWORD", "postgres"),
+		DBName:   getEnv("DB_NAME", "conversorgo"),
+		SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+	}
+}
+
 // getEnv retorna o valor da variável de ambiente ou o valor padrão se não estiver definida
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
@@ -250,3 +567,20 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+func TestVideoRepositoryPostgresConformance(t *testing.T) {
+	db, err := database.NewConnection(testDBConfig())
+	if err != nil {
+		t.Fatalf("Erro ao conectar ao banco de dados: %v", err)
+	}
+	defer db.Close()
+
+	suite.Run(t, &VideoRepositoryConformanceSuite{
+		newRepository: func(t *testing.T) domainRepository.VideoRepository {
+			if _, err := db.Exec("DELETE FROM videos"); err != nil {
+				t.Fatalf("Erro ao limpar a tabela de vídeos: %v", err)
+			}
+			return NewVideoRepositoryPostgres(db)
+		},
+	})
+}
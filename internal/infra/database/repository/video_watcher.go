@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+	"github.com/lib/pq"
+)
+
+// videoChangesChannel é o canal do NOTIFY publicado pelo gatilho notify_video_change
+const videoChangesChannel = "video_changes"
+
+const (
+	// watchResyncMargin recua o início da ressincronização para cobrir a diferença entre os relógios
+	// de quem grava updated_at e de quem observa
+	watchResyncMargin = 5 * time.Second
+
+	// watchPingInterval é o intervalo das verificações da conexão do LISTEN, que detectam conexões mortas
+	watchPingInterval = 30 * time.Second
+
+	watchMinReconnect = time.Second
+	watchMaxReconnect = time.Minute
+	watchBufferSize   = 64
+)
+
+// videoChangePayload é o JSON publicado pelo gatilho notify_video_change
+type videoChangePayload struct {
+	ID                   string `json:"id"`
+	OwnerID              string `json:"owner_id"`
+	Status               string `json:"status"`
+	UploadStatus         string `json:"upload_status"`
+	PreviousStatus       string `json:"previous_status"`
+	PreviousUploadStatus string `json:"previous_upload_status"`
+	Version              int64  `json:"version"`
+	UpdatedAt            int64  `json:"updated_at"` // Microssegundos desde a época Unix
+}
+
+// decodeVideoChange converte o payload do NOTIFY em VideoChange
+func decodeVideoChange(payload string) (domainRepository.VideoChange, error) {
+	var p videoChangePayload
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		return domainRepository.VideoChange{}, fmt.Errorf("erro ao decodificar mudança do vídeo: %w", err)
+	}
+
+	return domainRepository.VideoChange{
+		VideoID:              p.ID,
+		OwnerID:              p.OwnerID,
+		Status:               p.Status,
+		UploadStatus:         p.UploadStatus,
+		PreviousStatus:       p.PreviousStatus,
+		PreviousUploadStatus: p.PreviousUploadStatus,
+		Version:              p.Version,
+		UpdatedAt:            time.UnixMicro(p.UpdatedAt).UTC(),
+	}, nil
+}
+
+// VideoWatcherPostgres implementa a interface VideoWatcher com LISTEN/NOTIFY do PostgreSQL
+// Cada chamada a Watch mantém uma conexão própria para o LISTEN, fora do pool de db;
+// db é usado apenas para reler os vídeos alterados enquanto essa conexão esteve fora
+type VideoWatcherPostgres struct {
+	db  *sql.DB
+	dsn string
+}
+
+// NewVideoWatcherPostgres cria uma nova instância de VideoWatcherPostgres
+// dsn é a string de conexão usada pelas conexões do LISTEN (veja database.Config.DSN)
+func NewVideoWatcherPostgres(db *sql.DB, dsn string) *VideoWatcherPostgres {
+	return &VideoWatcherPostgres{
+		db:  db,
+		dsn: dsn,
+	}
+}
+
+// Watch entrega as mudanças dos vídeos que atendem ao filtro até o contexto ser cancelado
+// O canal também é fechado se o PostgreSQL recusar o LISTEN
+func (w *VideoWatcherPostgres) Watch(ctx context.Context, filter domainRepository.VideoWatchFilter) <-chan domainRepository.VideoChange {
+	changes := make(chan domainRepository.VideoChange, watchBufferSize)
+	go w.watch(ctx, filter, changes)
+	return changes
+}
+
+// watch mantém o LISTEN e repassa as notificações até o contexto ser cancelado
+// O pq.Listener refaz a conexão sozinho e envia uma notificação nil após cada reconexão, pois as
+// notificações do período desconectado se perderam; nesse caso os vídeos alterados desde a última vez
+// em que a conexão estava comprovadamente ativa são relidos
+func (w *VideoWatcherPostgres) watch(ctx context.Context, filter domainRepository.VideoWatchFilter, changes chan<- domainRepository.VideoChange) {
+	defer close(changes)
+
+	// As mudanças anteriores ao LISTEN também são relidas, para não perder as que ocorrerem até ele ser aceito
+	resyncFrom := time.Now()
+	needsResync := true
+	lastAlive := resyncFrom
+
+	listener := pq.NewListener(w.dsn, watchMinReconnect, watchMaxReconnect, nil)
+	defer listener.Close()
+
+	// Listen espera a primeira conexão; fechar o listener o libera se o contexto for cancelado antes
+	stop := context.AfterFunc(ctx, func() { listener.Close() })
+	defer stop()
+
+	if err := listener.Listen(videoChangesChannel); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(watchPingInterval)
+	defer ticker.Stop()
+
+	for {
+		// Uma ressincronização que falhou é tentada de novo na próxima volta do laço
+		if needsResync && w.resync(ctx, filter, resyncFrom.Add(-watchResyncMargin), changes) == nil {
+			needsResync = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case notification, ok := <-listener.Notify:
+			if !ok {
+				return
+			}
+
+			if notification == nil {
+				if !needsResync {
+					needsResync = true
+					resyncFrom = lastAlive
+				}
+				continue
+			}
+
+			if !needsResync {
+				lastAlive = time.Now()
+			}
+
+			change, err := decodeVideoChange(notification.Extra)
+			if err != nil || !filter.Matches(change) {
+				continue
+			}
+
+			if !sendVideoChange(ctx, changes, change) {
+				return
+			}
+		case <-ticker.C:
+			if listener.Ping() == nil && !needsResync {
+				lastAlive = time.Now()
+			}
+		}
+	}
+}
+
+// resync relê os vídeos alterados desde since e os entrega como mudanças ressincronizadas
+func (w *VideoWatcherPostgres) resync(ctx context.Context, filter domainRepository.VideoWatchFilter, since time.Time, changes chan<- domainRepository.VideoChange) error {
+	query := `
+		SELECT id, owner_id, status, upload_status, version, updated_at
+		FROM videos
+		WHERE deleted_at IS NULL
+			AND updated_at >= $1
+			AND ($2 = '' OR owner_id = $2)
+			AND (cardinality($3::text[]) = 0 OR id::text = ANY($3::text[]))
+		ORDER BY updated_at ASC, id ASC
+	`
+
+	rows, err := w.db.QueryContext(ctx, query, since, filter.OwnerID, pq.Array(filter.VideoIDs))
+	if err != nil {
+		return fmt.Errorf("erro ao ressincronizar mudanças dos vídeos: %w", err)
+	}
+	defer rows.Close()
+
+	// Lê tudo antes de entregar, para não prender a conexão enquanto o consumidor estiver lento
+	var resynced []domainRepository.VideoChange
+
+	for rows.Next() {
+		change := domainRepository.VideoChange{Resync: true}
+		if err := rows.Scan(&change.VideoID, &change.OwnerID, &change.Status, &change.UploadStatus, &change.Version, &change.UpdatedAt); err != nil {
+			return fmt.Errorf("erro ao escanear mudança do vídeo: %w", err)
+		}
+		resynced = append(resynced, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao iterar sobre os resultados: %w", err)
+	}
+	rows.Close()
+
+	for _, change := range resynced {
+		if !sendVideoChange(ctx, changes, change) {
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// sendVideoChange entrega a mudança, retornando false se o contexto for cancelado antes
+func sendVideoChange(ctx context.Context, changes chan<- domainRepository.VideoChange, change domainRepository.VideoChange) bool {
+	select {
+	case changes <- change:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Ensure VideoWatcherPostgres implements VideoWatcher
+var _ domainRepository.VideoWatcher = (*VideoWatcherPostgres)(nil)
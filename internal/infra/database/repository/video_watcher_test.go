@@ -0,0 +1,131 @@
+//go:build integration
+// +build integration
+
+// Este arquivo contém testes de integração que acessam o banco de dados real.
+// Para executar estes testes, use o comando:
+// go test -tags=integration ./internal/infra/database/repository
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/devfullcycle/golangtechweek/internal/domain/entity"
+	domainRepository "github.com/devfullcycle/golangtechweek/internal/domain/repository"
+	"github.com/devfullcycle/golangtechweek/internal/infra/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type VideoWatcherTestSuite struct {
+	suite.Suite
+	db        *sql.DB
+	watcher   *VideoWatcherPostgres
+	videoRepo *VideoRepositoryPostgres
+}
+
+func (suite *VideoWatcherTestSuite) SetupSuite() {
+	var err error
+	suite.db, err = database.NewConnection(testDBConfig())
+	if err != nil {
+		suite.T().Fatalf("Erro ao conectar ao banco de dados: %v", err)
+	}
+
+	suite.watcher = NewVideoWatcherPostgres(suite.db, testDBConfig().DSN())
+	suite.videoRepo = NewVideoRepositoryPostgres(suite.db)
+}
+
+func (suite *VideoWatcherTestSuite) TearDownSuite() {
+	_, err := suite.db.Exec("DELETE FROM videos")
+	if err != nil {
+		suite.T().Fatalf("Erro ao limpar a tabela de vídeos: %v", err)
+	}
+
+	if suite.db != nil {
+		suite.db.Close()
+	}
+}
+
+// waitForStatus lê o canal até receber a mudança do vídeo para o status informado
+func (suite *VideoWatcherTestSuite) waitForStatus(changes <-chan domainRepository.VideoChange, status string) domainRepository.VideoChange {
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case change, ok := <-changes:
+			require.True(suite.T(), ok, "canal fechado antes da mudança esperada")
+			if change.Status == status {
+				return change
+			}
+		case <-timeout:
+			suite.T().Fatalf("Mudança para %s não recebida", status)
+		}
+	}
+}
+
+func (suite *VideoWatcherTestSuite) TestWatchDeliversStatusChanges() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	video := entity.NewVideo(testOwnerID, "Teste de Watch", "", "/path/to/watch.mp4")
+	require.NoError(suite.T(), suite.videoRepo.Create(ctx, video))
+
+	changes := suite.watcher.Watch(ctx, domainRepository.VideoWatchFilter{VideoIDs: []string{video.ID}})
+
+	// A criação, anterior ao Watch, chega pela ressincronização inicial
+	created := suite.waitForStatus(changes, entity.StatusPending)
+	assert.True(suite.T(), created.Resync)
+
+	require.NoError(suite.T(), suite.videoRepo.UpdateStatus(ctx, video.ID, video.Version, entity.StatusProcessing, ""))
+
+	change := suite.waitForStatus(changes, entity.StatusProcessing)
+	assert.False(suite.T(), change.Resync)
+	assert.Equal(suite.T(), video.ID, change.VideoID)
+	assert.Equal(suite.T(), testOwnerID, change.OwnerID)
+	assert.Equal(suite.T(), entity.StatusPending, change.PreviousStatus)
+	assert.Equal(suite.T(), int64(2), change.Version)
+
+	cancel()
+	for range changes {
+	}
+}
+
+func (suite *VideoWatcherTestSuite) TestWatchResyncsAfterReconnect() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	video := entity.NewVideo(testOwnerID, "Teste de Reconexão", "", "/path/to/reconnect.mp4")
+	require.NoError(suite.T(), suite.videoRepo.Create(ctx, video))
+
+	changes := suite.watcher.Watch(ctx, domainRepository.VideoWatchFilter{VideoIDs: []string{video.ID}})
+	suite.waitForStatus(changes, entity.StatusPending)
+
+	// Derruba a conexão do LISTEN e altera o vídeo enquanto ela está fora
+	_, err := suite.db.Exec(`SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE query LIKE 'LISTEN %'`)
+	require.NoError(suite.T(), err)
+	require.NoError(suite.T(), suite.videoRepo.UpdateStatus(ctx, video.ID, video.Version, entity.StatusCompleted, ""))
+
+	change := suite.waitForStatus(changes, entity.StatusCompleted)
+	assert.Equal(suite.T(), video.ID, change.VideoID)
+}
+
+func (suite *VideoWatcherTestSuite) TestDecodeVideoChange() {
+	change, err := decodeVideoChange(`{"id":"v1","owner_id":"o1","status":"processing","upload_status":"none",` +
+		`"previous_status":"pending","previous_upload_status":null,"version":3,"updated_at":1700000000000000}`)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "v1", change.VideoID)
+	assert.Equal(suite.T(), "pending", change.PreviousStatus)
+	assert.Empty(suite.T(), change.PreviousUploadStatus)
+	assert.Equal(suite.T(), int64(3), change.Version)
+	assert.Equal(suite.T(), time.Unix(1700000000, 0).UTC(), change.UpdatedAt)
+
+	_, err = decodeVideoChange(`não é json`)
+	assert.Error(suite.T(), err)
+}
+
+func TestVideoWatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(VideoWatcherTestSuite))
+}
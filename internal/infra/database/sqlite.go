@@ -0,0 +1,29 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// NewSQLiteConnection abre (ou cria) o banco SQLite no caminho informado
+// Indicado para instalações de um único nó, em que manter um PostgreSQL não se justifica
+func NewSQLiteConnection(filePath string) (*sql.DB, error) {
+	// WAL permite leituras durante a escrita; busy_timeout espera o lock em vez de falhar imediatamente
+	// _txlock=immediate reserva a escrita no início da transação, evitando que uma transação que leu antes
+	// de gravar (como as do AuditedVideoRepository) falhe com SQLITE_BUSY sem esperar o busy_timeout
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)&_txlock=immediate", filePath)
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir banco de dados SQLite: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("erro ao verificar conexão com o banco de dados SQLite: %w", err)
+	}
+
+	return db, nil
+}